@@ -0,0 +1,40 @@
+package app
+
+import (
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type checkField struct {
+	value    widget.Bool
+	name     string
+	setValue func(value bool)
+	getValue func() bool
+}
+
+// Creates a new check box widget with the specified name.
+// The state is read by getValue and every change is passed to setValue.
+func NewCheckField(name string, setValue func(value bool), getValue func() bool) checkField {
+	ret := checkField{
+		name:     name,
+		setValue: setValue,
+		getValue: getValue,
+	}
+	ret.Update()
+	return ret
+}
+
+func (c *checkField) Update() {
+	c.value.Value = c.getValue()
+}
+
+// Returns a layout widget for the check box.
+func (c *checkField) GetWidget(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		if c.value.Update(gtx) {
+			c.setValue(c.value.Value)
+		}
+		return material.CheckBox(th, &c.value, c.name).Layout(gtx)
+	}
+}
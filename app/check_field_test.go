@@ -0,0 +1,21 @@
+package app
+
+import (
+	"testing"
+)
+
+func TestNewCheckField(t *testing.T) {
+	value := true
+	field := NewCheckField("Test", func(v bool) { value = v }, func() bool { return value })
+	if field.name != "Test" {
+		t.Errorf("name = %v, want %v", field.name, "Test")
+	}
+	if !field.value.Value {
+		t.Error("value.Value = false, want true")
+	}
+	value = false
+	field.Update()
+	if field.value.Value {
+		t.Error("value.Value = true after Update, want false")
+	}
+}
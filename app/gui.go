@@ -23,6 +23,7 @@ type MessageType int
 const (
 	Info MessageType = iota
 	Error
+	Warning
 )
 
 const (
@@ -49,6 +50,12 @@ func (m *Message) setInfo(s string) {
 	m.messageType = Info
 }
 
+// Sets the message to a warning shown until it is closed.
+func (m *Message) setWarning(s string) {
+	m.message = s
+	m.messageType = Warning
+}
+
 const NAME string = "EANBaker"
 
 // Starts the GUI application in a separate goroutine.
@@ -56,9 +63,17 @@ const NAME string = "EANBaker"
 // Exits the program when the window is closed.
 // Returns an error if the GUI fails to start.
 func RunGui(logger *core.MultiLogger) error {
+	return RunGuiWithConfig(logger, nil, "", nil)
+}
+
+// Starts the GUI application as RunGui does with the generator values and
+// the profile active. Configuration of the profile is loaded if the generator
+// is nil, the local configuration file is used if the profile is empty.
+// Warning is shown in the banner, e.g. why the configuration was not loaded.
+func RunGuiWithConfig(logger *core.MultiLogger, generator *core.Generator, profile string, warning error) error {
 	go func() {
 		window := new(app.Window)
-		err := runUI(window, logger)
+		err := runUI(window, logger, generator, profile, warning)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -86,10 +101,16 @@ func inset(inset layout.Inset, widget layout.Widget) layout.Widget {
 // Manages page switching between main and options pages,
 // handles button clicks, and renders the UI based on current state.
 // Processes window events until destruction.
-func runUI(w *app.Window, log *core.MultiLogger) error {
+func runUI(w *app.Window, log *core.MultiLogger, configured *core.Generator, profile string, warning error) error {
 	var message Message
+	// Configuration that cannot be loaded is reported until the banner is closed.
+	var banner Message
+	if warning != nil {
+		banner.setWarning(fmt.Sprintf("Configuration could not be loaded, default values are used: %v", warning))
+	}
 
 	messageBtn := widget.Clickable{}
+	bannerBtn := widget.Clickable{}
 	optionsBtn := widget.Clickable{}
 	infoBtn := widget.Clickable{}
 	actPage := PageMain
@@ -97,17 +118,28 @@ func runUI(w *app.Window, log *core.MultiLogger) error {
 	var ops op.Ops
 	th := material.NewTheme()
 
-	generator, err := core.LoadGenerator("./."+NAME+".json", log.Logger)
-	if err != nil {
-		generator = &core.Generator{TimesEachEAN: 1}
+	defaults := core.DefaultGenerator()
+	generator := &defaults
+	if configured != nil {
+		generator = configured
 	}
 
-	textHeader := NewInputField("Text", "Text column header", &message, func(v string) error {
+	var mainPage MainPage
+	var optsPage OptsPage
+	store, err := core.UserProfileStore(NAME)
+	message.setError(err)
+	profiles := NewProfileSwitcher(store, generator, &message, log.Logger, func() {
+		banner.message = ""
+		optsPage.update()
+		mainPage.update()
+	})
+
+	textHeader := NewInputField("Text", "Text column header, letter or #position", &message, func(v string) error {
 		generator.TextHeader = v
 		return nil
 	}, func() string { return generator.TextHeader })
 
-	eanHeader := NewInputField("EAN", "EAN column header", &message, func(v string) error {
+	eanHeader := NewInputField("EAN", "EAN column header, letter or #position", &message, func(v string) error {
 		generator.EanHeader = v
 		return nil
 	}, func() string { return generator.EanHeader })
@@ -117,9 +149,9 @@ func runUI(w *app.Window, log *core.MultiLogger) error {
 		return nil
 	}, func() string { return generator.TimesHeader })
 
-	csvComma := NewInputField("Csv sep", "Csv column separator", &message, func(v string) error {
+	csvComma := NewInputField("Csv sep", "Csv column separator (detected if empty)", &message, func(v string) error {
 		if v == "" {
-			generator.CsvComma = ','
+			generator.CsvComma = 0
 			return nil
 		}
 		comma, err := core.CommaFromString(strings.TrimSpace(v))
@@ -128,13 +160,359 @@ func runUI(w *app.Window, log *core.MultiLogger) error {
 		}
 		generator.CsvComma = comma
 		return nil
-	}, func() string { return string(generator.CsvComma) })
+	}, func() string {
+		if generator.CsvComma == 0 {
+			return ""
+		}
+		return string(generator.CsvComma)
+	})
+
+	const autoEncoding = "auto"
+	encodings := []string{autoEncoding}
+	for _, e := range core.TextEncodings {
+		encodings = append(encodings, string(e))
+	}
+	csvEncoding := NewSelectField("Csv encoding", encodings, &message, func(v string) error {
+		value, err := core.TextEncodingFromString(v)
+		if err != nil {
+			return err
+		}
+		generator.CsvEncoding = value
+		return nil
+	}, func() string {
+		if generator.CsvEncoding == core.EncodingAuto {
+			return autoEncoding
+		}
+		return string(generator.CsvEncoding)
+	})
+
+	symbologies := []string{}
+	for _, s := range core.Symbologies {
+		symbologies = append(symbologies, string(s))
+	}
+	symbology := NewSelectField("Symbology", symbologies, &message, func(v string) error {
+		value, err := core.SymbologyFromString(v)
+		if err != nil {
+			return err
+		}
+		generator.Symbology = value
+		return nil
+	}, func() string {
+		value, _ := core.SymbologyFromString(string(generator.Symbology))
+		return string(value)
+	})
+
+	symbologyHeader := NewInputField("Symbology header", "Per-row symbology column header (optional)", &message, func(v string) error {
+		generator.SymbologyHeader = v
+		return nil
+	}, func() string { return generator.SymbologyHeader })
+
+	const noLabelSheet = "none"
+	labelSheet := NewSelectField("Label sheet", append([]string{noLabelSheet}, core.LabelSheetPresetNames()...), &message, func(v string) error {
+		if v == noLabelSheet {
+			generator.LabelSheet = nil
+			return nil
+		}
+		sheet, err := core.LabelSheetFromString(v)
+		if err != nil {
+			return err
+		}
+		generator.LabelSheet = &sheet
+		return nil
+	}, func() string {
+		if generator.LabelSheet == nil {
+			return noLabelSheet
+		}
+		return generator.LabelSheet.Name
+	})
+
+	renderers := []string{}
+	for _, r := range core.BarcodeRenderers {
+		renderers = append(renderers, string(r))
+	}
+	barcodeRenderer := NewSelectField("Barcode renderer", renderers, &message, func(v string) error {
+		value, err := core.BarcodeRendererFromString(v)
+		if err != nil {
+			return err
+		}
+		generator.BarcodeRenderer = value
+		return nil
+	}, func() string {
+		value, _ := core.BarcodeRendererFromString(string(generator.BarcodeRenderer))
+		return string(value)
+	})
+
+	moduleWidth := NewInputField("Module width", "Width of the narrowest bar in mm, e.g. 0.33 (fits the box if empty)", &message, func(v string) error {
+		if v == "" {
+			generator.ModuleWidth = 0
+			return nil
+		}
+		width, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || width < 0 {
+			return fmt.Errorf("Module width must be positive number not '%s'.", v)
+		}
+		generator.ModuleWidth = width
+		return nil
+	}, func() string {
+		if generator.ModuleWidth == 0 {
+			return ""
+		}
+		return fmt.Sprint(generator.ModuleWidth)
+	})
+
+	addOnHeader := NewInputField("Add-on header", "EAN-2/EAN-5 add-on column header (optional)", &message, func(v string) error {
+		generator.AddOnHeader = v
+		return nil
+	}, func() string { return generator.AddOnHeader })
+
+	convertISBN10 := NewCheckField("Convert ISBN-10 to ISBN-13", func(v bool) {
+		generator.ConvertISBN10 = v
+	}, func() bool { return generator.ConvertISBN10 })
+
+	// Columns of the most used Application Identifiers, others are set in the config file.
+	gs1Columns := []*inputField{}
+	for _, ai := range []struct{ ai, name string }{
+		{"01", "GTIN"}, {"10", "batch"}, {"17", "expiry date"}, {"21", "serial number"},
+	} {
+		field := NewInputField(fmt.Sprintf("GS1 (%s) header", ai.ai), fmt.Sprintf("Column with %s of GS1 symbologies (optional)", ai.name), &message, func(v string) error {
+			if strings.TrimSpace(v) == "" {
+				delete(generator.GS1Columns, ai.ai)
+				return nil
+			}
+			if generator.GS1Columns == nil {
+				generator.GS1Columns = map[string]string{}
+			}
+			generator.GS1Columns[ai.ai] = v
+			return nil
+		}, func() string { return generator.GS1Columns[ai.ai] })
+		gs1Columns = append(gs1Columns, &field)
+	}
+
+	gs1Rendering := NewCheckField("GS1 EAN rendering", func(v bool) {
+		generator.GS1Rendering = v
+	}, func() bool { return generator.GS1Rendering })
+
+	magnification := NewInputField("Magnification", "Magnification of GS1 symbols from 0.8 to 2.0 (module width if empty)", &message, func(v string) error {
+		if v == "" {
+			generator.Magnification = 0
+			return nil
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || value < 0 {
+			return fmt.Errorf("Magnification must be positive number not '%s'.", v)
+		}
+		// Range is checked when generating, "1" is a prefix of "1.5".
+		generator.Magnification = value
+		return nil
+	}, func() string {
+		if generator.Magnification == 0 {
+			return ""
+		}
+		return fmt.Sprint(generator.Magnification)
+	})
+
+	startPosition := NewInputField("Start position", "Position of the first label on the first sheet.", &message, func(v string) error {
+		if v == "" {
+			generator.StartPosition = 1
+			return nil
+		}
+		position, err := strconv.ParseUint(strings.TrimSpace(v), 10, 0)
+		if err != nil || position == 0 {
+			return fmt.Errorf("Start position must be positive integer not '%s'.", v)
+		}
+		generator.StartPosition = int(position)
+		return nil
+	}, func() string { return fmt.Sprint(max(1, generator.StartPosition)) })
+
+	completeCheckDigits := NewCheckField("Complete missing check digits", func(v bool) {
+		generator.CompleteCheckDigits = v
+	}, func() bool { return generator.CompleteCheckDigits })
+
+	errorPolicies := []string{}
+	for _, p := range core.ErrorPolicies {
+		errorPolicies = append(errorPolicies, string(p))
+	}
+	errorPolicy := NewSelectField("Invalid rows", errorPolicies, &message, func(v string) error {
+		value, err := core.ErrorPolicyFromString(v)
+		if err != nil {
+			return err
+		}
+		generator.ErrorPolicy = value
+		return nil
+	}, func() string {
+		value, _ := core.ErrorPolicyFromString(string(generator.ErrorPolicy))
+		return string(value)
+	})
+
+	skippedReport := NewInputField("Skipped report", "Path to CSV report of invalid rows (optional)", &message, func(v string) error {
+		generator.SkippedReportPath = v
+		return nil
+	}, func() string { return generator.SkippedReportPath })
 
-	pdfFile := NewInputField("Pdf path", "Static path to generated pdf.", &message, func(v string) error {
+	report := NewInputField("Report", "Path to CSV or XLSX report of all labels (optional)", &message, func(v string) error {
+		generator.ReportPath = v
+		return nil
+	}, func() string { return generator.ReportPath })
+
+	// Templates are validated when generating, partially written template is not valid.
+	templateTop := NewInputField("Top template", "Template of top text, e.g. {{.Name}} / {{.Size}}", &message, func(v string) error {
+		generator.Templates.Top = v
+		return nil
+	}, func() string { return generator.Templates.Top })
+
+	templateBottom := NewInputField("Bottom template", "Template of bottom text (barcode content if empty)", &message, func(v string) error {
+		generator.Templates.Bottom = v
+		return nil
+	}, func() string { return generator.Templates.Bottom })
+
+	templateSide := NewInputField("Side template", "Template of side text (layout must have side box)", &message, func(v string) error {
+		generator.Templates.Side = v
+		return nil
+	}, func() string { return generator.Templates.Side })
+
+	templateBarcode := NewInputField("Barcode template", "Template of barcode content, e.g. {{.Ean}}-{{.Serial}}", &message, func(v string) error {
+		generator.Templates.Barcode = v
+		return nil
+	}, func() string { return generator.Templates.Barcode })
+
+	serialEnabled := NewCheckField("Serial numbers", func(v bool) {
+		generator.Serial.Enabled = v
+	}, func() bool { return generator.Serial.Enabled })
+
+	serialStart := NewInputField("Serial start", "Serial number of the first label, the last issued is continued", &message, func(v string) error {
+		if v == "" {
+			generator.Serial.Start = 0
+			return nil
+		}
+		start, err := strconv.ParseUint(strings.TrimSpace(v), 10, 63)
+		if err != nil {
+			return fmt.Errorf("Serial start must be non negative integer not '%s'.", v)
+		}
+		generator.Serial.Start = int64(start)
+		return nil
+	}, func() string { return fmt.Sprint(generator.Serial.Start) })
+
+	serialStep := NewInputField("Serial step", "Difference between following serial numbers", &message, func(v string) error {
+		if v == "" {
+			generator.Serial.Step = 0
+			return nil
+		}
+		step, err := strconv.ParseUint(strings.TrimSpace(v), 10, 63)
+		if err != nil || step == 0 {
+			return fmt.Errorf("Serial step must be positive integer not '%s'.", v)
+		}
+		generator.Serial.Step = int64(step)
+		return nil
+	}, func() string { return fmt.Sprint(max(1, generator.Serial.Step)) })
+
+	serialPadding := NewInputField("Serial padding", "Minimal number of digits, padded by zeros", &message, func(v string) error {
+		if v == "" {
+			generator.Serial.Padding = 0
+			return nil
+		}
+		padding, err := strconv.ParseUint(strings.TrimSpace(v), 10, 0)
+		if err != nil || padding > core.MaxSerialPadding {
+			return fmt.Errorf("Serial padding must be integer from 0 to %d not '%s'.", core.MaxSerialPadding, v)
+		}
+		generator.Serial.Padding = int(padding)
+		return nil
+	}, func() string { return fmt.Sprint(generator.Serial.Padding) })
+
+	serialPrefix := NewInputField("Serial prefix", "Text before serial numbers (optional)", &message, func(v string) error {
+		generator.Serial.Prefix = v
+		return nil
+	}, func() string { return generator.Serial.Prefix })
+
+	serialSuffix := NewInputField("Serial suffix", "Text after serial numbers (optional)", &message, func(v string) error {
+		generator.Serial.Suffix = v
+		return nil
+	}, func() string { return generator.Serial.Suffix })
+
+	priceHeader := NewInputField("Price header", "Price column header (optional)", &message, func(v string) error {
+		generator.PriceHeader = v
+		return nil
+	}, func() string { return generator.PriceHeader })
+
+	quantityHeader := NewInputField("Quantity header", "Quantity column header for unit prices (optional)", &message, func(v string) error {
+		generator.QuantityHeader = v
+		return nil
+	}, func() string { return generator.QuantityHeader })
+
+	priceLocale := NewSelectField("Price locale", core.PriceLocaleNames(), &message, func(v string) error {
+		generator.PriceFormat.Locale = v
+		return nil
+	}, func() string {
+		if generator.PriceFormat.Locale == "" {
+			return "en-us"
+		}
+		return strings.ToLower(strings.ReplaceAll(generator.PriceFormat.Locale, "_", "-"))
+	})
+
+	currency := NewInputField("Currency", "Currency symbol (locale currency if empty)", &message, func(v string) error {
+		generator.PriceFormat.Currency = v
+		return nil
+	}, func() string { return generator.PriceFormat.Currency })
+
+	const localePosition = "locale"
+	currencyPosition := NewSelectField("Currency position", []string{localePosition, "before", "after"}, &message, func(v string) error {
+		if v == localePosition {
+			v = ""
+		}
+		generator.PriceFormat.SymbolPosition = v
+		return nil
+	}, func() string {
+		if generator.PriceFormat.SymbolPosition == "" {
+			return localePosition
+		}
+		return strings.ToLower(generator.PriceFormat.SymbolPosition)
+	})
+
+	// Unit is validated when generating, partially written unit is not valid.
+	quantityUnit := NewInputField("Quantity unit", "Unit of quantities given only by a number, e.g. g or ml", &message, func(v string) error {
+		generator.PriceFormat.QuantityUnit = v
+		return nil
+	}, func() string { return generator.PriceFormat.QuantityUnit })
+
+	headerSearchRows := NewInputField("Header rows", "Number of first rows searched for the header row.", &message, func(v string) error {
+		if v == "" {
+			generator.HeaderSearchRows = 0
+			return nil
+		}
+		rows, err := strconv.ParseUint(strings.TrimSpace(v), 10, 0)
+		if err != nil {
+			return fmt.Errorf("Header rows must be positive integer not '%s'.", v)
+		}
+		generator.HeaderSearchRows = int(rows)
+		return nil
+	}, func() string {
+		if generator.HeaderSearchRows == 0 {
+			return ""
+		}
+		return fmt.Sprint(generator.HeaderSearchRows)
+	})
+
+	// Placeholders are validated when generating, partially written placeholder is not valid.
+	pdfFile := NewInputField("Pdf path", "Path or template, e.g. out/{input}_{date}.pdf (input name if empty)", &message, func(v string) error {
 		generator.PdfPath = v
 		return nil
 	}, func() string { return generator.PdfPath })
 
+	overwritePolicies := []string{}
+	for _, p := range core.OverwritePolicies {
+		overwritePolicies = append(overwritePolicies, string(p))
+	}
+	overwrite := NewSelectField("If pdf exists", overwritePolicies, &message, func(v string) error {
+		value, err := core.OverwritePolicyFromString(v)
+		if err != nil {
+			return err
+		}
+		generator.OverwritePolicy = value
+		return nil
+	}, func() string {
+		value, _ := core.OverwritePolicyFromString(string(generator.OverwritePolicy))
+		return string(value)
+	})
+
 	timesEachEan := NewInputField("Times each EAN", "Number of times each EAN code will be printed in the output PDF.", &message, func(v string) error {
 		if v == "" {
 			generator.TimesEachEAN = 1
@@ -151,24 +529,82 @@ func runUI(w *app.Window, log *core.MultiLogger) error {
 		return nil
 	}, func() string { return fmt.Sprint(generator.TimesEachEAN) })
 
-	mainPage := MainPage{
+	sheet := NewSelectField("Sheet", nil, &message, func(v string) error {
+		generator.Sheet = v
+		return nil
+	}, func() string { return generator.Sheet })
+
+	mergeSheets := NewCheckField("Merge all sheets", func(v bool) {
+		generator.MergeSheets = v
+	}, func() bool { return generator.MergeSheets })
+
+	mainPage = MainPage{
+		profiles:    &profiles,
 		file:        NewOpenFileDialog("Choose file"),
 		textHeader:  &textHeader,
 		eanHeader:   &eanHeader,
 		pdfFile:     &pdfFile,
 		timesHeader: &timesHeader,
+		validation:  NewValidationList(),
+		sheet:       &sheet,
+		mergeSheets: &mergeSheets,
 	}
 
-	optsPage := OptsPage{
-		csvComma:     &csvComma,
-		textHeader:   &textHeader,
-		eanHeader:    &eanHeader,
-		timesHeader:  &timesHeader,
-		pdfFile:      &pdfFile,
-		timesEachEan: &timesEachEan,
+	optsPage = OptsPage{
+		profiles:        &profiles,
+		csvComma:        &csvComma,
+		csvEncoding:     &csvEncoding,
+		textHeader:      &textHeader,
+		eanHeader:       &eanHeader,
+		timesHeader:     &timesHeader,
+		headerRows:      &headerSearchRows,
+		templateTop:     &templateTop,
+		templateBottom:  &templateBottom,
+		templateSide:    &templateSide,
+		templateBarcode: &templateBarcode,
+		serialEnabled:   &serialEnabled,
+		serialStart:     &serialStart,
+		serialStep:      &serialStep,
+		serialPadding:   &serialPadding,
+		serialPrefix:    &serialPrefix,
+		serialSuffix:    &serialSuffix,
+		priceHeader:     &priceHeader,
+		quantityHeader:  &quantityHeader,
+		priceLocale:     &priceLocale,
+		currency:        &currency,
+		currencyPos:     &currencyPosition,
+		quantityUnit:    &quantityUnit,
+		pdfFile:         &pdfFile,
+		overwrite:       &overwrite,
+		timesEachEan:    &timesEachEan,
+		symbology:       &symbology,
+		symbologyHeader: &symbologyHeader,
+		addOnHeader:     &addOnHeader,
+		convertISBN10:   &convertISBN10,
+		gs1Columns:      gs1Columns,
+		renderer:        &barcodeRenderer,
+		moduleWidth:     &moduleWidth,
+		gs1Rendering:    &gs1Rendering,
+		magnification:   &magnification,
+		labelSheet:      &labelSheet,
+		startPosition:   &startPosition,
+		completeCheck:   &completeCheckDigits,
+		errorPolicy:     &errorPolicy,
+		skippedReport:   &skippedReport,
+		report:          &report,
 	}
 
-	infoPage := InfoPage {}
+	infoPage := InfoPage{}
+
+	if configured != nil {
+		profiles.markActive(profile)
+	} else if err := profiles.Activate(profile); err != nil {
+		log.Logger.Warn("Cannot load configuration", "profile", profile, "err", err)
+		banner.setWarning(fmt.Sprintf("Configuration could not be loaded, default values are used: %v", err))
+		if profile != "" && profiles.Activate("") == nil {
+			banner.setWarning(fmt.Sprintf("Profile %s could not be loaded, local configuration is used: %v", profile, err))
+		}
+	}
 
 	for {
 		switch e := w.Event().(type) {
@@ -222,6 +658,23 @@ func runUI(w *app.Window, log *core.MultiLogger) error {
 							return button.Layout(gtx)
 						})
 					}),
+					layout.Expanded(func(gtx C) D {
+						return layout.S.Layout(gtx, func(gtx C) D {
+							return layout.Inset{Bottom: unit.Dp(20), Left: unit.Dp(20), Right: unit.Dp(20)}.Layout(gtx, func(gtx C) D {
+								if banner.message == "" {
+									return layout.Dimensions{}
+								}
+								if bannerBtn.Clicked(gtx) {
+									banner.message = ""
+									return layout.Dimensions{}
+								}
+								button := material.Button(th, &bannerBtn, banner.message+" ×")
+								button.Background = color.NRGBA{R: 255, G: 193, B: 7, A: 255}
+								button.Color = color.NRGBA{A: 255}
+								return button.Layout(gtx)
+							})
+						})
+					}),
 					layout.Expanded(func(gtx C) D {
 						if optionsBtn.Clicked(gtx) {
 							if actPage == PageOptions {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"gioui.org/layout"
 	"gioui.org/unit"
@@ -14,12 +15,20 @@ import (
 )
 
 type MainPage struct {
+	profiles    *profileSwitcher
 	file        openFileDialog
 	textHeader  *inputField
 	eanHeader   *inputField
 	timesHeader *inputField
 	pdfFile     *inputField
 	submitBtn   widget.Clickable
+	validateBtn widget.Clickable
+	validation  validationList
+	sheet       *selectField
+	mergeSheets *checkField
+	// Content the sheets were read from and names of its workbook sheets.
+	sheetsOf *string
+	sheets   []string
 }
 
 // Renders the main page layout with file selection, input fields, and submit functionality.
@@ -31,16 +40,28 @@ func (m *MainPage) mainPage(
 	message *Message,
 	log *slog.Logger,
 ) []layout.FlexChild {
-	pdfFileName := m.file.GetFileName()
-	if pdfFileName != "" && generator.PdfPath == "" {
-		generator.PdfPath = core.GeneratePdfPath(m.file.GetFileName())
-		m.pdfFile.Update()
-	}
+	m.updateSheets(generator)
 	return []layout.FlexChild{
 		layout.Rigid(func(gtx C) D {
 			return material.H4(th, "EANBaker").Layout(gtx)
 		}),
+		layout.Rigid(func(gtx C) D {
+			if m.profiles.Active() == "" {
+				return layout.Dimensions{}
+			}
+			return material.Label(th, 16, "Profile: "+m.profiles.Active()).Layout(gtx)
+		}),
 		layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, m.file.GetWidget(th, message))),
+		layout.Rigid(func(gtx C) D {
+			// Sheet selection makes sense only for loaded workbook.
+			if len(m.sheets) == 0 {
+				return layout.Dimensions{}
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, m.sheet.GetWidget(th))),
+				layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, m.mergeSheets.GetWidget(th))),
+			)
+		}),
 		layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, m.textHeader.GetWidget(th))),
 		layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, m.eanHeader.GetWidget(th))),
 		layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, m.timesHeader.GetWidget(th))),
@@ -56,27 +77,104 @@ func (m *MainPage) mainPage(
 					// Set generator values
 					generator.CsvPath = m.file.GetFileName()
 
-					if generator.PdfPath == "" {
-						generator.PdfPath = "./" + NAME + ".pdf"
+					// Configured path is kept as a template for the next file.
+					output := *generator
+					path, err := generator.OutputPath(m.profiles.Active(), time.Now())
+					if err != nil {
+						return err
 					}
-
-					err := generator.Generate(
+					output.OutputFile = path
+					err = output.Generate(
 						m.file.GetFileName(),
 						strings.NewReader(*m.file.GetFileContent()),
 						log)
 					if err != nil {
 						return err
 					}
-					message.setInfo(fmt.Sprintf("File %s saved.", generator.PdfPath))
+					generator.Serial = output.Serial
+					message.setInfo(fmt.Sprintf("File %s saved.", output.OutputFile))
 					log.Info("File generated", "generator", generator)
-					setHidden("./." + NAME + ".json")
+					if generator.Serial.Enabled {
+						// Next run continues the sequence.
+						if err := generator.SaveSerialState(m.profiles.ConfigPath()); err != nil {
+							return err
+						}
+					}
+					if m.profiles.Active() == "" {
+						setHidden(LocalConfigPath)
+					}
 					m.file.Reset()
-					generator.PdfPath = ""
-					m.pdfFile.Update()
+					m.validation.SetReport(nil)
 					return nil
 				}())
 			}
-			return material.Button(th, &m.submitBtn, "Submit").Layout(gtx)
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					if m.validateBtn.Clicked(gtx) {
+						message.setError(m.validate(generator, message))
+					}
+					return material.Button(th, &m.validateBtn, "Validate").Layout(gtx)
+				}),
+				layout.Rigid(inset(layout.Inset{Left: unit.Dp(10)}, func(gtx C) D {
+					return material.Button(th, &m.submitBtn, "Submit").Layout(gtx)
+				})),
+			)
 		})),
+		layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, m.validation.GetWidget(th))),
+	}
+}
+
+// Shows values of the generator again, e.g. after a profile is loaded.
+// Sheets of the loaded workbook are selected again.
+func (m *MainPage) update() {
+	m.textHeader.Update()
+	m.eanHeader.Update()
+	m.timesHeader.Update()
+	m.pdfFile.Update()
+	m.mergeSheets.Update()
+	m.sheetsOf = nil
+}
+
+// Validates rows of the loaded input file and shows the results list.
+// Returns an error if no file is loaded or records cannot be read.
+func (m *MainPage) validate(generator *core.Generator, message *Message) error {
+	if m.file.GetFileContent() == nil {
+		return errors.New("Input file must be set.")
+	}
+	report, err := generator.ValidateInput(
+		m.file.GetFileName(),
+		strings.NewReader(*m.file.GetFileContent()))
+	if err != nil {
+		m.validation.SetReport(nil)
+		return err
+	}
+	m.validation.SetReport(&report)
+	if report.HasErrors() {
+		return errors.New("Some rows cannot be printed.")
+	}
+	message.setInfo("All rows are valid.")
+	return nil
+}
+
+// Reads sheet names when a new file is loaded and offers them in the sheet
+// selection. Selected sheet is kept if the workbook contains it, otherwise
+// the first sheet is selected. Files that are not workbooks (CSV, TSV) have no sheets.
+func (m *MainPage) updateSheets(generator *core.Generator) {
+	content := m.file.GetFileContent()
+	if content == m.sheetsOf {
+		return
+	}
+	m.sheetsOf = content
+	m.sheets = nil
+	if content != nil {
+		m.sheets, _ = core.SheetNames(m.file.GetFileName(), strings.NewReader(*content))
+	}
+	if len(m.sheets) > 0 {
+		index, err := core.FindSheet(m.sheets, generator.Sheet)
+		if err != nil {
+			index = 0
+		}
+		generator.Sheet = m.sheets[index]
 	}
+	m.sheet.SetOptions(m.sheets)
 }
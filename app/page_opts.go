@@ -3,16 +3,53 @@ package app
 import (
 	"gioui.org/layout"
 	"gioui.org/unit"
+	"gioui.org/widget"
 	"gioui.org/widget/material"
 )
 
 type OptsPage struct {
-	csvComma     *inputField
-	textHeader   *inputField
-	eanHeader    *inputField
-	timesHeader  *inputField
-	pdfFile      *inputField
-	timesEachEan *inputField
+	profiles        *profileSwitcher
+	csvComma        *inputField
+	csvEncoding     *selectField
+	textHeader      *inputField
+	eanHeader       *inputField
+	timesHeader     *inputField
+	headerRows      *inputField
+	templateTop     *inputField
+	templateBottom  *inputField
+	templateSide    *inputField
+	templateBarcode *inputField
+	serialEnabled   *checkField
+	serialStart     *inputField
+	serialStep      *inputField
+	serialPadding   *inputField
+	serialPrefix    *inputField
+	serialSuffix    *inputField
+	priceHeader     *inputField
+	quantityHeader  *inputField
+	priceLocale     *selectField
+	currency        *inputField
+	currencyPos     *selectField
+	quantityUnit    *inputField
+	pdfFile         *inputField
+	overwrite       *selectField
+	timesEachEan    *inputField
+	symbology       *selectField
+	symbologyHeader *inputField
+	addOnHeader     *inputField
+	convertISBN10   *checkField
+	gs1Columns      []*inputField
+	renderer        *selectField
+	moduleWidth     *inputField
+	gs1Rendering    *checkField
+	magnification   *inputField
+	labelSheet      *selectField
+	startPosition   *inputField
+	completeCheck   *checkField
+	errorPolicy     *selectField
+	skippedReport   *inputField
+	report          *inputField
+	list            widget.List
 }
 
 // Renders the options page layout with configuration input fields and save functionality.
@@ -21,15 +58,82 @@ type OptsPage struct {
 func (o *OptsPage) optsPage(
 	th *material.Theme,
 ) []layout.FlexChild {
+	widgets := []layout.Widget{}
+	widgets = append(widgets,
+		o.profiles.GetWidget(th),
+		o.timesEachEan.GetWidget(th),
+		o.csvComma.GetWidget(th),
+		o.csvEncoding.GetWidget(th),
+		o.textHeader.GetWidget(th),
+		o.eanHeader.GetWidget(th),
+		o.timesHeader.GetWidget(th),
+		o.headerRows.GetWidget(th),
+		o.templateTop.GetWidget(th),
+		o.templateBottom.GetWidget(th),
+		o.templateSide.GetWidget(th),
+		o.templateBarcode.GetWidget(th),
+		o.serialEnabled.GetWidget(th),
+		o.serialStart.GetWidget(th),
+		o.serialStep.GetWidget(th),
+		o.serialPadding.GetWidget(th),
+		o.serialPrefix.GetWidget(th),
+		o.serialSuffix.GetWidget(th),
+		o.priceHeader.GetWidget(th),
+		o.quantityHeader.GetWidget(th),
+		o.priceLocale.GetWidget(th),
+		o.currency.GetWidget(th),
+		o.currencyPos.GetWidget(th),
+		o.quantityUnit.GetWidget(th),
+		o.pdfFile.GetWidget(th),
+		o.overwrite.GetWidget(th),
+		o.symbology.GetWidget(th),
+		o.symbologyHeader.GetWidget(th),
+		o.addOnHeader.GetWidget(th),
+		o.convertISBN10.GetWidget(th),
+	)
+	for _, field := range o.gs1Columns {
+		widgets = append(widgets, field.GetWidget(th))
+	}
+	widgets = append(widgets,
+		o.renderer.GetWidget(th),
+		o.moduleWidth.GetWidget(th),
+		o.gs1Rendering.GetWidget(th),
+		o.magnification.GetWidget(th),
+		o.labelSheet.GetWidget(th),
+		o.startPosition.GetWidget(th),
+		o.completeCheck.GetWidget(th),
+		o.errorPolicy.GetWidget(th),
+		o.skippedReport.GetWidget(th),
+		o.report.GetWidget(th),
+	)
+	o.list.Axis = layout.Vertical
 	return []layout.FlexChild{
 		layout.Rigid(func(gtx C) D {
 			return material.H4(th, "Options").Layout(gtx)
 		}),
-		layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, o.timesEachEan.GetWidget(th))),
-		layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, o.csvComma.GetWidget(th))),
-		layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, o.textHeader.GetWidget(th))),
-		layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, o.eanHeader.GetWidget(th))),
-		layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, o.timesHeader.GetWidget(th))),
-		layout.Rigid(inset(layout.Inset{Top: unit.Dp(15)}, o.pdfFile.GetWidget(th))),
+		// Options do not fit on small windows, so they are scrollable.
+		layout.Flexed(1, func(gtx C) D {
+			return material.List(th, &o.list).Layout(gtx, len(widgets), func(gtx C, i int) D {
+				return inset(layout.Inset{Top: unit.Dp(15)}, widgets[i])(gtx)
+			})
+		}),
+	}
+}
+
+// Shows values of the generator again, e.g. after a profile is loaded.
+func (o *OptsPage) update() {
+	for _, field := range []interface{ Update() }{
+		o.csvComma, o.csvEncoding, o.textHeader, o.eanHeader, o.timesHeader, o.headerRows,
+		o.templateTop, o.templateBottom, o.templateSide, o.templateBarcode,
+		o.serialEnabled, o.serialStart, o.serialStep, o.serialPadding, o.serialPrefix, o.serialSuffix,
+		o.priceHeader, o.quantityHeader, o.priceLocale, o.currency, o.currencyPos, o.quantityUnit,
+		o.pdfFile, o.overwrite, o.timesEachEan, o.symbology, o.symbologyHeader, o.addOnHeader, o.convertISBN10,
+		o.renderer, o.moduleWidth, o.gs1Rendering, o.magnification, o.labelSheet, o.startPosition,
+		o.completeCheck, o.errorPolicy, o.skippedReport, o.report,
+	} {
+		field.Update()
+	}
+	for _, field := range o.gs1Columns {
+		field.Update()
 	}
 }
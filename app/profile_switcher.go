@@ -0,0 +1,355 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/explorer"
+	"github.com/Fanteria/EANBaker/core"
+)
+
+// Configuration file in the current directory used when no profile is active.
+const LocalConfigPath = "./." + NAME + ".json"
+
+// Key of the local configuration in the profile selection, profile
+// names cannot start with a colon.
+const localProfileKey = ":local"
+
+type profileSwitcher struct {
+	store     core.ProfileStore
+	generator *core.Generator
+	message   *Message
+	log       *slog.Logger
+	// Name of the active profile, empty for the local configuration.
+	active       string
+	names        []string
+	enum         widget.Enum
+	name         widget.Editor
+	saveBtn      widget.Clickable
+	createBtn    widget.Clickable
+	duplicateBtn widget.Clickable
+	deleteBtn    widget.Clickable
+	importBtn    widget.Clickable
+	exportBtn    widget.Clickable
+	result       chan profileResult
+	// Called after a profile is loaded, so fields show its values.
+	onLoad func()
+}
+
+// Result of the import or export file dialog.
+type profileResult struct {
+	// Imported profile name and its content, empty for export.
+	name    string
+	content []byte
+	info    string
+	err     error
+}
+
+// Creates a new profile switcher of the profiles in the store. Loaded
+// profile replaces the generator values and onLoad is called.
+func NewProfileSwitcher(store core.ProfileStore, generator *core.Generator, message *Message, log *slog.Logger, onLoad func()) profileSwitcher {
+	ret := profileSwitcher{
+		store:     store,
+		generator: generator,
+		message:   message,
+		log:       log,
+		name:      widget.Editor{SingleLine: true},
+		result:    make(chan profileResult),
+		onLoad:    onLoad,
+	}
+	ret.enum.Value = localProfileKey
+	message.setError(ret.refresh())
+	return ret
+}
+
+// Returns the name of the active profile, empty for the local configuration.
+func (p *profileSwitcher) Active() string {
+	return p.active
+}
+
+// Returns the file of the active profile or the local configuration file.
+func (p *profileSwitcher) ConfigPath() string {
+	if p.active == "" {
+		return LocalConfigPath
+	}
+	return p.store.Path(p.active)
+}
+
+// Loads the profile to the generator, empty name loads the local
+// configuration. The active profile is kept if loading fails.
+func (p *profileSwitcher) Activate(name string) error {
+	generator, err := p.load(name)
+	if err != nil {
+		p.enum.Value = p.key(p.active)
+		return err
+	}
+	*p.generator = *generator
+	p.markActive(name)
+	p.onLoad()
+	return nil
+}
+
+// Marks the profile active without loading it, e.g. if the generator
+// is already configured from the command line.
+func (p *profileSwitcher) markActive(name string) {
+	p.active = name
+	p.enum.Value = p.key(name)
+}
+
+// Returns the configuration of the profile resolved from defaults,
+// configuration file of the user, the local configuration file,
+// the profile and EANBAKER_* environment variables.
+func (p *profileSwitcher) load(name string) (*core.Generator, error) {
+	layers, err := p.store.ConfigLayers(LocalConfigPath, name, os.Environ(), p.log)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := core.ResolveConfig(layers...)
+	if err != nil {
+		return nil, err
+	}
+	return &resolved.Generator, nil
+}
+
+// Reads names of the profiles again.
+func (p *profileSwitcher) refresh() error {
+	names, err := p.store.Names()
+	if err != nil {
+		return err
+	}
+	p.names = names
+	return nil
+}
+
+// Returns the selection key of the profile.
+func (p *profileSwitcher) key(name string) string {
+	if name == "" {
+		return localProfileKey
+	}
+	return name
+}
+
+// Saves the generator to the active profile or the local configuration.
+func (p *profileSwitcher) save() error {
+	if p.active == "" {
+		if err := p.generator.Save(LocalConfigPath); err != nil {
+			return err
+		}
+		setHidden(LocalConfigPath)
+		p.message.setInfo("Configuration saved.")
+		return nil
+	}
+	if err := p.store.Save(p.active, p.generator); err != nil {
+		return err
+	}
+	p.message.setInfo(fmt.Sprintf("Profile %s saved.", p.active))
+	return nil
+}
+
+// Creates the profile of the entered name from the current values and activates it.
+func (p *profileSwitcher) create() error {
+	name := strings.TrimSpace(p.name.Text())
+	if err := p.store.Create(name, p.generator); err != nil {
+		return err
+	}
+	return p.added(name, "created")
+}
+
+// Creates a copy of the active profile with the entered name and activates it.
+func (p *profileSwitcher) duplicate() error {
+	if p.active == "" {
+		return errors.New("Select a profile to duplicate.")
+	}
+	name := strings.TrimSpace(p.name.Text())
+	if err := p.store.Duplicate(p.active, name); err != nil {
+		return err
+	}
+	return p.added(name, "created")
+}
+
+// Deletes the active profile and activates the local configuration.
+func (p *profileSwitcher) delete() error {
+	if p.active == "" {
+		return errors.New("Select a profile to delete.")
+	}
+	name := p.active
+	if err := p.store.Delete(name); err != nil {
+		return err
+	}
+	if err := p.refresh(); err != nil {
+		return err
+	}
+	if err := p.Activate(""); err != nil {
+		return err
+	}
+	p.message.setInfo(fmt.Sprintf("Profile %s deleted.", name))
+	return nil
+}
+
+// Activates the new profile and shows the info message.
+func (p *profileSwitcher) added(name string, action string) error {
+	if err := p.refresh(); err != nil {
+		return err
+	}
+	if err := p.Activate(name); err != nil {
+		return err
+	}
+	p.name.SetText("")
+	p.message.setInfo(fmt.Sprintf("Profile %s %s.", name, action))
+	return nil
+}
+
+// Opens a dialog to choose the file of the imported profile. Profile is
+// named by the entered name or the file name.
+func (p *profileSwitcher) importFile() {
+	name := strings.TrimSpace(p.name.Text())
+	go func() {
+		window := new(app.Window)
+		picker := explorer.NewExplorer(window)
+		file, err := picker.ChooseFile(".json")
+		if err != nil {
+			p.result <- profileResult{err: err}
+			return
+		}
+		// If dialog is closed, just do nothing.
+		if file == nil {
+			return
+		}
+		defer file.Close()
+		content, err := io.ReadAll(file)
+		if err != nil {
+			p.result <- profileResult{err: err}
+			return
+		}
+		if f, ok := file.(*os.File); ok && name == "" {
+			name = core.ProfileNameFromPath(f.Name())
+		}
+		if name == "" {
+			p.result <- profileResult{err: errors.New("Enter name of the imported profile.")}
+			return
+		}
+		p.result <- profileResult{name: name, content: content}
+	}()
+}
+
+// Opens a dialog to choose the file the active profile is exported to.
+func (p *profileSwitcher) exportFile() error {
+	if p.active == "" {
+		return errors.New("Select a profile to export.")
+	}
+	var content bytes.Buffer
+	if err := p.store.Export(p.active, &content); err != nil {
+		return err
+	}
+	name := p.active
+	go func() {
+		window := new(app.Window)
+		picker := explorer.NewExplorer(window)
+		file, err := picker.CreateFile(name + ".json")
+		if err != nil {
+			p.result <- profileResult{err: err}
+			return
+		}
+		if file == nil {
+			return
+		}
+		if _, err := file.Write(content.Bytes()); err != nil {
+			file.Close()
+			p.result <- profileResult{err: err}
+			return
+		}
+		p.result <- profileResult{info: fmt.Sprintf("Profile %s exported.", name), err: file.Close()}
+	}()
+	return nil
+}
+
+// Checks for results of file dialogs without blocking.
+func (p *profileSwitcher) checkResult() {
+	select {
+	case res := <-p.result:
+		switch {
+		case res.err != nil:
+			p.message.setError(res.err)
+		case res.content != nil:
+			if err := p.store.Import(res.name, bytes.NewReader(res.content), false); err != nil {
+				p.message.setError(err)
+				return
+			}
+			p.message.setError(p.added(res.name, "imported"))
+		default:
+			p.message.setInfo(res.info)
+		}
+	default:
+	}
+}
+
+// Returns a layout widget with the profile selection, name of new profiles
+// and buttons to save, create, duplicate, delete, import and export profiles.
+func (p *profileSwitcher) GetWidget(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		p.checkResult()
+		if p.enum.Update(gtx) {
+			name := p.enum.Value
+			if name == localProfileKey {
+				name = ""
+			}
+			p.message.setError(p.Activate(name))
+		}
+		actions := []struct {
+			btn    *widget.Clickable
+			text   string
+			action func() error
+		}{
+			{&p.saveBtn, "Save", p.save},
+			{&p.createBtn, "New", p.create},
+			{&p.duplicateBtn, "Duplicate", p.duplicate},
+			{&p.deleteBtn, "Delete", p.delete},
+			{&p.importBtn, "Import", func() error { p.importFile(); return nil }},
+			{&p.exportBtn, "Export", p.exportFile},
+		}
+
+		profiles := []layout.FlexChild{
+			layout.Rigid(material.RadioButton(th, &p.enum, localProfileKey, "Local ("+LocalConfigPath+")").Layout),
+		}
+		for _, name := range p.names {
+			profiles = append(profiles, layout.Rigid(material.RadioButton(th, &p.enum, name, name).Layout))
+		}
+		buttons := []layout.FlexChild{}
+		for i, a := range actions {
+			if a.btn.Clicked(gtx) {
+				p.message.setError(a.action())
+			}
+			left := unit.Dp(10)
+			if i == 0 {
+				left = 0
+			}
+			buttons = append(buttons, layout.Rigid(inset(layout.Inset{Left: left}, material.Button(th, a.btn, a.text).Layout)))
+		}
+
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(material.Label(th, 16, "Profile:").Layout),
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx, profiles...)
+			}),
+			layout.Rigid(inset(layout.Inset{Top: unit.Dp(10)}, func(gtx C) D {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Rigid(inset(layout.Inset{Right: unit.Dp(5)}, material.Label(th, 16, "New profile name:").Layout)),
+					layout.Rigid(material.Editor(th, &p.name, "Name of new, duplicated or imported profile").Layout),
+				)
+			})),
+			layout.Rigid(inset(layout.Inset{Top: unit.Dp(10)}, func(gtx C) D {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, buttons...)
+			})),
+		)
+	}
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/Fanteria/EANBaker/core"
+)
+
+func TestProfileSwitcher(t *testing.T) {
+	store := core.ProfileStore{Dir: t.TempDir()}
+	generator := &core.Generator{TextHeader: "Name", TimesEachEAN: 1}
+	msg := &Message{}
+	loaded := 0
+	switcher := NewProfileSwitcher(store, generator, msg, slog.New(slog.NewTextHandler(io.Discard, nil)), func() { loaded++ })
+
+	switcher.name.SetText(" zebra ")
+	if err := switcher.create(); err != nil {
+		t.Fatalf("create() failed: %v", err)
+	}
+	if switcher.Active() != "zebra" || switcher.enum.Value != "zebra" || loaded != 1 {
+		t.Errorf("Active() = %q, enum = %q, loaded %d times, want zebra loaded once", switcher.Active(), switcher.enum.Value, loaded)
+	}
+	if switcher.ConfigPath() != store.Path("zebra") {
+		t.Errorf("ConfigPath() = %q, want profile path", switcher.ConfigPath())
+	}
+
+	generator.TextHeader = "Product"
+	if err := switcher.save(); err != nil {
+		t.Fatalf("save() failed: %v", err)
+	}
+	switcher.name.SetText("shop")
+	if err := switcher.duplicate(); err != nil {
+		t.Fatalf("duplicate() failed: %v", err)
+	}
+	if generator.TextHeader != "Product" || len(switcher.names) != 2 {
+		t.Errorf("TextHeader = %q, names = %v, want duplicated saved profile", generator.TextHeader, switcher.names)
+	}
+
+	if err := switcher.Activate("missing"); err == nil {
+		t.Error("Activate() should fail for missing profile")
+	}
+	if switcher.Active() != "shop" || switcher.enum.Value != "shop" {
+		t.Errorf("Active() = %q, enum = %q, want shop kept", switcher.Active(), switcher.enum.Value)
+	}
+
+	if err := switcher.delete(); err != nil {
+		t.Fatalf("delete() failed: %v", err)
+	}
+	if switcher.Active() != "" || switcher.enum.Value != localProfileKey || len(switcher.names) != 1 {
+		t.Errorf("Active() = %q, names = %v, want local configuration", switcher.Active(), switcher.names)
+	}
+	if err := switcher.delete(); err == nil {
+		t.Error("delete() should fail without active profile")
+	}
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type selectField struct {
+	enum     widget.Enum
+	name     string
+	options  []string
+	message  *Message
+	setValue func(value string) error
+	getValue func() string
+}
+
+// Creates a new select field widget offering one of the given options.
+// The selected option is read by getValue and every change made by
+// the user is passed to setValue.
+func NewSelectField(name string, options []string, message *Message, setValue func(value string) error, getValue func() string) selectField {
+	ret := selectField{
+		name:     name,
+		options:  options,
+		message:  message,
+		setValue: setValue,
+		getValue: getValue,
+	}
+	ret.Update()
+	return ret
+}
+
+// Expect to set valid value
+func (s *selectField) Update() {
+	s.enum.Value = s.getValue()
+}
+
+// Replaces offered options and selects the current value again.
+func (s *selectField) SetOptions(options []string) {
+	s.options = options
+	s.Update()
+}
+
+// Returns a layout widget for the select field.
+// Creates a horizontal layout with a label (field name) and a radio button for each option.
+func (s *selectField) GetWidget(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		if s.enum.Update(gtx) {
+			err := s.setValue(s.enum.Value)
+			if err != nil {
+				s.message.setError(err)
+				s.Update()
+			}
+		}
+		childs := []layout.FlexChild{
+			layout.Rigid(inset(layout.Inset{Right: unit.Dp(5)}, func(gtx C) D {
+				return material.Label(th, 16, s.name+":").Layout(gtx)
+			})),
+		}
+		for _, option := range s.options {
+			childs = append(childs, layout.Rigid(func(gtx C) D {
+				return material.RadioButton(th, &s.enum, option, option).Layout(gtx)
+			}))
+		}
+		return layout.Flex{
+			Axis:      layout.Horizontal,
+			Alignment: layout.Middle,
+		}.Layout(gtx, childs...)
+	}
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"testing"
+)
+
+func TestNewSelectField(t *testing.T) {
+	msg := &Message{}
+	value := "b"
+	field := NewSelectField(
+		"Test",
+		[]string{"a", "b", "c"},
+		msg,
+		func(v string) error {
+			value = v
+			return nil
+		},
+		func() string {
+			return value
+		},
+	)
+
+	if field.name != "Test" {
+		t.Errorf("name = %v, want %v", field.name, "Test")
+	}
+	if len(field.options) != 3 {
+		t.Errorf("len(options) = %d, want 3", len(field.options))
+	}
+	if field.enum.Value != "b" {
+		t.Errorf("enum.Value = %v, want %v", field.enum.Value, "b")
+	}
+}
+
+func TestSelectField_SetOptions(t *testing.T) {
+	msg := &Message{}
+	value := "a"
+	field := NewSelectField(
+		"Test",
+		[]string{"a"},
+		msg,
+		func(v string) error {
+			value = v
+			return nil
+		},
+		func() string {
+			return value
+		},
+	)
+
+	value = "y"
+	field.SetOptions([]string{"x", "y"})
+	if len(field.options) != 2 {
+		t.Errorf("len(options) = %d, want 2", len(field.options))
+	}
+	if field.enum.Value != "y" {
+		t.Errorf("enum.Value = %v, want %v", field.enum.Value, "y")
+	}
+}
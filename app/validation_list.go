@@ -0,0 +1,55 @@
+package app
+
+import (
+	"image/color"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/Fanteria/EANBaker/core"
+)
+
+type validationList struct {
+	list   widget.List
+	report *core.ValidationReport
+}
+
+// Creates a new empty validation results list.
+func NewValidationList() validationList {
+	return validationList{
+		list: widget.List{List: layout.List{Axis: layout.Vertical}},
+	}
+}
+
+// Sets the report to show, nil hides the list.
+func (v *validationList) SetReport(report *core.ValidationReport) {
+	v.report = report
+	v.list.Position = layout.Position{}
+}
+
+// Returns a layout widget with summary of the report and a scrollable
+// list of rows with problems. Rows that cannot be printed are shown in red.
+func (v *validationList) GetWidget(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		if v.report == nil {
+			return layout.Dimensions{}
+		}
+		problems := v.report.Problems()
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return material.Label(th, 16, v.report.Summary()).Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Max.Y = min(gtx.Constraints.Max.Y, gtx.Dp(unit.Dp(150)))
+				return material.List(th, &v.list).Layout(gtx, len(problems), func(gtx C, i int) D {
+					label := material.Label(th, 14, problems[i].String())
+					if !problems[i].Status.Printable() {
+						label.Color = color.NRGBA{R: 176, G: 0, B: 32, A: 255}
+					}
+					return label.Layout(gtx)
+				})
+			}),
+		)
+	}
+}
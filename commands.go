@@ -0,0 +1,444 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Fanteria/EANBaker/app"
+	"github.com/Fanteria/EANBaker/core"
+	"github.com/Fanteria/EANBaker/values"
+)
+
+// Input that can be read repeatedly, e.g. to detect its format.
+type input interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// Opens the input file, or the standard input if path is core.StdStream.
+// Standard input is buffered, so pipes can be read as seekable files.
+func openInput(path string) (input, error) {
+	if path != core.StdStream {
+		return os.Open(path)
+	}
+	if _, err := os.Stdin.Seek(0, io.SeekCurrent); err == nil {
+		// Redirected file can be read directly.
+		return nopCloser{os.Stdin}, nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	return nopCloser{bytes.NewReader(data)}, nil
+}
+
+// Input that is not closed, the standard input is left open.
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// Creates the flag set of the command printing the usage text before the flags.
+func newFlagSet(name string, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+const GENERATE_USAGE string = `Usage:
+  eanbaker generate [flags]
+  eanbaker [flags]
+
+Description:
+  Reads the input file, extracts text and EAN code columns by their headers, and generates a PDF file containing barcodes.
+
+Flags:
+`
+
+// Generates the PDF from the input file.
+func generateCommand(args []string, logger *core.MultiLogger) error {
+	flags := newFlagSet("generate", GENERATE_USAGE)
+	generatorFlags := newGeneratorFlags(flags)
+	validate_only := flags.Bool("validate-only", false, `Only validate rows of the input file, same as "eanbaker validate".`)
+	print_version := flags.Bool("version", false, `Print version information and exit, same as "eanbaker version".`)
+	if err := parseFlags(flags, args, false); err != nil {
+		return err
+	}
+	if *print_version {
+		return versionCommand(nil, logger)
+	}
+	generator, err := generatorFlags.Generator(logger.Logger)
+	if err != nil {
+		return err
+	}
+	if *validate_only {
+		return validate(generator)
+	}
+
+	if generator.OutputFile, err = generator.OutputPath(generatorFlags.profile, time.Now()); err != nil {
+		return invalid(err)
+	}
+
+	//Open the CSV file
+	file, err := openInput(generator.CsvPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var output io.Writer
+	if generator.OutputFile == core.StdStream {
+		output = os.Stdout
+	}
+	if err := generator.GenerateToWriter(generator.CsvPath, file, output, logger.Logger); err != nil {
+		return invalid(err)
+	}
+	return saveSerialState(generatorFlags, generator)
+}
+
+// Saves the last issued serial number to the serial state file, the profile
+// or the project configuration file, so the next run continues the sequence.
+func saveSerialState(flags *generatorFlags, generator *core.Generator) error {
+	if !generator.Serial.Enabled {
+		return nil
+	}
+	path, err := flags.serialStatePath()
+	if err != nil || path == "" {
+		return err
+	}
+	return generator.SaveSerialState(path)
+}
+
+const BATCH_USAGE string = `Usage:
+  eanbaker batch [flags] INPUT...
+
+Description:
+  Generates PDFs from many input files at once. INPUT is a file, a directory (its CSV, TSV, XLSX, XLS and ODS files)
+  or a glob pattern, e.g. "suppliers/*.csv". One PDF per input is written to -output-dir, or a single PDF with
+  labels of all inputs if -pdf is set. Prints the result of every input, exits with non-zero code if any failed.
+  Inputs with serial numbers are processed one by one, so the numbers continue in order of the inputs.
+
+Flags:
+`
+
+// Generates PDFs from all inputs given by the arguments.
+func batchCommand(args []string, logger *core.MultiLogger) error {
+	flags := newFlagSet("batch", BATCH_USAGE)
+	generatorFlags := newGeneratorFlags(flags)
+	output_dir := flags.String("output-dir", ".", "Directory the PDF of every input is written to, the name is the input name with .pdf suffix. Directory can contain placeholders {profile} and {date}, e.g. \"out/{date}\".")
+	jobs := flags.Int("jobs", runtime.NumCPU(), "Maximal number of inputs processed at once.")
+	if err := parseFlags(flags, args, true); err != nil {
+		return err
+	}
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["csv"] {
+		flags.Usage()
+		return &exitError{code: ExitUsage, err: errors.New("Inputs of batch are given as arguments, not by -csv")}
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return &exitError{code: ExitUsage, err: errors.New("Batch needs at least one INPUT")}
+	}
+	if set["pdf"] && generatorFlags.generator.PdfPath == core.StdStream {
+		// Summary is printed to the standard output.
+		return &exitError{code: ExitUsage, err: errors.New("Batch cannot write PDF to the standard output")}
+	}
+	if *jobs < 1 {
+		return &exitError{code: ExitUsage, err: fmt.Errorf("Jobs must be positive, got %d", *jobs)}
+	}
+	generator, err := generatorFlags.Generator(logger.Logger)
+	if err != nil {
+		return err
+	}
+	inputs, err := core.ExpandInputs(flags.Args())
+	if err != nil {
+		return invalid(err)
+	}
+
+	now := time.Now()
+	var results []core.BatchResult
+	if set["pdf"] {
+		// Merged PDF has no single input.
+		generator.CsvPath = ""
+		if generator.OutputFile, err = generator.OutputPath(generatorFlags.profile, now); err != nil {
+			return invalid(err)
+		}
+		results, err = generator.GenerateMerged(inputs, *jobs, logger.Logger)
+	} else {
+		var dir string
+		if dir, err = core.ExpandOutputPath(*output_dir, core.OutputPathValues{Profile: generatorFlags.profile, Time: now}); err != nil {
+			return invalid(err)
+		}
+		results, err = generator.GenerateBatch(inputs, dir, *jobs, logger.Logger)
+	}
+	if results == nil && err != nil {
+		return invalid(err)
+	}
+
+	var failed error
+	count := 0
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Printf("FAILED  %s: %v\n", result.Input, result.Err)
+			if failed == nil {
+				failed = result.Err
+			}
+			count++
+		} else {
+			fmt.Printf("OK      %s -> %s\n", result.Input, result.Output)
+		}
+	}
+	fmt.Printf("%d inputs: %d generated, %d failed\n", len(results), len(results)-count, count)
+	if err != nil {
+		// Merged PDF was not generated.
+		return invalid(err)
+	}
+	if err := saveSerialState(generatorFlags, generator); err != nil {
+		return err
+	}
+	if failed != nil {
+		return &exitError{code: exitCode(invalid(failed)), err: fmt.Errorf("%d of %d inputs failed", count, len(results))}
+	}
+	return nil
+}
+
+const VALIDATE_USAGE string = `Usage:
+  eanbaker validate [flags]
+
+Description:
+  Validates every row of the input file without generating anything. Prints rows with a problem and a summary.
+  Exits with code 3 if any row cannot be printed.
+
+Flags:
+`
+
+// Validates rows of the input file.
+func validateCommand(args []string, logger *core.MultiLogger) error {
+	flags := newFlagSet("validate", VALIDATE_USAGE)
+	generatorFlags := newGeneratorFlags(flags)
+	if err := parseFlags(flags, args, false); err != nil {
+		return err
+	}
+	generator, err := generatorFlags.Generator(logger.Logger)
+	if err != nil {
+		return err
+	}
+	return validate(generator)
+}
+
+// Validates the input file and prints every row with a problem and a summary.
+// Returns an error if any row cannot be printed.
+func validate(generator *core.Generator) error {
+	file, err := openInput(generator.CsvPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	report, err := generator.ValidateInput(generator.CsvPath, file)
+	if err != nil {
+		return invalid(err)
+	}
+	for _, row := range report.Problems() {
+		fmt.Println(row)
+	}
+	fmt.Println(report.Summary())
+	if report.HasErrors() {
+		return invalid(errors.New("Input contains rows that cannot be printed"))
+	}
+	return nil
+}
+
+const PREVIEW_USAGE string = `Usage:
+  eanbaker preview [flags]
+
+Description:
+  Prints page, source row, status, barcode content and texts of the first labels without writing any file.
+  Labels whose barcode cannot be generated are shown as invalid with the reason.
+
+Flags:
+`
+
+// Prints the first labels of the input.
+func previewCommand(args []string, logger *core.MultiLogger) error {
+	flags := newFlagSet("preview", PREVIEW_USAGE)
+	generatorFlags := newGeneratorFlags(flags)
+	limit := flags.Int("limit", 10, "Maximal number of labels printed.")
+	if err := parseFlags(flags, args, false); err != nil {
+		return err
+	}
+	if *limit < 1 {
+		return &exitError{code: ExitUsage, err: fmt.Errorf("Limit must be positive, got %d", *limit)}
+	}
+	generator, err := generatorFlags.Generator(logger.Logger)
+	if err != nil {
+		return err
+	}
+	file, err := openInput(generator.CsvPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	labels, err := generator.Preview(generator.CsvPath, file, *limit, logger.Logger)
+	if err != nil {
+		return invalid(err)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "PAGE\tROW\tCOPY\tSTATUS\tBARCODE\tTOP\tBOTTOM\tSIDE")
+	for _, label := range labels {
+		fmt.Fprintf(writer, "%d\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			label.Page, label.Record.Row, label.Copy, label.Status,
+			label.Ean, label.Label.Top, label.Label.Bottom, label.Label.Side)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	for _, label := range labels {
+		if label.Err != nil {
+			fmt.Printf("Row %d: %v\n", label.Record.Row, label.Err)
+		}
+	}
+	return nil
+}
+
+const VERSION_USAGE string = `Usage:
+  eanbaker version
+
+Description:
+  Prints version, commit and build date.
+`
+
+// Prints version information.
+func versionCommand(args []string, logger *core.MultiLogger) error {
+	flags := newFlagSet("version", VERSION_USAGE)
+	if err := parseFlags(flags, args, false); err != nil {
+		return err
+	}
+	fmt.Println("Version:", values.Version)
+	fmt.Println("Commit:", values.Commit)
+	fmt.Println("Build date:", values.Date)
+	return nil
+}
+
+const GUI_USAGE string = `Usage:
+  eanbaker gui [-profile NAME] [flags]
+  eanbaker
+
+Description:
+  Starts the graphical interface. Settings are loaded from and saved to .EANBaker.json in the current directory,
+  or to the profile selected on the options page. Flags given on the command line override the loaded values,
+  see "Configuration" in "eanbaker help" for the precedence of configuration sources.
+
+Flags:
+`
+
+// Starts the graphical interface.
+func guiCommand(args []string, logger *core.MultiLogger) error {
+	flags := newFlagSet("gui", GUI_USAGE)
+	generatorFlags := newGeneratorFlags(flags)
+	if err := parseFlags(flags, args, false); err != nil {
+		return err
+	}
+	// Values are validated when generating, input is chosen in the GUI.
+	resolved, err := generatorFlags.Resolve(logger.Logger)
+	if err == nil {
+		return app.RunGuiWithConfig(logger, &resolved.Generator, generatorFlags.profile, nil)
+	}
+	// Configuration that cannot be loaded is reported in the GUI, so it can be fixed there.
+	warning := err
+	resolved, err = core.ResolveConfig(core.DefaultsLayer(), core.FuncLayer("flag", generatorFlags.apply))
+	if err != nil {
+		return invalid(err)
+	}
+	return app.RunGuiWithConfig(logger, &resolved.Generator, "", warning)
+}
+
+const ALLOCATE_USAGE string = `Usage:
+  eanbaker allocate -prefix COMPANY_PREFIX -registry FILE [flags]
+
+Description:
+  Assigns free EAN-13 codes of the GS1 company prefix to rows with empty EAN column and writes the table back in the same format (CSV or XLSX).
+  Used codes are kept in the registry file, which is saved before the table is written, so codes are never assigned twice.
+
+Flags:
+`
+
+// Assigns EAN-13 codes from the registry to rows of the input without code.
+// Registry is saved before the enriched input is written, so a failure
+// writing the input never leads to reusing the codes.
+func allocateCommand(args []string, logger *core.MultiLogger) error {
+	flags := newFlagSet("allocate", ALLOCATE_USAGE)
+	generator := core.Generator{}
+	flags.StringVar(&generator.CsvPath, "csv", "data.csv", "Path to the input data in CSV, TSV or XLSX.")
+	output := flags.String("output", "", "Path the enriched input is written to. The input file is overwritten if not set.")
+	prefix := flags.String("prefix", "", "GS1 company prefix (6 to 11 digits). Required when the registry does not exist yet.")
+	registry_path := flags.String("registry", "ean-registry.json", "JSON file with EAN codes already used with the company prefix.")
+	flags.StringVar(&generator.TextHeader, "text-header", "Material Number", "Header of column with product names stored to the registry.")
+	flags.StringVar(&generator.EanHeader, "ean-header", "ean", "Header of column the codes are written to.")
+	flags.IntVar(&generator.HeaderSearchRows, "header-search-rows", core.DefaultHeaderSearchRows, "Number of first rows searched for the header row.")
+	comma_string := flags.String("csv-separator", "", "CSV file column separator. Detected from the content if not set.")
+	encoding_string := flags.String("csv-encoding", "", "CSV file encoding. Detected from the content if not set.")
+	flags.StringVar(&generator.Sheet, "sheet", "", "Excel sheet given by name or one based position. The first sheet is used if not set.")
+	flags.BoolVar(&generator.MergeSheets, "merge-sheets", false, "Assign codes in all Excel sheets.")
+	if err := parseFlags(flags, args, false); err != nil {
+		return err
+	}
+
+	comma, err := core.CommaFromString(*comma_string)
+	if err != nil {
+		return invalid(err)
+	}
+	generator.CsvComma = comma
+	encoding, err := core.TextEncodingFromString(*encoding_string)
+	if err != nil {
+		return invalid(err)
+	}
+	generator.CsvEncoding = encoding
+	if *output == "" {
+		*output = generator.CsvPath
+	}
+
+	registry, err := core.LoadEanRegistry(*registry_path, *prefix)
+	if err != nil {
+		return invalid(err)
+	}
+	file, err := os.Open(generator.CsvPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var buf bytes.Buffer
+	allocated, err := generator.AllocateEans(generator.CsvPath, file, registry, &buf)
+	if err != nil {
+		return invalid(err)
+	}
+	// Codes found in the input are stored even if nothing was assigned.
+	if err := registry.Save(*registry_path); err != nil {
+		return err
+	}
+	if len(allocated) == 0 {
+		fmt.Println("All rows already have EAN code.")
+		return nil
+	}
+	if err := core.WriteFileAtomic(*output, buf.Bytes()); err != nil {
+		return err
+	}
+	for _, allocation := range allocated {
+		fmt.Printf("Row %d: %s %s\n", allocation.Row, allocation.Ean, allocation.Text)
+	}
+	fmt.Printf("Assigned %d codes, written to %s.\n", len(allocated), *output)
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/Fanteria/EANBaker/core"
+)
+
+func TestGenerateCommand_SerialState(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	if err := os.WriteFile("data.csv", []byte("Name,EAN\nMilk,5901234123457\nTea,4006381333931\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Serial numbers are enabled only in the project configuration file.
+	if err := os.WriteFile(configPath, []byte(`{"text_header": "Name", "ean_header": "EAN", "serial": {"enabled": true, "start": 10}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := core.NewMultiLogger(slog.LevelError)
+	for _, want := range []int64{11, 13} {
+		if err := generateCommand([]string{"-csv", "data.csv"}, logger); err != nil {
+			t.Fatalf("generateCommand() failed: %v", err)
+		}
+		last, err := core.LoadSerialState(configPath)
+		if err != nil || last == nil || *last != want {
+			t.Fatalf("LoadSerialState() = %v, %v, want %d", last, err, want)
+		}
+	}
+}
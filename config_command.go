@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Fanteria/EANBaker/app"
+	"github.com/Fanteria/EANBaker/core"
+)
+
+// Configuration file of the GUI in the current directory.
+var configPath = app.LocalConfigPath
+
+const CONFIG_USAGE string = `Usage:
+  eanbaker config show [-config FILE | -profile NAME]
+  eanbaker config show -resolved [-config FILE] [-profile NAME] [flags]
+  eanbaker config set [-config FILE | -profile NAME] KEY VALUE
+  eanbaker config init [-config FILE] [-force]
+
+Description:
+  Manages the configuration file used by the GUI (.EANBaker.json in the current directory),
+  or the profile given by -profile.
+
+  show  Prints the configuration, default values if the file does not exist. With -resolved prints every
+        effective value of the generate command with its source: default, user or project file, profile,
+        environment variable or flag. Generator flags (e.g. -text-header) are accepted with -resolved.
+  set   Sets a value by its JSON key, keys of nested objects are separated by dots,
+        e.g. "eanbaker config set serial.start 1000" or "eanbaker config set text_header Name".
+  init  Writes the default configuration, existing file is kept unless -force is set.
+
+Flags:
+`
+
+// Shows, sets or initializes the configuration file.
+func configCommand(args []string, logger *core.MultiLogger) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, CONFIG_USAGE)
+		return &exitError{code: ExitUsage, err: errors.New("Missing config command: show, set or init")}
+	}
+	flags := newFlagSet("config "+args[0], CONFIG_USAGE)
+	path := flags.String("config", configPath, "Path to the configuration file.")
+	profile := new(string)
+	// Profile must exist, the configuration file may not.
+	load := func() (*core.Generator, error) {
+		if *profile == "" {
+			return loadConfig(*path)
+		}
+		store, err := profileStore()
+		if err != nil {
+			return nil, err
+		}
+		*path = store.Path(*profile)
+		generator, err := store.Load(*profile)
+		return generator, invalid(err)
+	}
+	switch args[0] {
+	case "show":
+		resolved := flags.Bool("resolved", false, "Print every effective value with its source instead of the configuration file.")
+		generatorFlags := newGeneratorFlags(flags)
+		profile = &generatorFlags.profile
+		if err := parseFlags(flags, args[1:], false); err != nil {
+			return err
+		}
+		if *resolved {
+			generatorFlags.projectPath = *path
+			return showResolved(generatorFlags, logger)
+		}
+		given := ""
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name != "config" && f.Name != "profile" {
+				given = f.Name
+			}
+		})
+		if given != "" {
+			flags.Usage()
+			return &exitError{code: ExitUsage, err: fmt.Errorf("Flag -%s can be used only with -resolved", given)}
+		}
+		generator, err := load()
+		if err != nil {
+			return err
+		}
+		content, err := generator.MarshalConfig()
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(content)
+		return err
+	case "set":
+		profile = flags.String("profile", "", `Name of the profile changed instead of the configuration file, see "eanbaker profile".`)
+		if err := parseFlags(flags, args[1:], true); err != nil {
+			return err
+		}
+		if flags.NArg() != 2 {
+			flags.Usage()
+			return &exitError{code: ExitUsage, err: errors.New("Config set needs KEY and VALUE")}
+		}
+		generator, err := load()
+		if err != nil {
+			return err
+		}
+		if err := generator.SetValue(flags.Arg(0), flags.Arg(1)); err != nil {
+			return invalid(err)
+		}
+		// Paths are often chosen later in the GUI, so they are not required.
+		check := *generator
+		if check.CsvPath == "" {
+			check.CsvPath = core.DefaultGenerator().CsvPath
+		}
+		check.UpdatePdfPath()
+		if err := check.Validate(); err != nil {
+			return invalid(err)
+		}
+		return generator.Save(*path)
+	case "init":
+		force := flags.Bool("force", false, "Overwrite existing configuration file.")
+		if err := parseFlags(flags, args[1:], false); err != nil {
+			return err
+		}
+		if _, err := os.Stat(*path); err == nil && !*force {
+			return invalid(fmt.Errorf("Configuration '%s' already exists, use -force to overwrite it", *path))
+		}
+		generator := core.DefaultGenerator()
+		return generator.Save(*path)
+	case "-h", "-help", "--help":
+		flags.Usage()
+		return nil
+	}
+	fmt.Fprint(os.Stderr, CONFIG_USAGE)
+	return &exitError{code: ExitUsage, err: fmt.Errorf("Unknown config command %q, expected show, set or init", args[0])}
+}
+
+// Prints every value of the configuration resolved from all sources
+// with its source.
+func showResolved(generatorFlags *generatorFlags, logger *core.MultiLogger) error {
+	resolved, err := generatorFlags.Resolve(logger.Logger)
+	if err != nil {
+		return err
+	}
+	values, err := resolved.Values()
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tVALUE\tSOURCE")
+	for _, value := range values {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", value.Key, value.Value, value.Source)
+	}
+	return writer.Flush()
+}
+
+// Loads the configuration file, default configuration is returned
+// if the file does not exist.
+func loadConfig(path string) (*core.Generator, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		generator := core.DefaultGenerator()
+		return &generator, nil
+	}
+	generator, err := core.LoadGenerator(path, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		return nil, invalid(err)
+	}
+	return generator, nil
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Patterns of EAN digits with odd (L) and even (G) parity.
+var (
+	eanLPatterns = []string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	eanGPatterns = []string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+)
+
+// Parities of EAN-5 digits given by its check value.
+var ean5Parities = []string{"GGLLL", "GLGLL", "GLLGL", "GLLLG", "LGGLL", "LLGGL", "LLLGG", "LGLGL", "LGLLG", "LLGLG"}
+
+// Modules between the main symbol and its add-on.
+const addOnGap = 9
+
+// Light margin right of the add-on in modules.
+const addOnQuietZone = 5
+
+// Reports whether the add-on has two or five digits.
+func validAddOn(addOn string) bool {
+	return (len(addOn) == 2 || len(addOn) == 5) && isDigits(addOn)
+}
+
+// Reports whether the string is not empty and contains only ASCII digits.
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Splits a code written with add-on suffix, e.g. "9771234567003 05",
+// to the main code and the add-on. Code without suffix is returned as is.
+func splitAddOn(code string) (string, string) {
+	parts := strings.Fields(code)
+	if len(parts) == 2 && validAddOn(parts[1]) {
+		return parts[0], parts[1]
+	}
+	return code, ""
+}
+
+// Encodes the EAN-2 or EAN-5 add-on to modules, true for dark modules.
+func encodeAddOn(addOn string) ([]bool, error) {
+	if !validAddOn(addOn) {
+		return nil, fmt.Errorf("Add-on must have 2 or 5 digits, got %q", addOn)
+	}
+	var parities string
+	if len(addOn) == 2 {
+		value := int(addOn[0]-'0')*10 + int(addOn[1]-'0')
+		parities = []string{"LL", "LG", "GL", "GG"}[value%4]
+	} else {
+		check := 0
+		for i, c := range addOn {
+			weight := 3
+			if i%2 == 1 {
+				weight = 9
+			}
+			check += weight * int(c-'0')
+		}
+		parities = ean5Parities[check%10]
+	}
+	pattern := "1011"
+	for i, c := range addOn {
+		if i > 0 {
+			pattern += "01"
+		}
+		if parities[i] == 'L' {
+			pattern += eanLPatterns[c-'0']
+		} else {
+			pattern += eanGPatterns[c-'0']
+		}
+	}
+	modules := make([]bool, len(pattern))
+	for i, c := range pattern {
+		modules[i] = c == '1'
+	}
+	return modules, nil
+}
+
+// Appends the add-on right of the symbol. Add-on bars start below its digits
+// printed above them and end with the guard bars of the main symbol.
+func (s *eanSymbol) appendAddOn(addOn string) error {
+	modules, err := encodeAddOn(addOn)
+	if err != nil {
+		return err
+	}
+	start := len(s.modules) + addOnGap
+	s.modules = append(s.modules, make([]bool, addOnGap)...)
+	s.modules = append(s.modules, modules...)
+	s.extended = append(s.extended, make([]bool, addOnGap+len(modules))...)
+	s.addOn = make([]bool, len(s.modules))
+	for i := start; i < len(s.modules); i++ {
+		s.addOn[i] = true
+	}
+	s.quietRight = addOnQuietZone
+	for i, digit := range addOn {
+		// Start pattern has four modules, digits are separated by two.
+		s.digits = append(s.digits, eanDigit{digit: string(digit), position: start + 4 + 9*i, width: 7, align: "C", above: true})
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestEncodeAddOn(t *testing.T) {
+	tests := []struct {
+		addOn   string
+		want    string
+		wantErr bool
+	}{
+		// 05 % 4 = 1, parities LG.
+		{"05", "1011" + "0001101" + "01" + "0111001", false},
+		// Check value of 52495 is 1, parities GLGLL.
+		{"52495", "1011" + "0111001" + "01" + "0010011" + "01" + "0011101" + "01" + "0001011" + "01" + "0110001", false},
+		{"123", "", true},
+		{"1A", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addOn, func(t *testing.T) {
+			modules, err := encodeAddOn(tt.addOn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("encodeAddOn() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got strings.Builder
+			for _, dark := range modules {
+				if dark {
+					got.WriteString("1")
+				} else {
+					got.WriteString("0")
+				}
+			}
+			if got.String() != tt.want {
+				t.Errorf("encodeAddOn() = %s, want %s", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitAddOn(t *testing.T) {
+	tests := []struct {
+		code      string
+		wantCode  string
+		wantAddOn string
+	}{
+		{"9771234567003 05", "9771234567003", "05"},
+		{"9780306406157  52495", "9780306406157", "52495"},
+		{"9771234567003", "9771234567003", ""},
+		{"9771234567003 123", "9771234567003 123", ""},
+		{"978 80 7200 010 3", "978 80 7200 010 3", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			code, addOn := splitAddOn(tt.code)
+			if code != tt.wantCode || addOn != tt.wantAddOn {
+				t.Errorf("splitAddOn() = %q, %q, want %q, %q", code, addOn, tt.wantCode, tt.wantAddOn)
+			}
+		})
+	}
+}
+
+func TestPdf_AddOn(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pdf, err := NewPdfWithLayout(NewLayout(60, 30))
+	if err != nil {
+		t.Fatalf("NewPdfWithLayout() failed: %v", err)
+	}
+	pdf.pdf.SetCompression(false)
+	records := []Record{
+		{Ean: "9771234567003", AddOn: "05", Times: 1, Row: 2},
+		{Ean: "SKU-1", AddOn: "05", Symbology: SymbologyCode128, Times: 1, Row: 3},
+	}
+	skipped, err := pdf.AddPagesWithPolicy(records, 1, PolicySkipInvalid, log)
+	if err != nil {
+		t.Fatalf("AddPagesWithPolicy() failed: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0].Record.Row != 3 {
+		t.Errorf("skipped = %+v, want Code 128 with add-on", skipped)
+	}
+	var buf bytes.Buffer
+	if err := pdf.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() failed: %v", err)
+	}
+	// EAN-13 has 30 bars and EAN-2 seven, digits of both are printed.
+	if n := bytes.Count(buf.Bytes(), []byte(" re f\n")); n != 37 {
+		t.Errorf("PDF contains %d filled rectangles, want 37", n)
+	}
+	if n := bytes.Count(buf.Bytes(), []byte(")Tj")); n != 15 {
+		t.Errorf("PDF contains %d texts, want 15 digits", n)
+	}
+}
@@ -0,0 +1,354 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Allowed lengths of GS1 company prefixes for EAN-13 allocation.
+const (
+	MinCompanyPrefixLength = 6
+	MaxCompanyPrefixLength = 11
+)
+
+// EAN-13 code in the registry.
+type EanAllocation struct {
+	Ean  string `json:"ean"`
+	Text string `json:"text,omitempty"`
+	// Date of allocation (YYYY-MM-DD), empty for codes found in a table.
+	Date string `json:"date,omitempty"`
+	// Source file, sheet and one based row the code was allocated for.
+	Source string `json:"source,omitempty"`
+	Sheet  string `json:"sheet,omitempty"`
+	Row    int    `json:"row,omitempty"`
+}
+
+// Local registry of EAN-13 codes used with a GS1 company prefix.
+// Codes are allocated in ascending order after the highest item reference
+// in the registry, so numbers are never reused, even if a product is
+// removed from the registry.
+type EanRegistry struct {
+	CompanyPrefix string          `json:"company_prefix"`
+	Codes         []EanAllocation `json:"codes"`
+}
+
+// Validates GS1 company prefix used for EAN-13 allocation.
+func ValidateCompanyPrefix(prefix string) error {
+	if !isDigits(prefix) {
+		return fmt.Errorf("Company prefix must contain only digits, got %q", prefix)
+	}
+	if len(prefix) < MinCompanyPrefixLength || len(prefix) > MaxCompanyPrefixLength {
+		return fmt.Errorf(
+			"Company prefix must have %d to %d digits, got %q",
+			MinCompanyPrefixLength, MaxCompanyPrefixLength, prefix)
+	}
+	return nil
+}
+
+// Creates an empty registry of the company prefix.
+func NewEanRegistry(prefix string) (*EanRegistry, error) {
+	prefix = strings.TrimSpace(prefix)
+	if err := ValidateCompanyPrefix(prefix); err != nil {
+		return nil, err
+	}
+	return &EanRegistry{CompanyPrefix: prefix, Codes: []EanAllocation{}}, nil
+}
+
+// Reads the registry from a JSON file. New registry of the prefix is
+// returned if the file does not exist. Prefix can be empty for existing
+// registry, otherwise it must match the one of the registry.
+func LoadEanRegistry(path string, prefix string) (*EanRegistry, error) {
+	prefix = strings.TrimSpace(prefix)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if prefix == "" {
+			return nil, fmt.Errorf("Registry '%s' does not exist, company prefix must be set to create it", path)
+		}
+		return NewEanRegistry(prefix)
+	}
+	if err != nil {
+		return nil, errors.Join(errors.New("Cannot load EAN registry"), err)
+	}
+	var registry EanRegistry
+	if err := json.Unmarshal(content, &registry); err != nil {
+		return nil, errors.Join(errors.New("Cannot decode EAN registry"), err)
+	}
+	if err := ValidateCompanyPrefix(registry.CompanyPrefix); err != nil {
+		return nil, err
+	}
+	if prefix != "" && prefix != registry.CompanyPrefix {
+		return nil, fmt.Errorf("Registry '%s' belongs to company prefix %s, not %s", path, registry.CompanyPrefix, prefix)
+	}
+	return &registry, nil
+}
+
+// Save writes the registry to a JSON file. The file is replaced
+// only after the new content is written completely.
+func (r *EanRegistry) Save(path string) error {
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, append(content, '\n'))
+}
+
+// Reports whether the code is in the registry.
+func (r *EanRegistry) Contains(ean string) bool {
+	return slices.ContainsFunc(r.Codes, func(a EanAllocation) bool { return a.Ean == ean })
+}
+
+// Returns the item reference of the code, or -1 if the code
+// is not an EAN-13 of the registry company prefix.
+func (r *EanRegistry) itemReference(ean string) int64 {
+	if len(ean) != 13 || !isDigits(ean) || !strings.HasPrefix(ean, r.CompanyPrefix) {
+		return -1
+	}
+	reference, _ := strconv.ParseInt(ean[len(r.CompanyPrefix):12], 10, 64)
+	return reference
+}
+
+// Adds the code found outside of the registry, so it is never allocated.
+// Codes of other company prefixes and codes already in the registry are
+// ignored. Returns an error for codes of the prefix with wrong check digit.
+func (r *EanRegistry) Register(ean string, text string) error {
+	if r.itemReference(ean) == -1 || r.Contains(ean) {
+		return nil
+	}
+	if _, status := ValidateCode(SymbologyEAN, ean, false); status != RowValid {
+		return fmt.Errorf("Code %s of company prefix %s has %s", ean, r.CompanyPrefix, status)
+	}
+	r.Codes = append(r.Codes, EanAllocation{Ean: ean, Text: text})
+	return nil
+}
+
+// Allocates the EAN-13 following the highest item reference of the registry.
+// Returns an error if all item references of the prefix are used.
+func (r *EanRegistry) Allocate(allocation EanAllocation) (EanAllocation, error) {
+	next := int64(0)
+	for _, code := range r.Codes {
+		if reference := r.itemReference(code.Ean); reference >= next {
+			next = reference + 1
+		}
+	}
+	digits := 12 - len(r.CompanyPrefix)
+	if next >= int64(math.Pow10(digits)) {
+		return EanAllocation{}, fmt.Errorf("All %d item references of company prefix %s are used", int64(math.Pow10(digits)), r.CompanyPrefix)
+	}
+	code := fmt.Sprintf("%s%0*d", r.CompanyPrefix, digits, next)
+	check, _ := GS1CheckDigit(code)
+	allocation.Ean = code + string(check)
+	if allocation.Date == "" {
+		allocation.Date = time.Now().Format(time.DateOnly)
+	}
+	r.Codes = append(r.Codes, allocation)
+	return allocation, nil
+}
+
+// Returns the header row and indexes of the text and EAN columns.
+func allocationColumns(table Table, columns Columns) (int, int, int, error) {
+	if strings.TrimSpace(columns.Ean) == "" {
+		return 0, 0, 0, errors.New("Ean column header cannot be empty")
+	}
+	if len(table) == 0 {
+		return 0, 0, 0, errors.New("Table with data cannot be empty")
+	}
+	headers := []string{columns.Text, columns.Ean}
+	match, ok := findHeaderRow(table, headers, columns.HeaderSearchRows)
+	if !ok {
+		for i, name := range []string{"text", "ean"} {
+			if match.indexes[i] == -1 && strings.TrimSpace(headers[i]) != "" {
+				return 0, 0, 0, fmt.Errorf(
+					"Cannot find %s header '%s', available headers: %s",
+					name, headers[i], availableHeaders(table[match.row]))
+			}
+		}
+	}
+	return match.row, match.indexes[0], match.indexes[1], nil
+}
+
+// Registers codes of the registry prefix found in the table, so they are
+// not allocated. Must be called for all tables before allocation.
+func registerTable(table Table, columns Columns, registry *EanRegistry) error {
+	header, text_index, ean_index, err := allocationColumns(table, columns)
+	if err != nil {
+		return err
+	}
+	for i := header + 1; i < len(table); i++ {
+		ean := strings.TrimSpace(cell(table[i], ean_index))
+		if err := registry.Register(ean, strings.TrimSpace(cell(table[i], text_index))); err != nil {
+			return fmt.Errorf("Row %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Assigns EAN-13 codes from the registry to table rows with empty code.
+// Rows without any value are left empty. Allocations get source and sheet
+// of the origin. Cells are set in the table and by the set function if it
+// is not nil. Returns the allocated codes.
+func allocateTable(table Table, columns Columns, registry *EanRegistry, origin EanAllocation, set func(row int, column int, value string) error) ([]EanAllocation, error) {
+	header, text_index, ean_index, err := allocationColumns(table, columns)
+	if err != nil {
+		return nil, err
+	}
+	allocated := []EanAllocation{}
+	for i := header + 1; i < len(table); i++ {
+		if strings.TrimSpace(strings.Join(table[i], "")) == "" || strings.TrimSpace(cell(table[i], ean_index)) != "" {
+			continue
+		}
+		origin.Text, origin.Row = strings.TrimSpace(cell(table[i], text_index)), i+1
+		allocation, err := registry.Allocate(origin)
+		if err != nil {
+			return nil, err
+		}
+		for len(table[i]) <= ean_index {
+			table[i] = append(table[i], "")
+		}
+		table[i][ean_index] = allocation.Ean
+		if set != nil {
+			if err := set(i, ean_index, allocation.Ean); err != nil {
+				return nil, err
+			}
+		}
+		allocated = append(allocated, allocation)
+	}
+	return allocated, nil
+}
+
+// Assigns EAN-13 codes from the registry to table rows with empty code
+// and writes them to the table. Rows without any value are left empty.
+// Codes of the registry prefix found in the table are registered, so
+// they are not allocated again. Returns the allocated codes.
+func AllocateTable(table Table, columns Columns, registry *EanRegistry) ([]EanAllocation, error) {
+	if err := registerTable(table, columns, registry); err != nil {
+		return nil, err
+	}
+	return allocateTable(table, columns, registry, EanAllocation{}, nil)
+}
+
+// Reads the input, assigns EAN-13 codes from the registry to rows without
+// code and writes the enriched input in the same format to w. CSV keeps
+// its separator, quote character and encoding, workbook keeps formatting,
+// only the new codes are written to it. The registry is not saved, it must
+// be saved before the output replaces the input, so codes are never reused.
+func (g *Generator) AllocateEans(filename string, content io.ReadSeeker, registry *EanRegistry, w io.Writer) ([]EanAllocation, error) {
+	format, err := g.inputFormat(filename, content)
+	if err != nil {
+		return nil, err
+	}
+	columns := Columns{Text: g.TextHeader, Ean: g.EanHeader, HeaderSearchRows: g.HeaderSearchRows}
+	source := filepath.Base(filename)
+	switch format {
+	case FormatCsv, FormatTsv:
+		comma := rune(g.CsvComma)
+		if format == FormatTsv {
+			comma = '\t'
+		}
+		encoding, _ := TextEncodingFromString(string(g.CsvEncoding))
+		table, dialect, err := TableFromCsvDialect(content, CsvDialect{Comma: comma, Encoding: encoding})
+		if err != nil {
+			return nil, err
+		}
+		if err := registerTable(table, columns, registry); err != nil {
+			return nil, err
+		}
+		allocated, err := allocateTable(table, columns, registry, EanAllocation{Source: source}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return allocated, WriteCsvDialect(w, table, dialect)
+	case FormatXlsx:
+		return g.allocateExcel(content, source, columns, registry, w)
+	default:
+		return nil, fmt.Errorf("Cannot write %s input back, use CSV or XLSX", format)
+	}
+}
+
+// Assigns EAN-13 codes in the selected or all sheets of the workbook
+// and writes it to w.
+func (g *Generator) allocateExcel(content io.Reader, source string, columns Columns, registry *EanRegistry, w io.Writer) ([]EanAllocation, error) {
+	exel, err := excelize.OpenReader(content)
+	if err != nil {
+		return nil, err
+	}
+	defer exel.Close()
+	sheets, err := selectSheets(exel.GetSheetList(), g.Sheet, g.MergeSheets)
+	if err != nil {
+		return nil, err
+	}
+	// Codes of all sheets are registered first, so no code found
+	// in a later sheet is allocated in an earlier one.
+	tables := map[string]Table{}
+	for _, sheet := range sheets {
+		table, err := exel.GetRows(sheet)
+		if err != nil {
+			return nil, err
+		}
+		if len(sheets) > 1 && len(table) == 0 {
+			continue
+		}
+		if err := registerTable(table, columns, registry); err != nil {
+			return nil, sheetError(sheet, len(sheets), err)
+		}
+		tables[sheet] = table
+	}
+	allocated := []EanAllocation{}
+	for _, sheet := range sheets {
+		table, ok := tables[sheet]
+		if !ok {
+			continue
+		}
+		origin := EanAllocation{Source: source, Sheet: sheet}
+		sheetAllocated, err := allocateTable(table, columns, registry, origin, func(row int, column int, value string) error {
+			name, err := excelize.CoordinatesToCellName(column+1, row+1)
+			if err != nil {
+				return err
+			}
+			// Codes are written as text, so leading zeros are kept.
+			return exel.SetCellStr(sheet, name, value)
+		})
+		if err != nil {
+			return nil, sheetError(sheet, len(sheets), err)
+		}
+		allocated = append(allocated, sheetAllocated...)
+	}
+	return allocated, exel.Write(w)
+}
+
+// Adds the sheet name to the error when more sheets are processed.
+func sheetError(sheet string, sheets int, err error) error {
+	if sheets > 1 {
+		return fmt.Errorf("Sheet '%s': %w", sheet, err)
+	}
+	return err
+}
+
+// Writes the file by writing a temporary file in the same directory
+// and renaming it, so the file is never left written partially.
+func WriteFileAtomic(path string, content []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := io.Copy(file, bytes.NewReader(content)); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,212 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestValidateCompanyPrefix(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		wantErr bool
+	}{
+		{prefix: "859123"},
+		{prefix: "85912345678"},
+		{prefix: "85912", wantErr: true},
+		{prefix: "859123456789", wantErr: true},
+		{prefix: "859a23", wantErr: true},
+		{prefix: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if err := ValidateCompanyPrefix(tt.prefix); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCompanyPrefix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEanRegistry_Allocate(t *testing.T) {
+	registry, err := NewEanRegistry("8591234567")
+	if err != nil {
+		t.Fatalf("NewEanRegistry() failed: %v", err)
+	}
+	want := []string{"8591234567008", "8591234567015"}
+	for _, code := range want {
+		got, err := registry.Allocate(EanAllocation{Text: "Milk"})
+		if err != nil {
+			t.Fatalf("Allocate() failed: %v", err)
+		}
+		if got.Ean != code {
+			t.Errorf("Allocate() = %s, want %s", got.Ean, code)
+		}
+		if got.Date == "" || got.Text != "Milk" {
+			t.Errorf("Allocate() = %+v, want date and text set", got)
+		}
+	}
+
+	// Codes found in tables are never allocated, gaps are not filled.
+	if err := registry.Register("8591234567503", "Bread"); err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+	if err := registry.Register("4006381333931", "Foreign"); err != nil {
+		t.Fatalf("Register() of foreign code failed: %v", err)
+	}
+	if err := registry.Register("8591234567508", "Wrong check digit"); err == nil {
+		t.Error("Register() should fail for wrong check digit")
+	}
+	got, err := registry.Allocate(EanAllocation{})
+	if err != nil {
+		t.Fatalf("Allocate() failed: %v", err)
+	}
+	if got.Ean != "8591234567510" {
+		t.Errorf("Allocate() = %s, want 8591234567510", got.Ean)
+	}
+	if len(registry.Codes) != 4 {
+		t.Errorf("Registry has %d codes, want 4", len(registry.Codes))
+	}
+}
+
+func TestEanRegistry_AllocateExhausted(t *testing.T) {
+	registry, _ := NewEanRegistry("85912345678")
+	registry.Codes = append(registry.Codes, EanAllocation{Ean: "8591234567893"})
+	if _, err := registry.Allocate(EanAllocation{}); err == nil {
+		t.Error("Allocate() should fail when all item references are used")
+	}
+}
+
+func TestLoadEanRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.json")
+	if _, err := LoadEanRegistry(path, ""); err == nil {
+		t.Error("LoadEanRegistry() should fail for missing registry without prefix")
+	}
+	registry, err := LoadEanRegistry(path, "859123")
+	if err != nil {
+		t.Fatalf("LoadEanRegistry() failed: %v", err)
+	}
+	allocated, err := registry.Allocate(EanAllocation{Text: "Milk", Row: 2})
+	if err != nil {
+		t.Fatalf("Allocate() failed: %v", err)
+	}
+	if err := registry.Save(path); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	loaded, err := LoadEanRegistry(path, "")
+	if err != nil {
+		t.Fatalf("LoadEanRegistry() failed: %v", err)
+	}
+	if loaded.CompanyPrefix != "859123" || len(loaded.Codes) != 1 || loaded.Codes[0] != allocated {
+		t.Errorf("LoadEanRegistry() = %+v, want saved registry", loaded)
+	}
+	if _, err := LoadEanRegistry(path, "859999"); err == nil {
+		t.Error("LoadEanRegistry() should fail for different prefix")
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Directory contains %d files, want only the registry", len(entries))
+	}
+}
+
+func TestAllocateTable(t *testing.T) {
+	registry, _ := NewEanRegistry("859123")
+	table := Table{
+		{"Price list"},
+		{"Name", "EAN"},
+		{"Milk", ""},
+		{"Bread", "8591230000011"},
+		{"", ""},
+		{"Butter"},
+		{"Cheese", "4006381333931"},
+	}
+	allocated, err := AllocateTable(table, Columns{Text: "Name", Ean: "EAN"}, registry)
+	if err != nil {
+		t.Fatalf("AllocateTable() failed: %v", err)
+	}
+	want := Table{
+		{"Price list"},
+		{"Name", "EAN"},
+		{"Milk", "8591230000028"},
+		{"Bread", "8591230000011"},
+		{"", ""},
+		{"Butter", "8591230000035"},
+		{"Cheese", "4006381333931"},
+	}
+	for i := range want {
+		if strings.Join(table[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("Row %d = %v, want %v", i+1, table[i], want[i])
+		}
+	}
+	if len(allocated) != 2 || allocated[0].Row != 3 || allocated[1].Text != "Butter" {
+		t.Errorf("AllocateTable() = %+v, want rows 3 and 6", allocated)
+	}
+
+	if _, err := AllocateTable(table, Columns{Text: "Name", Ean: "Code"}, registry); err == nil {
+		t.Error("AllocateTable() should fail for missing header")
+	}
+}
+
+func TestGenerator_AllocateEans_Csv(t *testing.T) {
+	registry, _ := NewEanRegistry("859123")
+	generator := Generator{TextHeader: "Name", EanHeader: "EAN"}
+	input := "Name;EAN\r\n\"Milk; 1l\";\r\nBread;8591230000011\r\n"
+	var buf bytes.Buffer
+	allocated, err := generator.AllocateEans("items.csv", strings.NewReader(input), registry, &buf)
+	if err != nil {
+		t.Fatalf("AllocateEans() failed: %v", err)
+	}
+	want := "Name;EAN\r\n\"Milk; 1l\";8591230000028\r\nBread;8591230000011\r\n"
+	if buf.String() != want {
+		t.Errorf("AllocateEans() wrote %q, want %q", buf.String(), want)
+	}
+	if len(allocated) != 1 || allocated[0].Source != "items.csv" {
+		t.Errorf("AllocateEans() = %+v, want one allocation from items.csv", allocated)
+	}
+}
+
+func TestGenerator_AllocateEans_Excel(t *testing.T) {
+	registry, _ := NewEanRegistry("859123")
+	workbook := excelWorkbook(t, []string{"Food", "Drinks"}, map[string]Table{
+		"Food":   {{"Name", "EAN"}, {"Bread", ""}},
+		"Drinks": {{"Name", "EAN"}, {"Milk", ""}, {"Water", "8591230000011"}},
+	})
+	generator := Generator{TextHeader: "Name", EanHeader: "EAN", MergeSheets: true}
+	var buf bytes.Buffer
+	allocated, err := generator.AllocateEans("items.xlsx", workbook, registry, &buf)
+	if err != nil {
+		t.Fatalf("AllocateEans() failed: %v", err)
+	}
+	if len(allocated) != 2 || allocated[0].Sheet != "Food" || allocated[1].Sheet != "Drinks" {
+		t.Fatalf("AllocateEans() = %+v, want one allocation in each sheet", allocated)
+	}
+
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("OpenReader() failed: %v", err)
+	}
+	defer file.Close()
+	for _, tt := range []struct{ sheet, cell, want string }{
+		{"Food", "B2", "8591230000028"},
+		{"Drinks", "B2", "8591230000035"},
+		{"Drinks", "B3", "8591230000011"},
+	} {
+		got, _ := file.GetCellValue(tt.sheet, tt.cell)
+		if got != tt.want {
+			t.Errorf("%s!%s = %q, want %q", tt.sheet, tt.cell, got, tt.want)
+		}
+	}
+}
+
+func TestGenerator_AllocateEans_Ods(t *testing.T) {
+	registry, _ := NewEanRegistry("859123")
+	generator := Generator{TextHeader: "Name", EanHeader: "EAN"}
+	var buf bytes.Buffer
+	if _, err := generator.AllocateEans("items.ods", strings.NewReader(""), registry, &buf); err == nil {
+		t.Error("AllocateEans() should fail for ODS input")
+	}
+}
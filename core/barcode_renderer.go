@@ -0,0 +1,132 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/boombuler/barcode"
+)
+
+// How barcodes are drawn to the PDF.
+type BarcodeRenderer string
+
+const (
+	// Bars are drawn as PDF rectangles, sharp at any printer resolution.
+	RendererVector BarcodeRenderer = "vector"
+	// Barcode is drawn as a scaled PNG image.
+	RendererPng BarcodeRenderer = "png"
+)
+
+// List of all barcode renderers.
+var BarcodeRenderers = []BarcodeRenderer{
+	RendererVector,
+	RendererPng,
+}
+
+// Converts a string to a BarcodeRenderer, matching is case insensitive.
+// Empty string is converted to the default vector renderer.
+func BarcodeRendererFromString(s string) (BarcodeRenderer, error) {
+	normalized := strings.ToLower(strings.TrimSpace(s))
+	if normalized == "" {
+		return RendererVector, nil
+	}
+	for _, renderer := range BarcodeRenderers {
+		if string(renderer) == normalized {
+			return renderer, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown barcode renderer %q, expected vector or png", s)
+}
+
+// Implements the json.Unmarshaler interface for BarcodeRenderer.
+// Accepts the same values as BarcodeRendererFromString.
+func (r *BarcodeRenderer) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	renderer, err := BarcodeRendererFromString(s)
+	if err != nil {
+		return err
+	}
+	*r = renderer
+	return nil
+}
+
+// Returns modules of the encoded barcode by rows, true for dark modules.
+// One dimensional codes have a single row.
+func barcodeModules(code barcode.Barcode) [][]bool {
+	bounds := code.Bounds()
+	rows := make([][]bool, 0, bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := make([]bool, 0, bounds.Dx())
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := code.At(x, y).RGBA()
+			row = append(row, r+g+b < 3*0x8000)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Returns width and height of a single module of the barcode drawn to the box.
+// Modules fill the box when moduleWidth is zero, two dimensional codes keep
+// square modules. Returns an error if the barcode does not fit the box.
+func moduleSize(modules [][]bool, box Box, moduleWidth float64, is2D bool) (float64, float64, error) {
+	if len(modules) == 0 || len(modules[0]) == 0 {
+		return 0, 0, fmt.Errorf("Barcode has no modules")
+	}
+	columns, rows := float64(len(modules[0])), float64(len(modules))
+	width := moduleWidth
+	if width == 0 {
+		width = box.Width / columns
+		if is2D {
+			width = min(width, box.Height/rows)
+		}
+	}
+	// Small tolerance for float rounding of scaled layouts.
+	const eps = 1e-9
+	if width*columns > box.Width+eps {
+		return 0, 0, fmt.Errorf(
+			"Barcode of %d modules %g mm wide does not fit the barcode box %g mm wide",
+			len(modules[0]), width, box.Width)
+	}
+	if !is2D {
+		return width, box.Height, nil
+	}
+	if width*rows > box.Height+eps {
+		return 0, 0, fmt.Errorf(
+			"Barcode of %d module rows %g mm high does not fit the barcode box %g mm high",
+			len(modules), width, box.Height)
+	}
+	return width, width, nil
+}
+
+// Draws the barcode as filled rectangles centered in the box. Adjacent
+// dark modules of a row are drawn as one rectangle, so no hairline gaps
+// appear between them.
+func (p *Pdf) drawVectorBarcode(code barcode.Barcode, box Box, is2D bool) error {
+	modules := barcodeModules(code)
+	width, height, err := moduleSize(modules, box, p.layout.ModuleWidth, is2D)
+	if err != nil {
+		return err
+	}
+	x0 := box.X + (box.Width-width*float64(len(modules[0])))/2
+	y0 := box.Y + (box.Height-height*float64(len(modules)))/2
+	p.pdf.SetFillColor(0, 0, 0)
+	for y, row := range modules {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			p.pdf.Rect(x0+float64(start)*width, y0+float64(y)*height, float64(x-start)*width, height, "F")
+		}
+	}
+	return nil
+}
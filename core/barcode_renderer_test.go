@@ -0,0 +1,138 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestBarcodeRendererFromString(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    BarcodeRenderer
+		wantErr bool
+	}{
+		{"", RendererVector, false},
+		{"vector", RendererVector, false},
+		{" PNG ", RendererPng, false},
+		{"svg", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := BarcodeRendererFromString(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BarcodeRendererFromString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("BarcodeRendererFromString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBarcodeModules(t *testing.T) {
+	code, err := SymbologyEAN.Encode("4006381333931")
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	modules := barcodeModules(code)
+	if len(modules) != 1 || len(modules[0]) != 95 {
+		t.Fatalf("barcodeModules() returned %dx%d modules, want 1x95", len(modules), len(modules[0]))
+	}
+	// Start guard is bar, space, bar.
+	if !modules[0][0] || modules[0][1] || !modules[0][2] {
+		t.Errorf("barcodeModules() start guard = %v, want [true false true]", modules[0][:3])
+	}
+}
+
+func TestModuleSize(t *testing.T) {
+	row := make([]bool, 95)
+	square := [][]bool{make([]bool, 10), make([]bool, 10), make([]bool, 10), make([]bool, 10), make([]bool, 10)}
+	tests := []struct {
+		name        string
+		modules     [][]bool
+		box         Box
+		moduleWidth float64
+		is2D        bool
+		wantWidth   float64
+		wantHeight  float64
+		wantErr     bool
+	}{
+		{"Fill box", [][]bool{row}, Box{Width: 38, Height: 10}, 0, false, 0.4, 10, false},
+		{"Fixed width", [][]bool{row}, Box{Width: 38, Height: 10}, 0.33, false, 0.33, 10, false},
+		{"Too wide", [][]bool{row}, Box{Width: 27, Height: 10}, 0.33, false, 0, 0, true},
+		{"2D square modules", square, Box{Width: 10, Height: 10}, 0, true, 1, 1, false},
+		{"2D too high", square, Box{Width: 20, Height: 2}, 0.5, true, 0, 0, true},
+		{"No modules", nil, Box{Width: 10, Height: 10}, 0, false, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height, err := moduleSize(tt.modules, tt.box, tt.moduleWidth, tt.is2D)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("moduleSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if width != tt.wantWidth || height != tt.wantHeight {
+				t.Errorf("moduleSize() = %g, %g, want %g, %g", width, height, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestPdf_VectorBarcode(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	records := []Record{{Text: "Bread", Ean: "4006381333931", Times: 1}}
+
+	layout := NewLayout(50, 30)
+	layout.ModuleWidth = 0.33
+	pdf, err := NewPdfWithLayout(layout)
+	if err != nil {
+		t.Fatalf("NewPdfWithLayout() failed: %v", err)
+	}
+	pdf.pdf.SetCompression(false)
+	if err := pdf.AddPages(records, 1, log); err != nil {
+		t.Fatalf("AddPages() failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := pdf.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() failed: %v", err)
+	}
+	// EAN-13 has 30 bars and no image is embedded.
+	if n := bytes.Count(buf.Bytes(), []byte(" re f\n")); n != 30 {
+		t.Errorf("PDF contains %d filled rectangles, want 30", n)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("/Subtype /Image")) {
+		t.Error("PDF contains an image, want vector bars")
+	}
+
+	// Barcode does not fit the 30 mm label at 0.33 mm modules.
+	layout = DefaultLayout()
+	layout.ModuleWidth = 0.33
+	pdf, err = NewPdfWithLayout(layout)
+	if err != nil {
+		t.Fatalf("NewPdfWithLayout() failed: %v", err)
+	}
+	skipped, err := pdf.AddPagesWithPolicy(records, 1, PolicySkipInvalid, log)
+	if err != nil || len(skipped) != 1 {
+		t.Errorf("AddPagesWithPolicy() = %v, %v, want the record skipped", skipped, err)
+	}
+}
+
+func TestPdf_PngBarcode(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pdf := NewPdf()
+	if err := pdf.SetBarcodeRenderer(RendererPng); err != nil {
+		t.Fatalf("SetBarcodeRenderer() failed: %v", err)
+	}
+	pdf.pdf.SetCompression(false)
+	if err := pdf.AddPages([]Record{{Text: "Bread", Ean: "4006381333931", Times: 1}}, 1, log); err != nil {
+		t.Fatalf("AddPages() failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := pdf.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() failed: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/Subtype /Image")) {
+		t.Error("PDF does not contain the barcode image")
+	}
+}
@@ -0,0 +1,266 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Result of one input file of a batch.
+type BatchResult struct {
+	Input string
+	// Path of the generated PDF, empty if the input failed.
+	Output string
+	Err    error
+}
+
+// Returns the input files given by the arguments. Argument is a file,
+// a directory whose files of supported formats are used (not recursively),
+// or a glob pattern. Files are listed once in order of the arguments.
+func ExpandInputs(args []string) ([]string, error) {
+	inputs := []string{}
+	seen := map[string]bool{}
+	add := func(path string) {
+		path = filepath.Clean(path)
+		if !seen[path] {
+			seen[path] = true
+			inputs = append(inputs, path)
+		}
+	}
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		switch {
+		case err == nil && info.IsDir():
+			files, err := directoryInputs(arg)
+			if err != nil {
+				return nil, err
+			}
+			if len(files) == 0 {
+				return nil, fmt.Errorf("Directory '%s' contains no %s file", arg, supportedExtensions)
+			}
+			for _, file := range files {
+				add(file)
+			}
+		case err == nil:
+			add(arg)
+		case strings.ContainsAny(arg, "*?["):
+			matches, globErr := filepath.Glob(arg)
+			if globErr != nil {
+				return nil, fmt.Errorf("Invalid pattern %q: %w", arg, globErr)
+			}
+			count := 0
+			for _, match := range matches {
+				if info, err := os.Stat(match); err == nil && !info.IsDir() && !ignoredInput(match) {
+					add(match)
+					count++
+				}
+			}
+			if count == 0 {
+				return nil, fmt.Errorf("No input file matches %q", arg)
+			}
+		default:
+			return nil, err
+		}
+	}
+	if len(inputs) == 0 {
+		return nil, errors.New("No input files given")
+	}
+	return inputs, nil
+}
+
+// Returns files of the directory with extension of an input format
+// sorted by name.
+func directoryInputs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || ignoredInput(name) {
+			continue
+		}
+		if format, _ := InputFormatFromFilename(name); format != "" {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Reports whether the file is hidden or an Excel lock file, which are
+// left out of directories and glob matches.
+func ignoredInput(path string) bool {
+	name := filepath.Base(path)
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~$")
+}
+
+// Calls run for indexes 0 to n-1, at most jobs of them at once.
+func runJobs(n int, jobs int, run func(i int)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, jobs)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-limit }()
+			run(i)
+		}()
+	}
+	wg.Wait()
+}
+
+// Generates one PDF per input to the output directory, the name of the PDF
+// is given by GeneratePdfPath and the overwrite policy. At most jobs inputs
+// are processed at once.
+// Serial numbers continue from one input to the next, so inputs with serial
+// numbers are processed one by one in their order. Returns the result of
+// every input in order of the inputs.
+func (g *Generator) GenerateBatch(inputs []string, outputDir string, jobs int, log *slog.Logger) ([]BatchResult, error) {
+	// Reports have a single path, so they are written only with merged output.
+	if g.ReportPath != "" || g.SkippedReportPath != "" {
+		return nil, errors.New("Reports can be written only when inputs are merged into one PDF")
+	}
+	if outputDir == "" {
+		outputDir = "."
+	}
+
+	policy, err := OverwritePolicyFromString(string(g.OverwritePolicy))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(inputs))
+	outputs := map[string]string{}
+	for i, input := range inputs {
+		output := filepath.Join(outputDir, GeneratePdfPath(input))
+		results[i] = BatchResult{Input: input, Output: output}
+		if other, ok := outputs[output]; ok {
+			results[i].Err = fmt.Errorf("Output '%s' is generated from '%s' too", output, other)
+			continue
+		}
+		outputs[output] = input
+		free, err := FreeOutputPath(output, policy)
+		if other, ok := outputs[free]; ok && free != output {
+			err = fmt.Errorf("Output '%s' is generated from '%s' too", free, other)
+		}
+		results[i].Output, results[i].Err = free, err
+		outputs[free] = input
+	}
+
+	if g.Serial.Enabled {
+		// One job runs the inputs in order.
+		jobs = 1
+	}
+	runJobs(len(inputs), jobs, func(i int) {
+		result := &results[i]
+		if result.Err != nil {
+			return
+		}
+		generator := *g
+		generator.CsvPath = result.Input
+		generator.OutputFile = result.Output
+		result.Err = generator.generateFile(log)
+		if result.Err != nil {
+			log.Error("Failed to generate batch input", "input", result.Input, "err", result.Err)
+			return
+		}
+		if generator.Serial.Enabled {
+			g.Serial = generator.Serial
+		}
+	})
+	for i := range results {
+		if results[i].Err != nil {
+			results[i].Output = ""
+		}
+	}
+	return results, nil
+}
+
+// Generates the PDF of the input file at the generator paths.
+func (g *Generator) generateFile(log *slog.Logger) error {
+	file, err := os.Open(g.CsvPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return g.Generate(g.CsvPath, file, log)
+}
+
+// Generates a single PDF at the generator PDF path from records of all
+// inputs in their order. Inputs are read at most jobs at once. Records
+// remember the input file as their sheet, e.g. "a.xlsx/Prices". Labels of
+// inputs that cannot be read are left out and the inputs are reported
+// as failed. Returns an error if the PDF cannot be generated. Generator
+// must be valid, its input path is not used.
+func (g *Generator) GenerateMerged(inputs []string, jobs int, log *slog.Logger) ([]BatchResult, error) {
+	results := make([]BatchResult, len(inputs))
+	records := make([][]Record, len(inputs))
+	runJobs(len(inputs), jobs, func(i int) {
+		results[i].Input = inputs[i]
+		records[i], results[i].Err = g.fileRecords(inputs[i])
+		if results[i].Err != nil {
+			log.Error("Failed to read batch input", "input", inputs[i], "err", results[i].Err)
+		}
+	})
+
+	merged := []Record{}
+	for i := range results {
+		if results[i].Err == nil {
+			merged = append(merged, records[i]...)
+		}
+	}
+	if len(merged) == 0 {
+		return results, errors.New("No records to generate")
+	}
+	if err := g.generateFromRecords(merged, nil, log); err != nil {
+		return results, err
+	}
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].Output = g.outputFile()
+		}
+	}
+	return results, nil
+}
+
+// Reads records of the input file, their sheet is prefixed by the file name.
+func (g *Generator) fileRecords(path string) ([]Record, error) {
+	generator := *g
+	generator.CsvPath = path
+	if err := generator.Validate(); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	tables, err := generator.ReadTables(path, file)
+	if err != nil {
+		return nil, err
+	}
+	records, err := generator.RecordsFromTables(tables)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(path)
+	for i := range records {
+		if records[i].Sheet == "" {
+			records[i].Sheet = name
+		} else {
+			records[i].Sheet = name + "/" + records[i].Sheet
+		}
+	}
+	return records, nil
+}
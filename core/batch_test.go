@@ -0,0 +1,156 @@
+package core
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Writes the files to a temporary directory and returns the directory.
+func batchDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestExpandInputs(t *testing.T) {
+	dir := batchDir(t, map[string]string{
+		"b.csv":       "",
+		"a.xlsx":      "",
+		"notes.txt":   "",
+		".hidden.csv": "",
+		"~$a.xlsx":    "",
+	})
+	join := func(names ...string) []string {
+		paths := []string{}
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+		return paths
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Directory", args: []string{dir}, want: join("a.xlsx", "b.csv")},
+		{name: "Glob", args: []string{filepath.Join(dir, "*.csv")}, want: join("b.csv")},
+		{name: "File", args: join("notes.txt"), want: join("notes.txt")},
+		{name: "Duplicates", args: append(join("b.csv"), dir), want: join("b.csv", "a.xlsx")},
+		{name: "No match", args: []string{filepath.Join(dir, "*.ods")}, wantErr: true},
+		{name: "Missing file", args: join("missing.csv"), wantErr: true},
+		{name: "Empty", args: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandInputs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandInputs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("ExpandInputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerator_GenerateBatch(t *testing.T) {
+	dir := batchDir(t, map[string]string{
+		"a.csv":   "Text,EAN\nA,5901234123457\n",
+		"b.csv":   "Text,EAN\nB,4006381333931\n",
+		"bad.csv": "Name,Code\nC,5901234123457\n",
+	})
+	out := filepath.Join(dir, "out")
+	inputs := []string{filepath.Join(dir, "a.csv"), filepath.Join(dir, "bad.csv"), filepath.Join(dir, "b.csv")}
+	gen := Generator{TextHeader: "Text", EanHeader: "EAN", TimesEachEAN: 1}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	results, err := gen.GenerateBatch(inputs, out, 2, log)
+	if err != nil {
+		t.Fatalf("GenerateBatch() failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(results))
+	}
+	for i, name := range []string{"a.pdf", "", "b.pdf"} {
+		result := results[i]
+		if result.Input != inputs[i] {
+			t.Errorf("results[%d].Input = %s, want %s", i, result.Input, inputs[i])
+		}
+		if name == "" {
+			if result.Err == nil || result.Output != "" {
+				t.Errorf("results[%d] = %+v, want failure", i, result)
+			}
+			continue
+		}
+		if result.Err != nil || result.Output != filepath.Join(out, name) {
+			t.Errorf("results[%d] = %+v, want %s", i, result, name)
+		}
+		if _, err := os.Stat(result.Output); err != nil {
+			t.Errorf("PDF %s not written: %v", result.Output, err)
+		}
+	}
+
+	gen.ReportPath = filepath.Join(dir, "report.csv")
+	if _, err := gen.GenerateBatch(inputs, out, 2, log); err == nil {
+		t.Error("GenerateBatch() should fail with report path")
+	}
+}
+
+func TestGenerator_GenerateBatch_SameOutput(t *testing.T) {
+	dir := batchDir(t, map[string]string{
+		"a.csv": "Text,EAN\nA,5901234123457\n",
+		"a.tsv": "Text\tEAN\nA\t5901234123457\n",
+	})
+	gen := Generator{TextHeader: "Text", EanHeader: "EAN", TimesEachEAN: 1}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	results, err := gen.GenerateBatch([]string{filepath.Join(dir, "a.csv"), filepath.Join(dir, "a.tsv")}, dir, 2, log)
+	if err != nil {
+		t.Fatalf("GenerateBatch() failed: %v", err)
+	}
+	if results[0].Err != nil || results[1].Err == nil {
+		t.Errorf("GenerateBatch() = %+v, want second input failed", results)
+	}
+}
+
+func TestGenerator_GenerateMerged(t *testing.T) {
+	dir := batchDir(t, map[string]string{
+		"a.csv":   "Text,EAN\nA,5901234123457\n",
+		"b.csv":   "Text,EAN\nB,4006381333931\nC,5901234123457\n",
+		"bad.csv": "Name,Code\nC,5901234123457\n",
+	})
+	gen := Generator{
+		PdfPath:      filepath.Join(dir, "merged.pdf"),
+		ReportPath:   filepath.Join(dir, "report.csv"),
+		TextHeader:   "Text",
+		EanHeader:    "EAN",
+		TimesEachEAN: 1,
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	inputs := []string{filepath.Join(dir, "a.csv"), filepath.Join(dir, "bad.csv"), filepath.Join(dir, "b.csv")}
+
+	results, err := gen.GenerateMerged(inputs, 3, log)
+	if err != nil {
+		t.Fatalf("GenerateMerged() failed: %v", err)
+	}
+	if results[0].Output != gen.PdfPath || results[1].Err == nil || results[2].Output != gen.PdfPath {
+		t.Errorf("GenerateMerged() = %+v, want bad.csv failed", results)
+	}
+	report, err := os.ReadFile(gen.ReportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(report)), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[1], "a.csv") || !strings.Contains(lines[3], "b.csv") {
+		t.Errorf("report = %q, want labels of a.csv and b.csv in order", lines)
+	}
+}
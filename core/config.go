@@ -0,0 +1,87 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Returns the generator with default values of the command line.
+func DefaultGenerator() Generator {
+	return Generator{
+		CsvPath:          "data.csv",
+		TextHeader:       "Material Number",
+		EanHeader:        "ean",
+		TimesEachEAN:     1,
+		Symbology:        SymbologyEAN,
+		HeaderSearchRows: DefaultHeaderSearchRows,
+		BarcodeRenderer:  RendererVector,
+		Serial:           SerialNumbers{Start: 1, Step: 1},
+		StartPosition:    1,
+		ErrorPolicy:      PolicyFailFast,
+	}
+}
+
+// Sets the value of the generator JSON key, keys of nested objects are
+// separated by dots ("serial.start"). Value is JSON (number, boolean,
+// null, object) or a plain string. Returns an error for unknown keys
+// and values of a wrong type, the generator is not changed then.
+func (g *Generator) SetValue(key string, value string) error {
+	parts := strings.Split(key, ".")
+	for _, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			return fmt.Errorf("Invalid key %q", key)
+		}
+	}
+	var parsed any
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		parsed = value
+	}
+	updated, err := g.withValue(parts, parsed)
+	if err != nil {
+		if _, isString := parsed.(string); isString {
+			return errors.Join(fmt.Errorf("Cannot set %s", key), err)
+		}
+		// Numbers and booleans may be strings too, e.g. header "2024".
+		var strErr error
+		if updated, strErr = g.withValue(parts, value); strErr != nil {
+			return errors.Join(fmt.Errorf("Cannot set %s", key), err)
+		}
+	}
+	*g = updated
+	return nil
+}
+
+// Returns copy of the generator with the value at the key path.
+func (g *Generator) withValue(path []string, value any) (Generator, error) {
+	content, err := json.Marshal(g)
+	if err != nil {
+		return Generator{}, err
+	}
+	tree := map[string]any{}
+	if err := json.Unmarshal(content, &tree); err != nil {
+		return Generator{}, err
+	}
+	node := tree
+	for _, part := range path[:len(path)-1] {
+		child, ok := node[part].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			node[part] = child
+		}
+		node = child
+	}
+	node[path[len(path)-1]] = value
+	if content, err = json.Marshal(tree); err != nil {
+		return Generator{}, err
+	}
+	var updated Generator
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&updated); err != nil {
+		return Generator{}, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Version of the generator JSON schema written by Save. Files without
+// the version key are version 0 and are migrated when they are loaded.
+const ConfigVersion = 1
+
+// Migrations of the generator JSON, the migration at index N changes
+// version N to version N+1.
+var configMigrations = []func(config map[string]any) error{
+	// Version 0 has the same keys, only the version is added.
+	func(config map[string]any) error { return nil },
+}
+
+// Error of a value of the generator JSON.
+type ConfigFieldError struct {
+	// JSON path of the value, keys of nested objects are separated by dots.
+	Path string
+	Err  error
+}
+
+func (e *ConfigFieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *ConfigFieldError) Unwrap() error {
+	return e.Err
+}
+
+// Decodes the generator JSON of any supported version. Unknown keys and
+// values of a wrong type are reported as ConfigFieldError with their path.
+func DecodeGenerator(content []byte) (*Generator, error) {
+	config, err := decodeConfig(content)
+	if err != nil {
+		return nil, err
+	}
+	content, err = json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var g Generator
+	if err := json.Unmarshal(content, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// Returns the generator JSON with the schema version, indented and
+// ended by a new line.
+func (g *Generator) MarshalConfig() ([]byte, error) {
+	content, err := json.MarshalIndent(struct {
+		Version int `json:"version"`
+		*Generator
+	}{ConfigVersion, g}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// Returns values of the generator JSON migrated to ConfigVersion without
+// the version key. Returns errors of all unknown and invalid values.
+func decodeConfig(content []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var config map[string]any
+	if err := decoder.Decode(&config); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			line := 1 + bytes.Count(content[:syntaxErr.Offset], []byte("\n"))
+			return nil, fmt.Errorf("Invalid JSON on line %d: %v", line, err)
+		case errors.As(err, &typeErr):
+			return nil, errors.New("Configuration must be a JSON object")
+		case errors.Is(err, io.EOF):
+			return nil, errors.New("Configuration is empty")
+		}
+		return nil, fmt.Errorf("Invalid JSON: %w", err)
+	}
+	if config == nil {
+		return nil, errors.New("Configuration must be a JSON object")
+	}
+
+	version, err := configVersion(config)
+	if err != nil {
+		return nil, err
+	}
+	for ; version < ConfigVersion; version++ {
+		if err := configMigrations[version](config); err != nil {
+			return nil, errors.Join(fmt.Errorf("Cannot migrate configuration version %d", version), err)
+		}
+	}
+	delete(config, "version")
+
+	errs := unknownConfigKeys(config, "", configKeySet())
+	// Values are decoded one by one, so all invalid values are reported.
+	for _, key := range ConfigKeys() {
+		value, ok := config[key]
+		if !ok {
+			continue
+		}
+		content, err := json.Marshal(map[string]any{key: value})
+		if err != nil {
+			return nil, err
+		}
+		var g Generator
+		if err := json.Unmarshal(content, &g); err != nil {
+			errs = append(errs, configValueError(key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return config, nil
+}
+
+// Returns the schema version of the generator JSON, 0 if it is not set.
+func configVersion(config map[string]any) (int, error) {
+	value, ok := config["version"]
+	if !ok {
+		return 0, nil
+	}
+	number, ok := value.(json.Number)
+	version, err := number.Int64()
+	if !ok || err != nil || version < 0 {
+		return 0, &ConfigFieldError{Path: "version", Err: fmt.Errorf("Expected non negative integer not %v", value)}
+	}
+	if version > ConfigVersion {
+		return 0, &ConfigFieldError{Path: "version", Err: fmt.Errorf("Configuration version %d is newer than supported version %d, update EANBaker", version, ConfigVersion)}
+	}
+	return int(version), nil
+}
+
+// Returns the known keys of the generator JSON and whether they are objects
+// with known keys, values of other objects (e.g. GS1 columns) are not checked.
+func configKeySet() map[string]bool {
+	keys := map[string]bool{}
+	// Parent keys are listed before their children.
+	for _, key := range ConfigKeys() {
+		keys[key] = false
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			keys[key[:i]] = true
+		}
+	}
+	return keys
+}
+
+// Returns errors of keys of the object that are not in the known keys.
+func unknownConfigKeys(object map[string]any, prefix string, known map[string]bool) []error {
+	errs := []error{}
+	for _, key := range sortedKeys(object) {
+		path := prefix + key
+		isObject, ok := known[path]
+		if !ok {
+			errs = append(errs, &ConfigFieldError{Path: path, Err: errors.New("Unknown key")})
+			continue
+		}
+		if child, isMap := object[key].(map[string]any); isObject && isMap {
+			errs = append(errs, unknownConfigKeys(child, path+".", known)...)
+		}
+	}
+	return errs
+}
+
+// Returns the error of the invalid value of the key with the path of the value.
+func configValueError(key string, err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		path := key
+		if typeErr.Field != "" {
+			path = typeErr.Field
+		}
+		return &ConfigFieldError{Path: path, Err: fmt.Errorf("Expected %s not %s", typeErr.Type, typeErr.Value)}
+	}
+	return &ConfigFieldError{Path: key, Err: err}
+}
+
+// Returns keys of the object in alphabetical order.
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeGenerator(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		want      string
+		wantPaths []string
+		wantErr   string
+	}{
+		{name: "version 0", content: `{"text_header": "Name", "serial": {"start": 5}}`, want: "Name"},
+		{name: "current version", content: `{"version": 1, "text_header": "Name"}`, want: "Name"},
+		{name: "gs1 columns", content: `{"text_header": "Name", "gs1_columns": {"10": "Batch"}}`, want: "Name"},
+		{name: "unknown key", content: `{"text_header": "Name", "colour": "red"}`, wantPaths: []string{"colour"}},
+		{name: "unknown nested key", content: `{"serial": {"stat": 1}, "layout": {"margins": {"up": 1}}}`, wantPaths: []string{"layout.margins.up", "serial.stat"}},
+		{name: "invalid values", content: `{"times_each_ean": "many", "serial": {"start": "x"}, "symbology": "foo"}`, wantPaths: []string{"times_each_ean", "symbology", "serial.start"}},
+		{name: "newer version", content: `{"version": 2}`, wantPaths: []string{"version"}},
+		{name: "invalid version", content: `{"version": "one"}`, wantPaths: []string{"version"}},
+		{name: "syntax error", content: "{\n\"text_header\": \"Name\",,\n}", wantErr: "line 2"},
+		{name: "not object", content: `["text_header"]`, wantErr: "JSON object"},
+		{name: "null", content: `null`, wantErr: "JSON object"},
+		{name: "empty", content: ``, wantErr: "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := DecodeGenerator([]byte(tt.content))
+			if tt.wantPaths == nil && tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("DecodeGenerator() failed: %v", err)
+				}
+				if g.TextHeader != tt.want {
+					t.Errorf("DecodeGenerator().TextHeader = %q, want %q", g.TextHeader, tt.want)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("DecodeGenerator() should fail")
+			}
+			if tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("DecodeGenerator() error = %v, want %q", err, tt.wantErr)
+			}
+			paths := []string{}
+			var joined interface{ Unwrap() []error }
+			errs := []error{err}
+			if errors.As(err, &joined) {
+				errs = joined.Unwrap()
+			}
+			for _, e := range errs {
+				var fieldErr *ConfigFieldError
+				if errors.As(e, &fieldErr) {
+					paths = append(paths, fieldErr.Path)
+				}
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("DecodeGenerator() error paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestGenerator_MarshalConfig(t *testing.T) {
+	if len(configMigrations) != ConfigVersion {
+		t.Fatalf("%d migrations, want one for every version before %d", len(configMigrations), ConfigVersion)
+	}
+	g := DefaultGenerator()
+	g.TextHeader = "Name"
+	g.GS1Columns = map[string]string{"10": "Batch"}
+	content, err := g.MarshalConfig()
+	if err != nil {
+		t.Fatalf("MarshalConfig() failed: %v", err)
+	}
+	if !bytes.HasPrefix(content, []byte("{\n  \"version\": 1,\n")) || !bytes.HasSuffix(content, []byte("}\n")) {
+		t.Errorf("MarshalConfig() = %s, want indented JSON starting by version", content)
+	}
+	decoded, err := DecodeGenerator(content)
+	if err != nil {
+		t.Fatalf("DecodeGenerator() failed: %v", err)
+	}
+	if decoded.TextHeader != "Name" || decoded.GS1Columns["10"] != "Batch" || decoded.Serial.Step != 1 {
+		t.Errorf("DecodeGenerator() = %+v, want marshalled generator", decoded)
+	}
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestDefaultGenerator_Validate(t *testing.T) {
+	generator := DefaultGenerator()
+	generator.UpdatePdfPath()
+	if err := generator.Validate(); err != nil {
+		t.Errorf("Validate() of default generator failed: %v", err)
+	}
+}
+
+func TestGenerator_SetValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		check   func(g Generator) bool
+		wantErr bool
+	}{
+		{name: "String", key: "text_header", value: "Name", check: func(g Generator) bool { return g.TextHeader == "Name" }},
+		{name: "Number as string", key: "text_header", value: "2024", check: func(g Generator) bool { return g.TextHeader == "2024" }},
+		{name: "Number", key: "times_each_ean", value: "3", check: func(g Generator) bool { return g.TimesEachEAN == 3 }},
+		{name: "Boolean", key: "merge_sheets", value: "true", check: func(g Generator) bool { return g.MergeSheets }},
+		{name: "Nested", key: "serial.start", value: "1000", check: func(g Generator) bool { return g.Serial.Start == 1000 }},
+		{name: "Map", key: "gs1_columns.17", value: "Expiry", check: func(g Generator) bool { return g.GS1Columns["17"] == "Expiry" }},
+		{name: "Enum alias", key: "symbology", value: "EAN-128", check: func(g Generator) bool { return g.Symbology == SymbologyGS1128 }},
+		{name: "Comma", key: "csv_comma", value: ";", check: func(g Generator) bool { return g.CsvComma == ';' }},
+		{name: "Unknown key", key: "colour", value: "red", wantErr: true},
+		{name: "Unknown nested key", key: "serial.colour", value: "red", wantErr: true},
+		{name: "Wrong type", key: "times_each_ean", value: "many", wantErr: true},
+		{name: "Invalid enum", key: "error_policy", value: "ignore", wantErr: true},
+		{name: "Empty key", key: "serial.", value: "1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := DefaultGenerator()
+			before := generator.TimesEachEAN
+			err := generator.SetValue(tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if generator.TimesEachEAN != before {
+					t.Error("SetValue() changed the generator on error")
+				}
+				return
+			}
+			if !tt.check(generator) {
+				t.Errorf("SetValue() = %+v, value not set", generator)
+			}
+			if generator.EanHeader != DefaultGenerator().EanHeader {
+				t.Error("SetValue() changed other values")
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+type TextEncoding string
+
+const (
+	// Encoding is detected from the content.
+	EncodingAuto        TextEncoding = ""
+	EncodingUTF8        TextEncoding = "utf-8"
+	EncodingUTF16LE     TextEncoding = "utf-16le"
+	EncodingUTF16BE     TextEncoding = "utf-16be"
+	EncodingWindows1250 TextEncoding = "windows-1250"
+	EncodingISO88592    TextEncoding = "iso-8859-2"
+)
+
+// Supported encodings of text input.
+var TextEncodings = []TextEncoding{
+	EncodingUTF8,
+	EncodingUTF16LE,
+	EncodingUTF16BE,
+	EncodingWindows1250,
+	EncodingISO88592,
+}
+
+// Converts a string to a TextEncoding. Common aliases like "cp1250"
+// or "latin2" are accepted. Empty string means automatic detection.
+// Returns an error for unknown encodings.
+func TextEncodingFromString(s string) (TextEncoding, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "auto":
+		return EncodingAuto, nil
+	case "utf-8", "utf8":
+		return EncodingUTF8, nil
+	case "utf-16le", "utf16le", "utf-16", "utf16", "unicode":
+		return EncodingUTF16LE, nil
+	case "utf-16be", "utf16be":
+		return EncodingUTF16BE, nil
+	case "windows-1250", "cp1250", "win1250":
+		return EncodingWindows1250, nil
+	case "iso-8859-2", "iso8859-2", "latin2", "latin-2":
+		return EncodingISO88592, nil
+	}
+	names := []string{}
+	for _, e := range TextEncodings {
+		names = append(names, string(e))
+	}
+	return "", fmt.Errorf("Unknown encoding %q, expected one of: auto, %s", s, strings.Join(names, ", "))
+}
+
+// Implements the json.Unmarshaler interface for TextEncoding.
+func (e *TextEncoding) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	encoding, err := TextEncodingFromString(s)
+	if err != nil {
+		return err
+	}
+	*e = encoding
+	return nil
+}
+
+// Format of delimited text input. Zero values are detected from the content.
+type CsvDialect struct {
+	// Field separator.
+	Comma rune
+	// Quote character, either '"' or '\''.
+	Quote    rune
+	Encoding TextEncoding
+	// Input starts with a byte order mark. Only set by detection.
+	BOM bool
+	// Records end with "\r\n" instead of "\n". Only set by detection.
+	CRLF bool
+}
+
+// Separators tried when the separator is detected, in order of preference.
+var csvSeparators = []rune{',', ';', '\t', '|'}
+
+// Number of records used to detect the separator and quote character.
+const sniffRecords = 20
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// Detects encoding of the data from its byte order mark. Data without
+// it are UTF-8 if they are valid UTF-8. Otherwise one of the single byte
+// central european encodings is guessed: Windows-1250 uses bytes 0x80-0x9F
+// for letters (š, ž, ť, ś, ...), which are control characters in ISO-8859-2,
+// and ISO-8859-2 has these letters in 0xA1-0xBF instead.
+func sniffEncoding(data []byte) (TextEncoding, bool) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return EncodingUTF8, true
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return EncodingUTF16LE, true
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return EncodingUTF16BE, true
+	case utf8.Valid(data):
+		return EncodingUTF8, false
+	}
+	iso := false
+	for _, b := range data {
+		if b >= 0x80 && b <= 0x9F {
+			return EncodingWindows1250, false
+		}
+		if b >= 0xA1 && b <= 0xBF {
+			iso = true
+		}
+	}
+	if iso {
+		return EncodingISO88592, false
+	}
+	return EncodingWindows1250, false
+}
+
+// Decodes the data in the encoding to a string without byte order mark.
+func decodeText(data []byte, encoding TextEncoding) (string, error) {
+	var decoded []byte
+	var err error
+	switch encoding {
+	case EncodingUTF8, EncodingAuto:
+		return string(bytes.TrimPrefix(data, bomUTF8)), nil
+	case EncodingUTF16LE:
+		decoded, err = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder().Bytes(data)
+	case EncodingUTF16BE:
+		decoded, err = unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder().Bytes(data)
+	case EncodingWindows1250:
+		decoded, err = charmap.Windows1250.NewDecoder().Bytes(data)
+	case EncodingISO88592:
+		decoded, err = charmap.ISO8859_2.NewDecoder().Bytes(data)
+	default:
+		return "", fmt.Errorf("Unknown encoding %q", encoding)
+	}
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("Cannot decode input as %s", encoding), err)
+	}
+	return string(decoded), nil
+}
+
+// Encodes the text in the encoding, byte order mark is written if bom is set.
+func encodeText(text string, encoding TextEncoding, bom bool) ([]byte, error) {
+	var encoded []byte
+	var err error
+	switch encoding {
+	case EncodingUTF8, EncodingAuto:
+		if bom {
+			return append(bytes.Clone(bomUTF8), text...), nil
+		}
+		return []byte(text), nil
+	case EncodingUTF16LE, EncodingUTF16BE:
+		endianness, policy := unicode.LittleEndian, unicode.IgnoreBOM
+		if encoding == EncodingUTF16BE {
+			endianness = unicode.BigEndian
+		}
+		if bom {
+			policy = unicode.UseBOM
+		}
+		encoded, err = unicode.UTF16(endianness, policy).NewEncoder().Bytes([]byte(text))
+	case EncodingWindows1250:
+		encoded, err = charmap.Windows1250.NewEncoder().Bytes([]byte(text))
+	case EncodingISO88592:
+		encoded, err = charmap.ISO8859_2.NewEncoder().Bytes([]byte(text))
+	default:
+		return nil, fmt.Errorf("Unknown encoding %q", encoding)
+	}
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("Cannot encode output as %s", encoding), err)
+	}
+	return encoded, nil
+}
+
+// Detects the quote character. Single quote is used only if some field
+// starts with it and no field starts with double quote, so apostrophes
+// inside of words are not mistaken for quotes.
+func sniffQuote(text string) rune {
+	fieldStarts := func(quote rune) int {
+		count := 0
+		lines := strings.SplitN(text, "\n", sniffRecords+1)
+		for _, line := range lines[:min(len(lines), sniffRecords)] {
+			previous := '\n'
+			for _, c := range line {
+				if c == quote && strings.ContainsRune("\n"+string(csvSeparators), previous) {
+					count++
+				}
+				previous = c
+			}
+		}
+		return count
+	}
+	if fieldStarts('\'') > 0 && fieldStarts('"') == 0 {
+		return '\''
+	}
+	return '"'
+}
+
+// Detects the separator as the one splitting the first records into
+// the same and largest number of fields. Returns ',' when no separator
+// splits the records.
+func sniffComma(text string) rune {
+	best, bestFields := ',', 1
+	for _, comma := range csvSeparators {
+		reader := csv.NewReader(strings.NewReader(text))
+		reader.Comma = comma
+		fields := 0
+		for i := 0; i < sniffRecords; i++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				fields = 0
+				break
+			}
+			fields = len(record)
+		}
+		if fields > bestFields {
+			best, bestFields = comma, fields
+		}
+	}
+	return best
+}
+
+// Swaps single and double quotes. Swapping is its own inverse, so text
+// quoted by single quotes can be parsed by the standard CSV reader.
+func swapQuotes(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '"':
+			return '\''
+		case '\'':
+			return '"'
+		}
+		return r
+	}, s)
+}
+
+// Reads delimited text data from an io.Reader and returns it as a 2D string
+// table. Fields of the dialect that are not set are detected from the content.
+// Returns the table and the dialect used to read it.
+func TableFromCsvDialect(r io.Reader, dialect CsvDialect) (Table, CsvDialect, error) {
+	if r == nil {
+		return nil, dialect, errors.New("Reader is <nil>")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, dialect, err
+	}
+
+	encoding, bom := sniffEncoding(data)
+	dialect.BOM = bom
+	if dialect.Encoding == EncodingAuto {
+		dialect.Encoding = encoding
+	}
+	text, err := decodeText(data, dialect.Encoding)
+	if err != nil {
+		return nil, dialect, err
+	}
+	dialect.CRLF = strings.Contains(text, "\r\n")
+
+	if dialect.Quote == 0 {
+		dialect.Quote = sniffQuote(text)
+	}
+	switch dialect.Quote {
+	case '"':
+	case '\'':
+		text = swapQuotes(text)
+	default:
+		return nil, dialect, fmt.Errorf("Unsupported quote character %q", dialect.Quote)
+	}
+	if dialect.Comma == 0 {
+		dialect.Comma = sniffComma(text)
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = dialect.Comma
+	table, err := reader.ReadAll()
+	if err != nil {
+		return nil, dialect, err
+	}
+	if dialect.Quote == '\'' {
+		for _, row := range table {
+			for i := range row {
+				row[i] = swapQuotes(row[i])
+			}
+		}
+	}
+	return table, dialect, nil
+}
+
+// Writes the table as delimited text in the dialect, so a table read by
+// TableFromCsvDialect is written back with the same separator, quote
+// character, encoding, byte order mark and line endings.
+func WriteCsvDialect(w io.Writer, table Table, dialect CsvDialect) error {
+	if dialect.Comma == 0 {
+		dialect.Comma = ','
+	}
+	var buf strings.Builder
+	writer := csv.NewWriter(&buf)
+	writer.Comma = dialect.Comma
+	writer.UseCRLF = dialect.CRLF
+	for _, row := range table {
+		if dialect.Quote == '\'' {
+			swapped := make([]string, len(row))
+			for i, field := range row {
+				swapped[i] = swapQuotes(field)
+			}
+			row = swapped
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	text := buf.String()
+	if dialect.Quote == '\'' {
+		text = swapQuotes(text)
+	}
+	encoded, err := encodeText(text, dialect.Encoding, dialect.BOM)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(encoded)
+	return err
+}
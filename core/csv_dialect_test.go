@@ -0,0 +1,228 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestTableFromCsvDialect(t *testing.T) {
+	encode := func(t *testing.T, s string, encoder interface{ Bytes([]byte) ([]byte, error) }) string {
+		t.Helper()
+		data, err := encoder.Bytes([]byte(s))
+		if err != nil {
+			t.Fatalf("Cannot encode test data: %v", err)
+		}
+		return string(data)
+	}
+	tests := []struct {
+		name         string
+		data         string
+		dialect      CsvDialect
+		want         Table
+		wantComma    rune
+		wantQuote    rune
+		wantEncoding TextEncoding
+		wantBOM      bool
+	}{
+		{
+			name:         "Comma",
+			data:         "name,ean\nMilk,123\n",
+			want:         Table{{"name", "ean"}, {"Milk", "123"}},
+			wantComma:    ',',
+			wantQuote:    '"',
+			wantEncoding: EncodingUTF8,
+		},
+		{
+			name:         "Semicolon with commas in quoted text",
+			data:         "name;ean\n\"Milk, 1l\";123\n\"Bread, white\";456\n",
+			want:         Table{{"name", "ean"}, {"Milk, 1l", "123"}, {"Bread, white", "456"}},
+			wantComma:    ';',
+			wantQuote:    '"',
+			wantEncoding: EncodingUTF8,
+		},
+		{
+			name:         "Tab",
+			data:         "name\tean\nMilk\t123\n",
+			want:         Table{{"name", "ean"}, {"Milk", "123"}},
+			wantComma:    '\t',
+			wantQuote:    '"',
+			wantEncoding: EncodingUTF8,
+		},
+		{
+			name:         "Pipe",
+			data:         "name|ean|count\nMilk|123|1\n",
+			want:         Table{{"name", "ean", "count"}, {"Milk", "123", "1"}},
+			wantComma:    '|',
+			wantQuote:    '"',
+			wantEncoding: EncodingUTF8,
+		},
+		{
+			name:         "Single quotes",
+			data:         "name;ean\n'Milk; \"fresh\"';123\n'Baker''s bread';456\n",
+			want:         Table{{"name", "ean"}, {"Milk; \"fresh\"", "123"}, {"Baker's bread", "456"}},
+			wantComma:    ';',
+			wantQuote:    '\'',
+			wantEncoding: EncodingUTF8,
+		},
+		{
+			name:         "Apostrophe is not a quote",
+			data:         "name,ean\nBaker's bread,456\n",
+			want:         Table{{"name", "ean"}, {"Baker's bread", "456"}},
+			wantComma:    ',',
+			wantQuote:    '"',
+			wantEncoding: EncodingUTF8,
+		},
+		{
+			name:         "UTF-8 BOM",
+			data:         "\xEF\xBB\xBFname;ean\nMléko;123\n",
+			want:         Table{{"name", "ean"}, {"Mléko", "123"}},
+			wantComma:    ';',
+			wantQuote:    '"',
+			wantEncoding: EncodingUTF8,
+			wantBOM:      true,
+		},
+		{
+			name:         "UTF-16 with BOM",
+			data:         encode(t, "name\tean\nŽluťoučký kůň\t123\n", unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder()),
+			want:         Table{{"name", "ean"}, {"Žluťoučký kůň", "123"}},
+			wantComma:    '\t',
+			wantQuote:    '"',
+			wantEncoding: EncodingUTF16LE,
+			wantBOM:      true,
+		},
+		{
+			name:         "Windows-1250",
+			data:         encode(t, "name;ean\nŠťovík žlutý;123\n", charmap.Windows1250.NewEncoder()),
+			want:         Table{{"name", "ean"}, {"Šťovík žlutý", "123"}},
+			wantComma:    ';',
+			wantQuote:    '"',
+			wantEncoding: EncodingWindows1250,
+		},
+		{
+			name:         "ISO-8859-2",
+			data:         encode(t, "name;ean\nŠťovík žlutý;123\n", charmap.ISO8859_2.NewEncoder()),
+			want:         Table{{"name", "ean"}, {"Šťovík žlutý", "123"}},
+			wantComma:    ';',
+			wantQuote:    '"',
+			wantEncoding: EncodingISO88592,
+		},
+		{
+			name:         "Configured dialect wins",
+			data:         "a;b,c\n1;2,3\n",
+			dialect:      CsvDialect{Comma: ';'},
+			want:         Table{{"a", "b,c"}, {"1", "2,3"}},
+			wantComma:    ';',
+			wantQuote:    '"',
+			wantEncoding: EncodingUTF8,
+		},
+		{
+			name:         "Single column",
+			data:         "ean\n123\n",
+			want:         Table{{"ean"}, {"123"}},
+			wantComma:    ',',
+			wantQuote:    '"',
+			wantEncoding: EncodingUTF8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dialect, err := TableFromCsvDialect(strings.NewReader(tt.data), tt.dialect)
+			if err != nil {
+				t.Fatalf("TableFromCsvDialect() failed: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("TableFromCsvDialect() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if strings.Join(got[i], "\x00") != strings.Join(tt.want[i], "\x00") {
+					t.Errorf("TableFromCsvDialect() row %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+			if dialect.Comma != tt.wantComma {
+				t.Errorf("Comma = %q, want %q", dialect.Comma, tt.wantComma)
+			}
+			if dialect.Quote != tt.wantQuote {
+				t.Errorf("Quote = %q, want %q", dialect.Quote, tt.wantQuote)
+			}
+			if dialect.Encoding != tt.wantEncoding {
+				t.Errorf("Encoding = %q, want %q", dialect.Encoding, tt.wantEncoding)
+			}
+			if dialect.BOM != tt.wantBOM {
+				t.Errorf("BOM = %t, want %t", dialect.BOM, tt.wantBOM)
+			}
+		})
+	}
+}
+
+func TestTableFromCsvDialect_Errors(t *testing.T) {
+	if _, _, err := TableFromCsvDialect(nil, CsvDialect{}); err == nil {
+		t.Error("TableFromCsvDialect() should fail for nil reader")
+	}
+	if _, _, err := TableFromCsvDialect(strings.NewReader("a,b"), CsvDialect{Quote: '`'}); err == nil {
+		t.Error("TableFromCsvDialect() should fail for unsupported quote")
+	}
+	if _, _, err := TableFromCsvDialect(bytes.NewReader([]byte("a,b\n1,2,3\n")), CsvDialect{Comma: ','}); err == nil {
+		t.Error("TableFromCsvDialect() should fail for inconsistent rows")
+	}
+}
+
+func TestWriteCsvDialect_RoundTrip(t *testing.T) {
+	windows1250, _ := charmap.Windows1250.NewEncoder().Bytes([]byte("název;ean\r\nŠpenát;123\r\n"))
+	utf16, _ := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte("name\tean\nMilk\t123\n"))
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Comma", data: []byte("name,ean\n\"Milk, 1l\",123\n")},
+		{name: "UTF-8 BOM", data: []byte("\xEF\xBB\xBFname,ean\nMilk,123\n")},
+		{name: "Single quotes", data: []byte("name;ean\n'Milk; 1l';123\n'Tom''s bread';456\n")},
+		{name: "Windows-1250 with CRLF", data: windows1250},
+		{name: "UTF-16LE", data: utf16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, dialect, err := TableFromCsvDialect(bytes.NewReader(tt.data), CsvDialect{})
+			if err != nil {
+				t.Fatalf("TableFromCsvDialect() failed: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := WriteCsvDialect(&buf, table, dialect); err != nil {
+				t.Fatalf("WriteCsvDialect() failed: %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), tt.data) {
+				t.Errorf("WriteCsvDialect() = %q, want %q", buf.Bytes(), tt.data)
+			}
+		})
+	}
+}
+
+func TestTextEncodingFromString(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    TextEncoding
+		wantErr bool
+	}{
+		{input: "", want: EncodingAuto},
+		{input: "auto", want: EncodingAuto},
+		{input: "UTF8", want: EncodingUTF8},
+		{input: "cp1250", want: EncodingWindows1250},
+		{input: "Latin2", want: EncodingISO88592},
+		{input: "utf-16", want: EncodingUTF16LE},
+		{input: "koi8-r", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := TextEncodingFromString(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TextEncodingFromString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TextEncodingFromString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package core
+
+import (
+	"errors"
+	"image"
+	"image/color"
+
+	"github.com/boombuler/barcode"
+)
+
+// DataMatrix codeword of FNC1, which marks GS1 data when it is the first one.
+const dataMatrixFNC1 = 232
+
+// Square ECC 200 DataMatrix symbol with a single error correction block.
+type dataMatrixSize struct {
+	// Size of the symbol in modules including finder patterns.
+	size int
+	// Number of data regions in a row and a column.
+	regions int
+	data    int
+	ecc     int
+}
+
+// Symbol sizes from ISO/IEC 16022 up to 48x48, larger symbols interleave
+// more error correction blocks and are not needed for GS1 element strings.
+var dataMatrixSizes = []dataMatrixSize{
+	{10, 1, 3, 5},
+	{12, 1, 5, 7},
+	{14, 1, 8, 10},
+	{16, 1, 12, 12},
+	{18, 1, 18, 14},
+	{20, 1, 22, 18},
+	{22, 1, 30, 20},
+	{24, 1, 36, 24},
+	{26, 1, 44, 28},
+	{32, 2, 62, 36},
+	{36, 2, 86, 42},
+	{40, 2, 114, 48},
+	{44, 2, 144, 56},
+	{48, 2, 174, 68},
+}
+
+// Encodes the data in DataMatrix ASCII mode. Pairs of digits share one
+// codeword, FNC1 runes are encoded as FNC1 codewords.
+func dataMatrixCodewords(content string, fnc1 rune) []byte {
+	data := []rune(content)
+	codewords := []byte{}
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == fnc1:
+			codewords = append(codewords, dataMatrixFNC1)
+		case c >= '0' && c <= '9' && i+1 < len(data) && data[i+1] >= '0' && data[i+1] <= '9':
+			codewords = append(codewords, byte(130+(c-'0')*10+(data[i+1]-'0')))
+			i++
+		case c < 128:
+			codewords = append(codewords, byte(c+1))
+		default:
+			// Upper shift, GS1 data never contain these characters.
+			codewords = append(codewords, 235, byte(c-127))
+		}
+	}
+	return codewords
+}
+
+// Fills the codewords to the data capacity of the symbol with pad codewords.
+func dataMatrixPadding(codewords []byte, capacity int) []byte {
+	if len(codewords) < capacity {
+		codewords = append(codewords, 129)
+	}
+	for len(codewords) < capacity {
+		// Pseudo random padding of 253-state algorithm, positions are one based.
+		pad := 129 + (149*(len(codewords)+1))%253 + 1
+		if pad > 254 {
+			pad -= 254
+		}
+		codewords = append(codewords, byte(pad))
+	}
+	return codewords
+}
+
+// Logarithm and exponent tables of GF(256) with DataMatrix polynomial 301.
+var gfLog, gfExp = func() ([256]int, [256]int) {
+	var log, exp [256]int
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		log[x] = i
+		x <<= 1
+		if x >= 256 {
+			x ^= 301
+		}
+	}
+	return log, exp
+}()
+
+// Multiplies two elements of GF(256).
+func gfMul(a int, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[(gfLog[a]+gfLog[b])%255]
+}
+
+// Returns Reed-Solomon error correction codewords of the data.
+func reedSolomon(data []byte, count int) []byte {
+	// Generator polynomial (x - 2^1)...(x - 2^count), highest degree first.
+	generator := []int{1}
+	for i := 1; i <= count; i++ {
+		next := make([]int, len(generator)+1)
+		for j, g := range generator {
+			next[j] ^= g
+			next[j+1] ^= gfMul(g, gfExp[i])
+		}
+		generator = next
+	}
+	ecc := make([]int, count)
+	for _, d := range data {
+		feedback := int(d) ^ ecc[0]
+		copy(ecc, ecc[1:])
+		ecc[count-1] = 0
+		for j := range ecc {
+			ecc[j] ^= gfMul(generator[j+1], feedback)
+		}
+	}
+	ret := make([]byte, count)
+	for i, e := range ecc {
+		ret[i] = byte(e)
+	}
+	return ret
+}
+
+// Places codewords to the mapping matrix by the ECC 200 placement algorithm
+// of ISO/IEC 16022 Annex F. Returns dark modules of the matrix.
+func dataMatrixPlacement(codewords []byte, rows int, columns int) []bool {
+	// Zero is an empty module, otherwise 10 * codeword + bit or 1 for fixed dark module.
+	matrix := make([]int, rows*columns)
+	module := func(row int, col int, chr int, bit int) {
+		if row < 0 {
+			row += rows
+			col += 4 - (rows+4)%8
+		}
+		if col < 0 {
+			col += columns
+			row += 4 - (columns+4)%8
+		}
+		matrix[row*columns+col] = 10*chr + bit
+	}
+	utah := func(row int, col int, chr int) {
+		module(row-2, col-2, chr, 1)
+		module(row-2, col-1, chr, 2)
+		module(row-1, col-2, chr, 3)
+		module(row-1, col-1, chr, 4)
+		module(row-1, col, chr, 5)
+		module(row, col-2, chr, 6)
+		module(row, col-1, chr, 7)
+		module(row, col, chr, 8)
+	}
+	corners := [][8][2]int{
+		{{rows - 1, 0}, {rows - 1, 1}, {rows - 1, 2}, {0, columns - 2}, {0, columns - 1}, {1, columns - 1}, {2, columns - 1}, {3, columns - 1}},
+		{{rows - 3, 0}, {rows - 2, 0}, {rows - 1, 0}, {0, columns - 4}, {0, columns - 3}, {0, columns - 2}, {0, columns - 1}, {1, columns - 1}},
+		{{rows - 3, 0}, {rows - 2, 0}, {rows - 1, 0}, {0, columns - 2}, {0, columns - 1}, {1, columns - 1}, {2, columns - 1}, {3, columns - 1}},
+		{{rows - 1, 0}, {rows - 1, columns - 1}, {0, columns - 3}, {0, columns - 2}, {0, columns - 1}, {1, columns - 3}, {1, columns - 2}, {1, columns - 1}},
+	}
+	corner := func(shape int, chr int) {
+		for bit, position := range corners[shape] {
+			module(position[0], position[1], chr, bit+1)
+		}
+	}
+
+	chr, row, col := 1, 4, 0
+	for row < rows || col < columns {
+		switch {
+		case row == rows && col == 0:
+			corner(0, chr)
+			chr++
+		case row == rows-2 && col == 0 && columns%4 != 0:
+			corner(1, chr)
+			chr++
+		case row == rows-2 && col == 0 && columns%8 == 4:
+			corner(2, chr)
+			chr++
+		case row == rows+4 && col == 2 && columns%8 == 0:
+			corner(3, chr)
+			chr++
+		}
+		// Sweep upward diagonally.
+		for {
+			if row < rows && col >= 0 && matrix[row*columns+col] == 0 {
+				utah(row, col, chr)
+				chr++
+			}
+			row -= 2
+			col += 2
+			if row < 0 || col >= columns {
+				break
+			}
+		}
+		row++
+		col += 3
+		// Sweep downward diagonally.
+		for {
+			if row >= 0 && col < columns && matrix[row*columns+col] == 0 {
+				utah(row, col, chr)
+				chr++
+			}
+			row += 2
+			col -= 2
+			if row >= rows || col < 0 {
+				break
+			}
+		}
+		row += 3
+		col++
+	}
+	// Unfilled bottom right corner has a fixed pattern.
+	if matrix[rows*columns-1] == 0 {
+		matrix[rows*columns-1] = 1
+		matrix[rows*columns-columns-2] = 1
+	}
+
+	dark := make([]bool, len(matrix))
+	for i, value := range matrix {
+		if value == 1 {
+			dark[i] = true
+		} else if value >= 10 {
+			codeword, bit := codewords[value/10-1], value%10
+			dark[i] = codeword&(1<<(8-bit)) != 0
+		}
+	}
+	return dark
+}
+
+// Two dimensional barcode given by its modules.
+type matrixCode struct {
+	modules [][]bool
+	content string
+	kind    string
+}
+
+func (c *matrixCode) ColorModel() color.Model {
+	return color.Gray16Model
+}
+
+func (c *matrixCode) Bounds() image.Rectangle {
+	return image.Rect(0, 0, len(c.modules[0]), len(c.modules))
+}
+
+func (c *matrixCode) At(x int, y int) color.Color {
+	if c.modules[y][x] {
+		return color.Black
+	}
+	return color.White
+}
+
+func (c *matrixCode) Metadata() barcode.Metadata {
+	return barcode.Metadata{CodeKind: c.kind, Dimensions: 2}
+}
+
+func (c *matrixCode) Content() string {
+	return c.content
+}
+
+// Encodes the content as ECC 200 DataMatrix in ASCII mode. FNC1 runes of
+// the content are encoded as FNC1 codewords, GS1 DataMatrix starts with it.
+func encodeDataMatrix(content string, fnc1 rune, kind string) (barcode.Barcode, error) {
+	codewords := dataMatrixCodewords(content, fnc1)
+	var size *dataMatrixSize
+	for i := range dataMatrixSizes {
+		if dataMatrixSizes[i].data >= len(codewords) {
+			size = &dataMatrixSizes[i]
+			break
+		}
+	}
+	if size == nil {
+		return nil, errors.New("Too much data for DataMatrix")
+	}
+	codewords = dataMatrixPadding(codewords, size.data)
+	codewords = append(codewords, reedSolomon(codewords, size.ecc)...)
+
+	regionSize := size.size/size.regions - 2
+	mapping := size.regions * regionSize
+	placed := dataMatrixPlacement(codewords, mapping, mapping)
+
+	modules := make([][]bool, size.size)
+	for y := range modules {
+		modules[y] = make([]bool, size.size)
+	}
+	for y := 0; y < size.size; y++ {
+		for x := 0; x < size.size; x++ {
+			// Position inside of the region including its finder pattern.
+			ry, rx := y%(regionSize+2), x%(regionSize+2)
+			switch {
+			case rx == 0 || ry == regionSize+1:
+				// Solid left and bottom finder pattern.
+				modules[y][x] = true
+			case ry == 0:
+				// Alternating top timing pattern.
+				modules[y][x] = rx%2 == 0
+			case rx == regionSize+1:
+				// Alternating right timing pattern.
+				modules[y][x] = ry%2 == 1
+			default:
+				row := y/(regionSize+2)*regionSize + ry - 1
+				col := x/(regionSize+2)*regionSize + rx - 1
+				modules[y][x] = placed[row*mapping+col]
+			}
+		}
+	}
+	return &matrixCode{modules: modules, content: content, kind: kind}, nil
+}
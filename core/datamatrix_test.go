@@ -0,0 +1,78 @@
+package core
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/datamatrix"
+)
+
+func TestDataMatrixCodewords(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []byte
+	}{
+		{name: "Digit pairs", content: "123456", want: []byte{142, 164, 186}},
+		{name: "Odd digit", content: "A1", want: []byte{66, 50}},
+		{name: "FNC1", content: string(code128.FNC1) + "0112" + string(code128.FNC1) + "X", want: []byte{232, 131, 142, 232, 89}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dataMatrixCodewords(tt.content, code128.FNC1)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("dataMatrixCodewords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReedSolomon(t *testing.T) {
+	// Example of ISO/IEC 16022 for "123456" in 10x10 symbol.
+	got := reedSolomon([]byte{142, 164, 186}, 5)
+	want := []byte{114, 25, 5, 88, 102}
+	if !slices.Equal(got, want) {
+		t.Errorf("reedSolomon() = %v, want %v", got, want)
+	}
+}
+
+func TestEncodeDataMatrix(t *testing.T) {
+	// Without FNC1 the symbol must be identical to the one of the datamatrix package.
+	tests := []string{
+		"1",
+		"Hello",
+		"0123456789",
+		"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+		"01095011015300031725123110ABC123",
+		"The quick brown fox jumps over the lazy dog 1234567890 The quick brown fox jumps over the lazy dog",
+	}
+	for _, content := range tests {
+		t.Run(content, func(t *testing.T) {
+			want, err := datamatrix.Encode(content)
+			if err != nil {
+				t.Fatalf("datamatrix.Encode() failed: %v", err)
+			}
+			got, err := encodeDataMatrix(content, code128.FNC1, "DataMatrix")
+			if err != nil {
+				t.Fatalf("encodeDataMatrix() failed: %v", err)
+			}
+			if got.Bounds() != want.Bounds() {
+				t.Fatalf("encodeDataMatrix() bounds = %v, want %v", got.Bounds(), want.Bounds())
+			}
+			gotModules, wantModules := barcodeModules(got), barcodeModules(want)
+			for y := range wantModules {
+				if !slices.Equal(gotModules[y], wantModules[y]) {
+					t.Fatalf("encodeDataMatrix() row %d differs", y)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeDataMatrix_TooLong(t *testing.T) {
+	content := string(make([]byte, 200))
+	if _, err := encodeDataMatrix(content, code128.FNC1, "DataMatrix"); err == nil {
+		t.Error("encodeDataMatrix() should fail for data over 174 codewords")
+	}
+}
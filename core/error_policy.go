@@ -0,0 +1,99 @@
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Policy applied when a barcode of a record cannot be generated.
+type ErrorPolicy string
+
+const (
+	// Abort the whole generation on the first invalid record.
+	PolicyFailFast ErrorPolicy = "fail-fast"
+	// Leave invalid records out of the document.
+	PolicySkipInvalid ErrorPolicy = "skip-invalid"
+	// Render a visibly marked INVALID label instead of the barcode.
+	PolicyPlaceholder ErrorPolicy = "placeholder"
+)
+
+// List of all error policies.
+var ErrorPolicies = []ErrorPolicy{
+	PolicyFailFast,
+	PolicySkipInvalid,
+	PolicyPlaceholder,
+}
+
+// Converts a string to an ErrorPolicy, matching is case insensitive.
+// Empty string is converted to the default fail-fast policy.
+func ErrorPolicyFromString(s string) (ErrorPolicy, error) {
+	normalized := strings.ToLower(strings.TrimSpace(s))
+	if normalized == "" {
+		return PolicyFailFast, nil
+	}
+	for _, policy := range ErrorPolicies {
+		if string(policy) == normalized {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown error policy %q, expected fail-fast, skip-invalid or placeholder", s)
+}
+
+// Implements the json.Unmarshaler interface for ErrorPolicy.
+// Accepts the same values as ErrorPolicyFromString.
+func (p *ErrorPolicy) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	policy, err := ErrorPolicyFromString(s)
+	if err != nil {
+		return err
+	}
+	*p = policy
+	return nil
+}
+
+// Record whose barcode could not be generated.
+type SkippedRecord struct {
+	Record Record
+	Err    error
+}
+
+// Writes skipped records as CSV with header to the writer.
+func WriteSkippedReport(w io.Writer, skipped []SkippedRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"row", "text", "ean", "symbology", "error", "sheet"}); err != nil {
+		return err
+	}
+	for _, s := range skipped {
+		err := writer.Write([]string{
+			strconv.Itoa(s.Record.Row),
+			s.Record.Text,
+			s.Record.Ean,
+			string(s.Record.Symbology),
+			s.Err.Error(),
+			s.Record.Sheet,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Writes skipped records as CSV to the file at path.
+func SaveSkippedReport(path string, skipped []SkippedRecord) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return WriteSkippedReport(file, skipped)
+}
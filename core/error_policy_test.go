@@ -0,0 +1,71 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrorPolicyFromString(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ErrorPolicy
+		wantErr bool
+	}{
+		{value: "", want: PolicyFailFast},
+		{value: "fail-fast", want: PolicyFailFast},
+		{value: " Skip-Invalid ", want: PolicySkipInvalid},
+		{value: "PLACEHOLDER", want: PolicyPlaceholder},
+		{value: "ignore", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ErrorPolicyFromString(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ErrorPolicyFromString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ErrorPolicyFromString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorPolicy_UnmarshalJSON(t *testing.T) {
+	var p ErrorPolicy
+	if err := json.Unmarshal([]byte(`"skip-invalid"`), &p); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	if p != PolicySkipInvalid {
+		t.Errorf("Unmarshal() = %v, want %v", p, PolicySkipInvalid)
+	}
+	if err := json.Unmarshal([]byte(`"ignore"`), &p); err == nil {
+		t.Error("Unmarshal() should fail for unknown policy")
+	}
+}
+
+func TestWriteSkippedReport(t *testing.T) {
+	skipped := []SkippedRecord{
+		{Record: Record{Text: "Product, A", Ean: "123", Row: 4}, Err: errors.New("invalid ean code data")},
+		{Record: Record{Text: "B", Ean: "x", Row: 7, Symbology: SymbologyITF14}, Err: errors.New("bad")},
+	}
+	var buf bytes.Buffer
+	if err := WriteSkippedReport(&buf, skipped); err != nil {
+		t.Fatalf("WriteSkippedReport() failed: %v", err)
+	}
+	table, err := TableFromCsv(strings.NewReader(buf.String()), ',')
+	if err != nil {
+		t.Fatalf("Report is not valid CSV: %v", err)
+	}
+	if len(table) != 3 {
+		t.Fatalf("Report has %d rows, want 3", len(table))
+	}
+	if table[1][0] != "4" || table[1][1] != "Product, A" || table[1][4] != "invalid ean code data" {
+		t.Errorf("Report row = %v", table[1])
+	}
+	if table[2][3] != "itf14" {
+		t.Errorf("Report symbology = %v, want itf14", table[2][3])
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type Comma rune
@@ -51,41 +53,241 @@ func (r *Comma) UnmarshalJSON(data []byte) error {
 }
 
 type Generator struct {
-	CsvPath      string `json:"csv_path"`
-	PdfPath      string `json:"pdf_path"`
-	CsvComma     Comma  `json:"csv_comma"`
-	TextHeader   string `json:"text_header"`
-	EanHeader    string `json:"ean_header"`
-	TimesHeader  string `json:"times_header"`
-	TimesEachEAN uint   `json:"times_each_ean"`
+	// Paths of the input and the PDF, StdStream for the standard input and output.
+	// PDF path is a template with placeholders, see ExpandOutputPath.
+	CsvPath string `json:"csv_path"`
+	PdfPath string `json:"pdf_path"`
+	// Path the PDF is saved to resolved by OutputPath, it is not expanded
+	// again. PDF path is used if it is empty.
+	OutputFile string `json:"-"`
+	// What to do if the PDF already exists, the PDF is overwritten by default.
+	OverwritePolicy OverwritePolicy `json:"overwrite_policy,omitempty"`
+	CsvComma        Comma           `json:"csv_comma"`
+	// Format of the input, given by the extension or detected from the content if empty.
+	InputFormat InputFormat `json:"input_format,omitempty"`
+	// Encoding of CSV input, detected from the content when empty.
+	CsvEncoding     TextEncoding `json:"csv_encoding,omitempty"`
+	TextHeader      string       `json:"text_header"`
+	EanHeader       string       `json:"ean_header"`
+	TimesHeader     string       `json:"times_header"`
+	TimesEachEAN    uint         `json:"times_each_ean"`
+	Symbology       Symbology    `json:"symbology"`
+	SymbologyHeader string       `json:"symbology_header"`
+	// Number of first rows searched for the header row, DefaultHeaderSearchRows if zero.
+	HeaderSearchRows int `json:"header_search_rows,omitempty"`
+	// Templates of texts printed in the label slots.
+	Templates LabelTemplates `json:"templates"`
+	// Optional price and quantity columns, price is drawn to the price box of the layout.
+	PriceHeader    string      `json:"price_header,omitempty"`
+	QuantityHeader string      `json:"quantity_header,omitempty"`
+	PriceFormat    PriceFormat `json:"price_format"`
+	// Optional column with EAN-2 or EAN-5 add-ons, add-ons can be also
+	// written after the code separated by space ("9771234567003 05").
+	AddOnHeader string `json:"add_on_header,omitempty"`
+	// Convert ISBN-10 codes of the ISBN symbology to ISBN-13.
+	ConvertISBN10 bool `json:"convert_isbn10,omitempty"`
+	// Column headers of GS1 Application Identifiers by the AI, barcodes
+	// of GS1-128 or GS1 DataMatrix symbology are built from them.
+	GS1Columns map[string]string `json:"gs1_columns,omitempty"`
+	// Label geometry, default layout is used when not set.
+	Layout *Layout `json:"layout,omitempty"`
+	// Module width in mm overriding the one of the layout when positive.
+	ModuleWidth float64 `json:"module_width,omitempty"`
+	// How barcodes are drawn, vector rectangles by default.
+	BarcodeRenderer BarcodeRenderer `json:"barcode_renderer,omitempty"`
+	// Draw EAN and UPC codes with light margins, guard bars and digits under bars.
+	GS1Rendering bool `json:"gs1_rendering,omitempty"`
+	// Magnification of GS1 symbols (1 is 0.33 mm module), module width is used when zero.
+	Magnification float64 `json:"magnification,omitempty"`
+	// Serial numbers of label copies, Last is updated after each generated PDF.
+	Serial SerialNumbers `json:"serial"`
+	// Sheet to tile labels on, each label is on its own page when not set.
+	LabelSheet *LabelSheet `json:"label_sheet,omitempty"`
+	// One based position of the first label on the first sheet.
+	StartPosition int `json:"start_position,omitempty"`
+	// Compute missing check digit of 7, 11, 12 and 13 digit GS1 codes.
+	CompleteCheckDigits bool `json:"complete_check_digits"`
+	// What to do with records whose barcode cannot be generated.
+	ErrorPolicy ErrorPolicy `json:"error_policy"`
+	// Path to CSV report of skipped records, no report is written if empty.
+	SkippedReportPath string `json:"skipped_report_path,omitempty"`
+	// Path to CSV or XLSX report of every label copy, no report is written if empty.
+	ReportPath string `json:"report_path,omitempty"`
+	// Excel sheet given by name or one based position, the first sheet when empty.
+	Sheet string `json:"sheet,omitempty"`
+	// Read records from all Excel sheets instead of the selected one.
+	MergeSheets bool `json:"merge_sheets,omitempty"`
 }
 
 // Validate checks if the generator configuration is valid.
-// Verifies that CSV input file has .csv extension and PDF
-// output file has .pdf extension.
+// Verifies that input file has extension of supported format unless the
+// input format is set, and the output file, or the PDF path template if
+// it is not resolved, has .pdf extension. Standard input and output are
+// valid paths.
 func (g *Generator) Validate() error {
-	{
-		ext := strings.ToLower(filepath.Ext(g.CsvPath))
-		if ext != ".csv" && ext != ".xlsx" {
-			return fmt.Errorf("Error: Input file must have a .csv or .xlsx extension")
+	if g.InputFormat != "" {
+		if _, err := InputFormatFromString(string(g.InputFormat)); err != nil {
+			return err
+		}
+	} else if g.CsvPath != StdStream {
+		// Standard input has no extension, its format is detected.
+		format, err := InputFormatFromFilename(g.CsvPath)
+		if err != nil {
+			return err
+		}
+		if format == "" {
+			return fmt.Errorf("Error: Input file must have a %s extension", supportedExtensions)
 		}
 	}
-	{
-		ext := filepath.Ext(g.PdfPath)
-		if strings.ToLower(ext) != ".pdf" {
+	if g.OutputFile != "" {
+		// Resolved path is not a template.
+		if g.OutputFile != StdStream && strings.ToLower(filepath.Ext(g.OutputFile)) != ".pdf" {
+			return errors.New("Error: Input file must have a .pdf extension")
+		}
+	} else if g.PdfPath != StdStream {
+		// Placeholders are checked with example values, directories get PDF named by the input.
+		path, err := ExpandOutputPath(g.PdfPath, OutputPathValues{Input: "input.csv", Time: time.Now()})
+		if err != nil {
+			return err
+		}
+		if !isOutputDir(path) && strings.ToLower(filepath.Ext(path)) != ".pdf" {
 			return errors.New("Error: Input file must have a .pdf extension")
 		}
 	}
+	if _, err := OverwritePolicyFromString(string(g.OverwritePolicy)); err != nil {
+		return err
+	}
+	if _, err := SymbologyFromString(string(g.Symbology)); err != nil {
+		return err
+	}
+	if _, err := ErrorPolicyFromString(string(g.ErrorPolicy)); err != nil {
+		return err
+	}
+	if g.ReportPath != "" {
+		if err := validateReportPath(g.ReportPath); err != nil {
+			return err
+		}
+	}
+	if err := ValidateGS1Columns(g.GS1Columns); err != nil {
+		return err
+	}
+	if symbology, _ := SymbologyFromString(string(g.Symbology)); len(g.GS1Columns) > 0 && !symbology.IsGS1() {
+		return fmt.Errorf("GS1 columns need %s or %s symbology, got %s", SymbologyGS1128, SymbologyGS1DataMatrix, symbology)
+	}
+	if _, err := TextEncodingFromString(string(g.CsvEncoding)); err != nil {
+		return err
+	}
+	if err := g.Templates.Validate(); err != nil {
+		return err
+	}
+	if _, err := BarcodeRendererFromString(string(g.BarcodeRenderer)); err != nil {
+		return err
+	}
+	if g.ModuleWidth < 0 {
+		return fmt.Errorf("Module width cannot be negative, got %g", g.ModuleWidth)
+	}
+	if err := validateMagnification(g.Magnification); err != nil {
+		return err
+	}
+	if g.GS1Rendering && g.BarcodeRenderer == RendererPng {
+		return errors.New("GS1 rendering needs the vector barcode renderer")
+	}
+	if err := g.PriceFormat.Validate(); err != nil {
+		return err
+	}
+	if err := g.Serial.Validate(); err != nil {
+		return err
+	}
+	if strings.TrimSpace(g.Templates.Side) != "" && g.GetLayout().Side.Empty() {
+		return errors.New("Side template is set, but the layout has no side box")
+	}
+	if g.HeaderSearchRows < 0 {
+		return fmt.Errorf("Header search rows cannot be negative, got %d", g.HeaderSearchRows)
+	}
+	if g.Layout != nil {
+		if err := g.Layout.Validate(); err != nil {
+			return err
+		}
+	}
+	if g.LabelSheet != nil {
+		if err := g.LabelSheet.Validate(); err != nil {
+			return err
+		}
+		if g.StartPosition < 0 || g.StartPosition > g.LabelSheet.PerPage() {
+			return fmt.Errorf("Start position must be between 1 and %d, got %d", g.LabelSheet.PerPage(), g.StartPosition)
+		}
+	}
 	return nil
 }
 
+// Returns the configured label layout. If it is not set, the default
+// layout scaled to labels of the label sheet (if any) is returned.
+// Module width of the generator overrides the one of the layout.
+func (g *Generator) GetLayout() Layout {
+	var layout Layout
+	switch {
+	case g.Layout != nil:
+		layout = *g.Layout
+	case g.LabelSheet != nil:
+		layout = NewLayout(g.LabelSheet.LabelWidth, g.LabelSheet.LabelHeight)
+	default:
+		layout = DefaultLayout()
+	}
+	if g.ModuleWidth > 0 {
+		layout.ModuleWidth = g.ModuleWidth
+	}
+	return layout
+}
+
+// Creates an empty PDF document configured by the generator layout,
+// label sheet, templates, price format, serial numbers and barcode renderer.
+func (g *Generator) NewPdf() (Pdf, error) {
+	var pdf Pdf
+	var err error
+	if g.LabelSheet == nil {
+		pdf, err = NewPdfWithLayout(g.GetLayout())
+	} else {
+		pdf, err = NewPdfOnSheet(g.GetLayout(), *g.LabelSheet, max(1, g.StartPosition))
+	}
+	if err != nil {
+		return Pdf{}, err
+	}
+	if err := pdf.SetBarcodeRenderer(g.BarcodeRenderer); err != nil {
+		return Pdf{}, err
+	}
+	if err := pdf.SetGS1Rendering(g.GS1Rendering, g.Magnification); err != nil {
+		return Pdf{}, err
+	}
+	if err := pdf.SetPriceFormat(g.PriceFormat); err != nil {
+		return Pdf{}, err
+	}
+	if err := pdf.SetSerialNumbers(g.Serial); err != nil {
+		return Pdf{}, err
+	}
+	return pdf, pdf.SetTemplates(g.Templates)
+}
+
 // Sets the PDF output path if it's not already configured.
-// Generates a PDF path based on the CSV input path by changing the extension.
+// Generates a PDF path based on the CSV input path by changing the extension,
+// braces of the name are escaped, so the path is not expanded as a template.
+// PDF of the standard input is written to the standard output.
 func (g *Generator) UpdatePdfPath() {
 	if g.PdfPath != "" {
 		return
 	}
-	g.PdfPath = GeneratePdfPath(g.CsvPath)
+	if g.CsvPath == StdStream {
+		g.PdfPath = StdStream
+		return
+	}
+	g.PdfPath = escapeOutputPath(GeneratePdfPath(g.CsvPath))
+}
+
+// Returns the path the PDF is saved to, the output file if it is resolved.
+func (g *Generator) outputFile() string {
+	if g.OutputFile != "" {
+		return g.OutputFile
+	}
+	return g.PdfPath
 }
 
 // Creates a PDF filename from an input file path.
@@ -96,58 +298,255 @@ func GeneratePdfPath(path string) string {
 }
 
 // Reads and deserializes a generator configuration from a JSON file.
+// Older versions of the configuration are migrated, unknown and invalid
+// values are reported with their JSON path, see DecodeGenerator.
 // Returns a pointer to the loaded Generator or an error if loading fails.
 func LoadGenerator(path string, log *slog.Logger) (*Generator, error) {
-	file, err := os.Open(path)
+	content, err := os.ReadFile(path)
 	if err != nil {
 		err = errors.Join(errors.New("Cannot load generator"), err)
 		log.Error("Failed to open generator file", "err", err)
 		return nil, err
 	}
-	defer file.Close()
 
-	var g Generator
-	if err := json.NewDecoder(file).Decode(&g); err != nil {
+	g, err := DecodeGenerator(content)
+	if err != nil {
 		log.Error("Failed to decode generator file", "err", err)
-		return nil, err
+		return nil, errors.Join(fmt.Errorf("Invalid configuration '%s'", path), err)
 	}
 	log.Info("Generator loaded", "generator", g)
-	return &g, nil
+	return g, nil
 }
 
 // Save serializes and writes the generator configuration to a JSON file.
-// Creates the file with proper indentation for readability.
+// Creates the file with proper indentation for readability and the
+// version of the configuration schema.
 func (g *Generator) Save(path string) error {
-	file, err := os.Create(path)
+	content, err := g.MarshalConfig()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	return os.WriteFile(path, content, 0o644)
+}
+
+// Returns the table columns configured in the generator.
+func (g *Generator) Columns() Columns {
+	return Columns{
+		Text:      g.TextHeader,
+		Ean:       g.EanHeader,
+		Times:     g.TimesHeader,
+		Symbology: g.SymbologyHeader,
+		Price:     g.PriceHeader,
+		Quantity:  g.QuantityHeader,
+		AddOn:     g.AddOnHeader,
+		GS1:       g.GS1Columns,
+
+		QuantityUnit:     g.PriceFormat.QuantityUnit,
+		HeaderSearchRows: g.HeaderSearchRows,
+	}
+}
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(g)
+// Extracts records from the table using the generator columns.
+// Rows without own symbology get the generator one. Add-on suffixes
+// of EAN, UPC-A and ISBN codes are split from them. Text column
+// can be omitted only if the top template is set.
+func (g *Generator) Records(table Table) ([]Record, error) {
+	if g.TextHeader == "" && strings.TrimSpace(g.Templates.Top) == "" {
+		return nil, errors.New("Text column header cannot be empty")
+	}
+	records, err := RecordsFromColumns(table, g.Columns())
+	if err != nil {
+		return nil, err
+	}
+	g.completeRecords(records)
+	return records, nil
+}
+
+// Sets the configured symbology to records without one and splits
+// the add-on from EAN and UPC codes that have no add-on set.
+func (g *Generator) completeRecords(records []Record) {
+	symbology, _ := SymbologyFromString(string(g.Symbology))
+	for i := range records {
+		if records[i].Symbology == "" {
+			records[i].Symbology = symbology
+		}
+		if records[i].AddOn == "" && records[i].Symbology.isEanUpc() {
+			records[i].Ean, records[i].AddOn = splitAddOn(records[i].Ean)
+		}
+	}
+}
+
+// Extracts records from every table as Records does and concatenates them.
+// Records remember the sheet they come from. Empty sheets are skipped
+// when there are more tables, so merged workbooks may contain them.
+func (g *Generator) RecordsFromTables(tables []SheetTable) ([]Record, error) {
+	records := []Record{}
+	for _, table := range tables {
+		if len(tables) > 1 && len(table.Table) == 0 {
+			continue
+		}
+		sheetRecords, err := g.Records(table.Table)
+		if err != nil {
+			if table.Sheet != "" {
+				return nil, fmt.Errorf("Sheet '%s': %w", table.Sheet, err)
+			}
+			return nil, err
+		}
+		for i := range sheetRecords {
+			sheetRecords[i].Sheet = table.Sheet
+		}
+		records = append(records, sheetRecords...)
+	}
+	return records, nil
+}
+
+// Validates every record of the table without generating anything.
+// Returns an error only if records cannot be extracted from the table.
+func (g *Generator) ValidateTable(table Table) (ValidationReport, error) {
+	return g.ValidateTables([]SheetTable{{Table: table}})
+}
+
+// Validates every record of all tables without generating anything.
+// Returns an error only if records cannot be extracted from the tables.
+func (g *Generator) ValidateTables(tables []SheetTable) (ValidationReport, error) {
+	records, err := g.RecordsFromTables(tables)
+	if err != nil {
+		return ValidationReport{}, err
+	}
+	return ValidateRecordsWithOptions(records, g.validationOptions()), nil
+}
+
+// Returns options of record validation configured in the generator.
+func (g *Generator) validationOptions() ValidationOptions {
+	return ValidationOptions{CompleteCheckDigits: g.CompleteCheckDigits, ConvertISBN10: g.ConvertISBN10}
 }
 
 // Generator must be valid
 func (g *Generator) GenerateFromTable(table Table, log *slog.Logger) error {
-	records, err := RecordsFromTable(table, g.TextHeader, g.EanHeader, g.TimesHeader)
+	return g.GenerateFromTables([]SheetTable{{Table: table}}, log)
+}
+
+// Generates the PDF from records of all tables, generator must be valid.
+func (g *Generator) GenerateFromTables(tables []SheetTable, log *slog.Logger) error {
+	return g.generateFromTables(tables, nil, log)
+}
+
+// Generates the PDF from records of all tables and writes it to w,
+// or saves it to the PDF path if w is nil. Generator must be valid.
+func (g *Generator) generateFromTables(tables []SheetTable, w io.Writer, log *slog.Logger) error {
+	records, err := g.RecordsFromTables(tables)
 	if err != nil {
 		log.Error("Failed to get records from table", "err", err)
 		return err
 	}
-	log.Debug("Records in table", "records", records)
-	pdf := NewPdf()
-	pdf.AddPages(records, g.TimesEachEAN, log)
-	err = pdf.Save(g.PdfPath)
+	return g.generateFromRecords(records, w, log)
+}
+
+// Generates the PDF from the records and writes it to w, or saves it
+// to the output file if w is nil. Missing directories of the output
+// file are created just before it is saved. Reports are saved after the PDF.
+func (g *Generator) generateFromRecords(records []Record, w io.Writer, log *slog.Logger) error {
+	policy, _ := ErrorPolicyFromString(string(g.ErrorPolicy))
+	pdf, skipped, err := g.addPages(records, policy, log)
+	if err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		rows := []int{}
+		for _, s := range skipped {
+			rows = append(rows, s.Record.Row)
+		}
+		log.Warn("Some records have invalid barcode", "policy", policy, "count", len(skipped), "rows", rows)
+	}
+	if g.SkippedReportPath != "" {
+		if err := SaveSkippedReport(g.SkippedReportPath, skipped); err != nil {
+			log.Error("Failed to save skipped report", "err", err)
+			return err
+		}
+	}
+	if w != nil {
+		err = pdf.Write(w)
+	} else if err = os.MkdirAll(filepath.Dir(g.outputFile()), 0o755); err != nil {
+		err = errors.Join(errors.New("Cannot create output directory"), err)
+	} else {
+		err = pdf.Save(g.outputFile())
+	}
 	if err != nil {
 		log.Error("Failed to save pdf file", "err", err)
 		return err
 	}
+	// Serial numbers are issued only when the document is saved.
+	g.Serial = pdf.SerialNumbers()
+	if g.ReportPath != "" {
+		if err := SaveReport(g.ReportPath, pdf.Report()); err != nil {
+			log.Error("Failed to save report", "err", err)
+			return err
+		}
+	}
 	return nil
 }
 
+// Creates the PDF and adds labels of the records with the policy.
+// Missing check digits are completed and ISBN-10 converted first
+// if the generator is configured to.
+func (g *Generator) addPages(records []Record, policy ErrorPolicy, log *slog.Logger) (Pdf, []SkippedRecord, error) {
+	if g.CompleteCheckDigits || g.ConvertISBN10 {
+		report := ValidateRecordsWithOptions(records, g.validationOptions())
+		for i, row := range report.Rows {
+			// Duplicates of completed or converted codes are changed as well.
+			if row.Code != "" && row.Code != row.Ean {
+				log.Info("Code changed", "row", row.Row, "ean", row.Ean, "code", row.Code, "status", row.Status)
+				records[i].Ean = row.Code
+			}
+		}
+	}
+	log.Debug("Records in table", "records", records)
+	pdf, err := g.NewPdf()
+	if err != nil {
+		log.Error("Failed to create pdf", "err", err)
+		return Pdf{}, nil, err
+	}
+	skipped, err := pdf.AddPagesWithPolicy(records, g.TimesEachEAN, policy, log)
+	if err != nil {
+		log.Error("Failed to add pages", "err", err)
+		return Pdf{}, nil, err
+	}
+	return pdf, skipped, nil
+}
+
+// Renders at most limit first labels of the input without saving anything,
+// labels whose barcode cannot be generated are marked as invalid. Returns
+// the labels with their texts, page and status. Generator must be valid.
+// Returns an error if the limit is negative.
+func (g *Generator) Preview(filename string, content io.ReadSeeker, limit int, log *slog.Logger) ([]ReportEntry, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("Preview limit cannot be negative, got %d", limit)
+	}
+	tables, err := g.ReadTables(filename, content)
+	if err != nil {
+		return nil, err
+	}
+	records, err := g.RecordsFromTables(tables)
+	if err != nil {
+		return nil, err
+	}
+	// Every record has at least one label, unless its times are zero.
+	records = records[:min(len(records), limit)]
+	pdf, _, err := g.addPages(records, PolicyPlaceholder, log)
+	if err != nil {
+		return nil, err
+	}
+	report := pdf.Report()
+	return report[:min(len(report), limit)], nil
+}
+
 func (g *Generator) Generate(filename string, content io.ReadSeeker, log *slog.Logger) error {
+	return g.GenerateToWriter(filename, content, nil, log)
+}
+
+// Generates the PDF as Generate does, but writes it to w instead
+// of the PDF path. PDF is saved to the path if w is nil.
+func (g *Generator) GenerateToWriter(filename string, content io.ReadSeeker, w io.Writer, log *slog.Logger) error {
 	log.Debug("Try to generate pdf", "filename", filename, "generator", *g)
 	err := g.Validate()
 	if err != nil {
@@ -156,19 +555,82 @@ func (g *Generator) Generate(filename string, content io.ReadSeeker, log *slog.L
 	}
 	log.Info("Generator is valid")
 
-	var table Table
-	switch strings.ToLower(filepath.Ext(filename)) {
-	case "csv":
-		table, err = TableFromCsv(content, rune(g.CsvComma))
-	case "xlsx":
-		table, err = TableFromExcel(content, 0)
+	tables, err := g.ReadTables(filename, content)
+	if err != nil {
+		log.Error("Failed to read table", "err", err)
+		return err
+	}
+	log.Debug("Tables to generate pdf", "tables", tables)
+	return g.generateFromTables(tables, w, log)
+}
+
+// Generates the PDF from the records, e.g. decoded from JSON, and writes
+// it to w as GenerateToWriter does. Records without symbology get
+// the configured one, the input path and format are not used.
+func (g *Generator) GenerateRecordsToWriter(records []Record, w io.Writer, log *slog.Logger) error {
+	log.Debug("Try to generate pdf from records", "count", len(records), "generator", *g)
+	valid := *g
+	valid.CsvPath, valid.InputFormat = StdStream, ""
+	if err := valid.Validate(); err != nil {
+		log.Error("Generator is invalid", "err", err)
+		return err
+	}
+	if len(records) == 0 {
+		return errors.New("No records to generate")
+	}
+	g.completeRecords(records)
+	return g.generateFromRecords(records, w, log)
+}
+
+// Reads the input tables, format is chosen by the file name extension
+// or detected from the content for unknown extensions. CSV and TSV input
+// is a single table, workbook input is the selected sheet or all of them
+// when sheets are merged.
+func (g *Generator) ReadTables(filename string, content io.ReadSeeker) ([]SheetTable, error) {
+	format, err := g.inputFormat(filename, content)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatTsv:
+		return g.readCsv(content, '\t')
+	case FormatXlsx:
+		return SheetTablesFromExcel(content, g.Sheet, g.MergeSheets)
+	case FormatXls:
+		return SheetTablesFromXls(content, g.Sheet, g.MergeSheets)
+	case FormatOds:
+		return SheetTablesFromOds(content, g.Sheet, g.MergeSheets)
 	default:
-		table, err = TableFromCsv(content, rune(g.CsvComma))
-		if err != nil {
-			content.Seek(0, io.SeekStart)
-			table, err = TableFromExcel(content, 0)
-		}
+		return g.readCsv(content, rune(g.CsvComma))
+	}
+}
+
+// Returns the configured input format, or the one given by the file name
+// extension or detected from the content if it is not configured.
+func (g *Generator) inputFormat(filename string, content io.ReadSeeker) (InputFormat, error) {
+	if g.InputFormat != "" {
+		return InputFormatFromString(string(g.InputFormat))
+	}
+	return DetectInputFormat(filename, content)
+}
+
+// Reads delimited text input, separator, quote character and encoding
+// that are not configured are detected from the content.
+func (g *Generator) readCsv(content io.Reader, comma rune) ([]SheetTable, error) {
+	encoding, _ := TextEncodingFromString(string(g.CsvEncoding))
+	table, dialect, err := TableFromCsvDialect(content, CsvDialect{Comma: comma, Encoding: encoding})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Dialect: separator %q, quote %q, encoding %s, BOM %t", dialect.Comma, dialect.Quote, dialect.Encoding, dialect.BOM)
+	return []SheetTable{{Table: table}}, nil
+}
+
+// Reads the input tables and validates every record without generating anything.
+func (g *Generator) ValidateInput(filename string, content io.ReadSeeker) (ValidationReport, error) {
+	tables, err := g.ReadTables(filename, content)
+	if err != nil {
+		return ValidationReport{}, err
 	}
-	log.Debug("Table to generate pdf", "table", table)
-	return g.GenerateFromTable(table, log)
+	return g.ValidateTables(tables)
 }
@@ -1,10 +1,17 @@
 package core
 
 import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
 func TestGenerator_Validate(t *testing.T) {
+	sheet3x8 := LabelSheetPresets["a4-3x8"]
 	tests := []struct {
 		name    string // description of this test case
 		gen     Generator
@@ -13,6 +20,47 @@ func TestGenerator_Validate(t *testing.T) {
 		{name: "Valid suffixes", gen: Generator{CsvPath: "a.csv", PdfPath: "a.pdf"}, wantErr: false},
 		{name: "Invalid csv", gen: Generator{CsvPath: "a.txt", PdfPath: "a.pdf"}, wantErr: true},
 		{name: "Invalid pdf", gen: Generator{CsvPath: "a.csv", PdfPath: "a.txt"}, wantErr: true},
+		{name: "Standard streams", gen: Generator{CsvPath: StdStream, PdfPath: StdStream}, wantErr: false},
+		{name: "Pdf template", gen: Generator{CsvPath: "a.csv", PdfPath: "out/{input}_{date:2006-01-02}.pdf"}, wantErr: false},
+		{name: "Pdf directory", gen: Generator{CsvPath: "a.csv", PdfPath: "out/"}, wantErr: false},
+		{name: "Invalid pdf template", gen: Generator{CsvPath: "a.csv", PdfPath: "{name}.pdf"}, wantErr: true},
+		{name: "Invalid overwrite policy", gen: Generator{CsvPath: "a.csv", PdfPath: "a.pdf", OverwritePolicy: "skip"}, wantErr: true},
+		{name: "Input format", gen: Generator{CsvPath: "a.txt", PdfPath: "a.pdf", InputFormat: FormatTsv}, wantErr: false},
+		{name: "Invalid input format", gen: Generator{CsvPath: "a.csv", PdfPath: "a.pdf", InputFormat: "xlsm"}, wantErr: true},
+		{name: "Valid symbology", gen: Generator{CsvPath: "a.csv", PdfPath: "a.pdf", Symbology: SymbologyQR}, wantErr: false},
+		{name: "Invalid symbology", gen: Generator{CsvPath: "a.csv", PdfPath: "a.pdf", Symbology: "pdf417"}, wantErr: true},
+		{name: "Invalid layout", gen: Generator{CsvPath: "a.csv", PdfPath: "a.pdf", Layout: &Layout{}}, wantErr: true},
+		{name: "Invalid label sheet", gen: Generator{CsvPath: "a.csv", PdfPath: "a.pdf", LabelSheet: &LabelSheet{}}, wantErr: true},
+		{
+			name:    "Invalid template",
+			gen:     Generator{CsvPath: "a.csv", PdfPath: "a.pdf", Templates: LabelTemplates{Top: "{{.Name"}},
+			wantErr: true,
+		},
+		{
+			name:    "Side template without side box",
+			gen:     Generator{CsvPath: "a.csv", PdfPath: "a.pdf", Templates: LabelTemplates{Side: "{{.Name}}"}},
+			wantErr: true,
+		},
+		{
+			name:    "GS1 columns",
+			gen:     Generator{CsvPath: "a.csv", PdfPath: "a.pdf", Symbology: SymbologyGS1128, GS1Columns: map[string]string{"10": "Batch"}},
+			wantErr: false,
+		},
+		{
+			name:    "GS1 columns with EAN symbology",
+			gen:     Generator{CsvPath: "a.csv", PdfPath: "a.pdf", GS1Columns: map[string]string{"10": "Batch"}},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported GS1 AI",
+			gen:     Generator{CsvPath: "a.csv", PdfPath: "a.pdf", Symbology: SymbologyGS1128, GS1Columns: map[string]string{"99": "Other"}},
+			wantErr: true,
+		},
+		{
+			name:    "Start position after sheet",
+			gen:     Generator{CsvPath: "a.csv", PdfPath: "a.pdf", LabelSheet: &sheet3x8, StartPosition: 25},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -42,6 +90,8 @@ func TestGenerator_UpdatePdfPath(t *testing.T) {
 			gen:  Generator{CsvPath: "data.csv", PdfPath: "different.pdf"},
 			want: "different.pdf",
 		},
+		{name: "Standard input", gen: Generator{CsvPath: StdStream}, want: StdStream},
+		{name: "Braces in csv name", gen: Generator{CsvPath: "data{1}.csv"}, want: "data{{1}}.pdf"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -71,3 +121,292 @@ func TestGeneratePdfPath(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerator_GetLayout(t *testing.T) {
+	custom := NewLayout(50, 30)
+	sheet := LabelSheetPresets["a4-4x10"]
+	tests := []struct {
+		name       string
+		gen        Generator
+		wantWidth  float64
+		wantHeight float64
+	}{
+		{name: "Default", gen: Generator{}, wantWidth: 30, wantHeight: 15},
+		{name: "Custom layout", gen: Generator{Layout: &custom}, wantWidth: 50, wantHeight: 30},
+		{name: "Label sheet", gen: Generator{LabelSheet: &sheet}, wantWidth: 52.5, wantHeight: 29.7},
+		{name: "Layout wins over sheet", gen: Generator{Layout: &custom, LabelSheet: &sheet}, wantWidth: 50, wantHeight: 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.gen.GetLayout()
+			if got.PageWidth != tt.wantWidth || got.PageHeight != tt.wantHeight {
+				t.Errorf("GetLayout() = %gx%g, want %gx%g", got.PageWidth, got.PageHeight, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestGenerator_ValidateInput(t *testing.T) {
+	csv := "Text,EAN\nA,5901234123457\nB,590123412345\nC,\nD,5901234123458"
+	gen := Generator{TextHeader: "Text", EanHeader: "EAN", CompleteCheckDigits: true}
+
+	report, err := gen.ValidateInput("data.csv", strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ValidateInput() failed: %v", err)
+	}
+	// Row with empty EAN is skipped.
+	if len(report.Rows) != 3 {
+		t.Fatalf("len(Rows) = %d, want 3", len(report.Rows))
+	}
+	if report.Rows[1].Row != 3 || report.Rows[1].Status != RowDuplicate || report.Rows[1].Code != "5901234123457" {
+		t.Errorf("Rows[1] = %+v, want completed duplicate on row 3", report.Rows[1])
+	}
+	if report.Rows[2].Row != 5 || report.Rows[2].Status != RowWrongChecksum {
+		t.Errorf("Rows[2] = %+v, want wrong checksum on row 5", report.Rows[2])
+	}
+
+	gen.EanHeader = "Code"
+	if _, err := gen.ValidateInput("data.csv", strings.NewReader(csv)); err == nil {
+		t.Error("ValidateInput() should fail for missing header")
+	}
+}
+
+func TestGenerator_GenerateFromTable_ErrorPolicy(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "generator-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	table := Table{
+		{"Text", "EAN"},
+		{"A", "5901234123457"},
+		{"B", "5901234123458"},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	gen := Generator{
+		PdfPath:      filepath.Join(tmpDir, "fail.pdf"),
+		TextHeader:   "Text",
+		EanHeader:    "EAN",
+		TimesEachEAN: 1,
+	}
+	if err := gen.GenerateFromTable(table, log); err == nil {
+		t.Error("GenerateFromTable() should fail with fail-fast policy")
+	}
+	if _, err := os.Stat(gen.PdfPath); !os.IsNotExist(err) {
+		t.Error("Partial pdf should not be saved with fail-fast policy")
+	}
+
+	gen.PdfPath = filepath.Join(tmpDir, "skip.pdf")
+	gen.ErrorPolicy = PolicySkipInvalid
+	gen.SkippedReportPath = filepath.Join(tmpDir, "skipped.csv")
+	if err := gen.GenerateFromTable(table, log); err != nil {
+		t.Fatalf("GenerateFromTable() failed: %v", err)
+	}
+	if _, err := os.Stat(gen.PdfPath); err != nil {
+		t.Errorf("Pdf was not saved: %v", err)
+	}
+	report, err := os.ReadFile(gen.SkippedReportPath)
+	if err != nil {
+		t.Fatalf("Skipped report was not saved: %v", err)
+	}
+	if !strings.Contains(string(report), "3,B,5901234123458") {
+		t.Errorf("Skipped report = %q, want row 3", report)
+	}
+}
+
+func TestGenerator_GenerateToWriter_CompletedDuplicates(t *testing.T) {
+	tests := []struct {
+		name  string
+		gen   Generator
+		input string
+	}{
+		{name: "completed check digits", gen: Generator{CompleteCheckDigits: true}, input: "Text,EAN\nA,590123412345\nB,590123412345\n"},
+		{name: "converted ISBN-10", gen: Generator{ConvertISBN10: true, Symbology: SymbologyISBN}, input: "Text,EAN\nA,0306406152\nB,0306406152\n"},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := tt.gen
+			gen.CsvPath, gen.PdfPath, gen.InputFormat = StdStream, StdStream, FormatCsv
+			gen.TextHeader, gen.EanHeader, gen.TimesEachEAN = "Text", "EAN", 1
+			var buf bytes.Buffer
+			if err := gen.GenerateToWriter(StdStream, strings.NewReader(tt.input), &buf, log); err != nil {
+				t.Fatalf("GenerateToWriter() failed for duplicate of changed code: %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerator_ValidateInput_Sheets(t *testing.T) {
+	names := []string{"Food", "Empty", "Drinks"}
+	sheets := map[string]Table{
+		"Food":   {{"Name", "EAN"}, {"Bread", "4006381333931"}},
+		"Drinks": {{"name", "ean"}, {"Water", "4006381333932"}, {"Juice", "96385074"}},
+	}
+	gen := Generator{TextHeader: "name", EanHeader: "ean", Sheet: "Drinks"}
+
+	report, err := gen.ValidateInput("data.xlsx", excelWorkbook(t, names, sheets))
+	if err != nil {
+		t.Fatalf("ValidateInput() failed: %v", err)
+	}
+	if len(report.Rows) != 2 || report.Rows[0].Sheet != "Drinks" {
+		t.Fatalf("ValidateInput() rows = %v, want 2 rows of sheet Drinks", report.Rows)
+	}
+	if want := `Row 2 of sheet "Drinks": "4006381333932" wrong checksum`; report.Rows[0].String() != want {
+		t.Errorf("RowValidation.String() = %q, want %q", report.Rows[0].String(), want)
+	}
+
+	gen.MergeSheets = true
+	report, err = gen.ValidateInput("data.xlsx", excelWorkbook(t, names, sheets))
+	if err != nil {
+		t.Fatalf("ValidateInput() with merged sheets failed: %v", err)
+	}
+	if len(report.Rows) != 3 || report.Rows[0].Sheet != "Food" || report.Rows[2].Sheet != "Drinks" {
+		t.Errorf("ValidateInput() with merged sheets rows = %v", report.Rows)
+	}
+
+	// Merged sheet without configured headers is reported with its name.
+	sheets["Empty"] = Table{{"other"}, {"x"}}
+	_, err = gen.ValidateInput("data.xlsx", excelWorkbook(t, names, sheets))
+	if err == nil || !strings.Contains(err.Error(), "Empty") {
+		t.Errorf("ValidateInput() error = %v, want error naming sheet Empty", err)
+	}
+}
+
+func TestGenerator_ReadTables_Formats(t *testing.T) {
+	gen := Generator{}
+	tests := []struct {
+		name     string
+		filename string
+		content  io.ReadSeeker
+		want     string
+	}{
+		{name: "Sniffed CSV", filename: "data.csv", content: strings.NewReader("ean;name\n123;Milk\n"), want: "Milk"},
+		{name: "TSV", filename: "data.tsv", content: strings.NewReader("ean\tname\n123\tMilk, 1l\n"), want: "Milk, 1l"},
+		{name: "ODS", filename: "data.ods", content: odsFile(t, `<table:table table:name="S">
+			<table:table-row><table:table-cell><text:p>ean</text:p></table:table-cell><table:table-cell><text:p>name</text:p></table:table-cell></table:table-row>
+			<table:table-row><table:table-cell><text:p>123</text:p></table:table-cell><table:table-cell><text:p>Milk</text:p></table:table-cell></table:table-row>
+			</table:table>`), want: "Milk"},
+		{name: "Unknown extension", filename: "export", content: excelWorkbook(t, []string{"S"}, map[string]Table{"S": {{"ean", "name"}, {"123", "Milk"}}}), want: "Milk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := gen.ReadTables(tt.filename, tt.content)
+			if err != nil {
+				t.Fatalf("ReadTables() failed: %v", err)
+			}
+			if len(tables) != 1 || len(tables[0].Table) != 2 || tables[0].Table[1][1] != tt.want {
+				t.Errorf("ReadTables() = %v, want %q in second column", tables, tt.want)
+			}
+		})
+	}
+	tables, err := gen.ReadTables("data.xls", xlsTwoSheets(t))
+	if err != nil || len(tables) != 1 || tables[0].Table[1][1] != "5901234123457" {
+		t.Errorf("ReadTables() = %v, %v, want EAN of the first xls sheet", tables, err)
+	}
+	if _, err := gen.ReadTables("data.xls", strings.NewReader("")); err == nil {
+		t.Error("ReadTables() should fail for invalid .xls")
+	}
+}
+
+func TestGenerator_ReadTables_InputFormat(t *testing.T) {
+	// Configured format wins over the extension, content would be sniffed as CSV.
+	gen := Generator{InputFormat: FormatTsv}
+	tables, err := gen.ReadTables("data.csv", strings.NewReader("ean\tname\n123\tMilk, 1l\n"))
+	if err != nil {
+		t.Fatalf("ReadTables() failed: %v", err)
+	}
+	if len(tables) != 1 || tables[0].Table[1][1] != "Milk, 1l" {
+		t.Errorf("ReadTables() = %v, want TSV table", tables)
+	}
+}
+
+func TestGenerator_GenerateToWriter(t *testing.T) {
+	gen := Generator{
+		CsvPath:      StdStream,
+		PdfPath:      StdStream,
+		InputFormat:  FormatCsv,
+		TextHeader:   "Text",
+		EanHeader:    "EAN",
+		TimesEachEAN: 1,
+	}
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := gen.GenerateToWriter(StdStream, strings.NewReader("Text,EAN\nA,5901234123457\n"), &buf, log); err != nil {
+		t.Fatalf("GenerateToWriter() failed: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF")) {
+		t.Errorf("GenerateToWriter() wrote %q, want PDF", buf.Bytes()[:min(buf.Len(), 16)])
+	}
+}
+
+func TestGenerator_GenerateRecordsToWriter(t *testing.T) {
+	gen := Generator{PdfPath: StdStream, TimesEachEAN: 1, Symbology: SymbologyEAN}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	records := []Record{
+		{Text: "A", Ean: "5901234123457 05", Times: 2},
+		{Text: "B", Ean: "HELLO", Times: 1, Symbology: SymbologyCode128},
+	}
+	var buf bytes.Buffer
+	if err := gen.GenerateRecordsToWriter(records, &buf, log); err != nil {
+		t.Fatalf("GenerateRecordsToWriter() failed: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF")) {
+		t.Errorf("GenerateRecordsToWriter() wrote %q, want PDF", buf.Bytes()[:min(buf.Len(), 16)])
+	}
+	if records[0].Symbology != SymbologyEAN || records[0].AddOn != "05" || records[1].Symbology != SymbologyCode128 {
+		t.Errorf("GenerateRecordsToWriter() records = %+v, want completed symbology and add-on", records)
+	}
+	if err := gen.GenerateRecordsToWriter(nil, &buf, log); err == nil {
+		t.Error("GenerateRecordsToWriter() should fail without records")
+	}
+}
+
+func TestGenerator_Records_AddOn(t *testing.T) {
+	table := Table{
+		{"Name", "EAN", "Issue"},
+		{"Weekly", "9771234567003 05", ""},
+		{"Monthly", "9771234567003", "52495"},
+		{"Book", "978-0-306-40615-7 05", ""},
+	}
+	g := Generator{TextHeader: "Name", EanHeader: "EAN", AddOnHeader: "Issue"}
+	records, err := g.Records(table)
+	if err != nil {
+		t.Fatalf("Records() failed: %v", err)
+	}
+	want := [][2]string{{"9771234567003", "05"}, {"9771234567003", "52495"}, {"978-0-306-40615-7", "05"}}
+	for i, w := range want {
+		if records[i].Ean != w[0] || records[i].AddOn != w[1] {
+			t.Errorf("records[%d] = %q, %q, want %q, %q", i, records[i].Ean, records[i].AddOn, w[0], w[1])
+		}
+	}
+}
+
+func TestGenerator_Preview(t *testing.T) {
+	input := "Text,EAN\nA,5901234123457\nB,5901234123458\nC,4006381333931\n"
+	gen := Generator{
+		TextHeader:   "Text",
+		EanHeader:    "EAN",
+		TimesEachEAN: 2,
+		Templates:    LabelTemplates{Top: "{{.Text}}!"},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	labels, err := gen.Preview("data.csv", strings.NewReader(input), 3, log)
+	if err != nil {
+		t.Fatalf("Preview() failed: %v", err)
+	}
+	if len(labels) != 3 {
+		t.Fatalf("len(Preview()) = %d, want 3", len(labels))
+	}
+	if labels[0].Label.Top != "A!" || labels[1].Copy != 2 || labels[1].Status != ReportPrinted {
+		t.Errorf("Preview() = %+v, want two copies of A", labels[:2])
+	}
+	if labels[2].Record.Row != 3 || labels[2].Status != ReportInvalid || labels[2].Err == nil {
+		t.Errorf("Preview()[2] = %+v, want invalid row 3", labels[2])
+	}
+	if _, err := gen.Preview("data.csv", strings.NewReader(input), -1, log); err == nil {
+		t.Error("Preview() should fail for negative limit")
+	}
+}
@@ -0,0 +1,276 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Format of the data field of a GS1 Application Identifier.
+type gs1AI struct {
+	title string
+	// Length of fixed length data, zero for variable length data.
+	length int
+	// Maximal length of variable length data.
+	max     int
+	numeric bool
+	// Data is a date YYMMDD, day 00 means the last day of the month.
+	date bool
+	// Last digit is a GS1 check digit.
+	checkDigit bool
+}
+
+// Supported Application Identifiers of the GS1 General Specifications.
+var gs1AIs = map[string]gs1AI{
+	"00": {title: "SSCC", length: 18, numeric: true, checkDigit: true},
+	"01": {title: "GTIN", length: 14, numeric: true, checkDigit: true},
+	"02": {title: "CONTENT", length: 14, numeric: true, checkDigit: true},
+	"10": {title: "BATCH/LOT", max: 20},
+	"11": {title: "PROD DATE", length: 6, numeric: true, date: true},
+	"13": {title: "PACK DATE", length: 6, numeric: true, date: true},
+	"15": {title: "BEST BEFORE", length: 6, numeric: true, date: true},
+	"16": {title: "SELL BY", length: 6, numeric: true, date: true},
+	"17": {title: "USE BY", length: 6, numeric: true, date: true},
+	"21": {title: "SERIAL", max: 20},
+	"37": {title: "COUNT", max: 8, numeric: true},
+}
+
+// Returns the supported Application Identifiers in ascending order.
+func GS1AIs() []string {
+	ret := make([]string, 0, len(gs1AIs))
+	for ai := range gs1AIs {
+		ret = append(ret, ai)
+	}
+	slices.Sort(ret)
+	return ret
+}
+
+// Characters allowed in alphanumeric GS1 data (GS1 AI encodable character set 82).
+const gs1Charset82 = `!"%&'()*+,-./0123456789:;<=>?ABCDEFGHIJKLMNOPQRSTUVWXYZ_abcdefghijklmnopqrstuvwxyz`
+
+// Single Application Identifier with its data, e.g. AI 17 with "251231".
+type GS1Element struct {
+	AI    string
+	Value string
+}
+
+// Validate checks the data against the format of its Application Identifier.
+func (e GS1Element) Validate() error {
+	format, ok := gs1AIs[e.AI]
+	if !ok {
+		return fmt.Errorf("Unsupported GS1 Application Identifier (%s)", e.AI)
+	}
+	if format.length > 0 && len(e.Value) != format.length {
+		return fmt.Errorf("AI (%s) %s must have %d characters, got %q", e.AI, format.title, format.length, e.Value)
+	}
+	if format.length == 0 && (len(e.Value) == 0 || len(e.Value) > format.max) {
+		return fmt.Errorf("AI (%s) %s must have 1 to %d characters, got %q", e.AI, format.title, format.max, e.Value)
+	}
+	for _, c := range e.Value {
+		if format.numeric && (c < '0' || c > '9') {
+			return fmt.Errorf("AI (%s) %s must be numeric, got %q", e.AI, format.title, e.Value)
+		}
+		if !strings.ContainsRune(gs1Charset82, c) {
+			return fmt.Errorf("AI (%s) %s contains invalid character %q", e.AI, format.title, c)
+		}
+	}
+	if format.checkDigit {
+		check, _ := GS1CheckDigit(e.Value[:len(e.Value)-1])
+		if rune(e.Value[len(e.Value)-1]) != check {
+			return fmt.Errorf("AI (%s) %s has wrong check digit, got %q", e.AI, format.title, e.Value)
+		}
+	}
+	if format.date {
+		if err := validateGS1Date(e.Value); err != nil {
+			return fmt.Errorf("AI (%s) %s: %w", e.AI, format.title, err)
+		}
+	}
+	return nil
+}
+
+// Reports whether the element has fixed length data, which needs no FNC1 separator.
+func (e GS1Element) fixedLength() bool {
+	return gs1AIs[e.AI].length > 0
+}
+
+// Validates a date YYMMDD. Day 00 stands for the last day of the month.
+func validateGS1Date(date string) error {
+	var year, month, day int
+	if _, err := fmt.Sscanf(date, "%2d%2d%2d", &year, &month, &day); err != nil {
+		return fmt.Errorf("Invalid date %q, expected YYMMDD", date)
+	}
+	if month < 1 || month > 12 {
+		return fmt.Errorf("Invalid month of date %q", date)
+	}
+	// Day after the last day of the month overflows to the next month.
+	if day > 0 && time.Date(2000+year, time.Month(month), day, 0, 0, 0, 0, time.UTC).Day() != day {
+		return fmt.Errorf("Invalid day of date %q", date)
+	}
+	return nil
+}
+
+// GS1 element string, sequence of Application Identifiers with their data.
+type GS1ElementString []GS1Element
+
+// Parses human readable element string with Application Identifiers
+// in parentheses, e.g. "(01)09501101530003(17)251231(10)ABC123".
+// Data are not validated.
+func ParseGS1ElementString(s string) (GS1ElementString, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "(") {
+		return nil, fmt.Errorf("GS1 element string must start with an AI in parentheses, got %q", s)
+	}
+	ret := GS1ElementString{}
+	for s != "" {
+		ai, rest, ok := strings.Cut(s[1:], ")")
+		if !ok || !isDigits(ai) {
+			return nil, fmt.Errorf("Invalid GS1 Application Identifier in %q", s)
+		}
+		// Data continue up to the next AI in parentheses.
+		end := len(rest)
+		for i := 0; i < len(rest); i++ {
+			if rest[i] != '(' {
+				continue
+			}
+			if next, _, ok := strings.Cut(rest[i+1:], ")"); ok && gs1AIs[next] != (gs1AI{}) {
+				end = i
+				break
+			}
+		}
+		ret = append(ret, GS1Element{AI: ai, Value: rest[:end]})
+		s = rest[end:]
+	}
+	return ret, nil
+}
+
+// Validate checks every element and that no AI is repeated.
+func (e GS1ElementString) Validate() error {
+	if len(e) == 0 {
+		return errors.New("GS1 element string cannot be empty")
+	}
+	seen := map[string]bool{}
+	for _, element := range e {
+		if err := element.Validate(); err != nil {
+			return err
+		}
+		if seen[element.AI] {
+			return fmt.Errorf("AI (%s) is repeated", element.AI)
+		}
+		seen[element.AI] = true
+	}
+	return nil
+}
+
+// Returns the human readable interpretation, e.g. "(01)09501101530003(17)251231".
+func (e GS1ElementString) HumanReadable() string {
+	var ret strings.Builder
+	for _, element := range e {
+		fmt.Fprintf(&ret, "(%s)%s", element.AI, element.Value)
+	}
+	return ret.String()
+}
+
+// Returns the data of the barcode. It starts with FNC1 marking GS1 data,
+// variable length data are terminated by FNC1 unless they are the last.
+func (e GS1ElementString) encode(fnc1 rune) string {
+	var ret strings.Builder
+	ret.WriteRune(fnc1)
+	for i, element := range e {
+		ret.WriteString(element.AI)
+		ret.WriteString(element.Value)
+		if !element.fixedLength() && i < len(e)-1 {
+			ret.WriteRune(fnc1)
+		}
+	}
+	return ret.String()
+}
+
+// Parses and validates human readable element string and returns
+// the data of the barcode.
+func encodeGS1(content string, fnc1 rune) (string, error) {
+	elements, err := ParseGS1ElementString(content)
+	if err != nil {
+		return "", err
+	}
+	if err := elements.Validate(); err != nil {
+		return "", err
+	}
+	return elements.encode(fnc1), nil
+}
+
+// Builds element string from data by their Application Identifiers.
+// Empty data are skipped. Fixed length elements are placed first, so
+// fewer FNC1 separators are needed. Dates given as YYYY-MM-DD or
+// DD.MM.YYYY are converted to YYMMDD and GTIN-8, GTIN-12 and GTIN-13
+// are padded by zeros to GTIN-14. Result is not validated.
+func BuildGS1ElementString(values map[string]string) GS1ElementString {
+	ret := GS1ElementString{}
+	for ai, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		format := gs1AIs[ai]
+		if format.date {
+			value = normalizeGS1Date(value)
+		}
+		if format.checkDigit && isDigits(value) && len(value) < format.length && slices.Contains([]int{8, 12, 13}, len(value)) {
+			value = strings.Repeat("0", format.length-len(value)) + value
+		}
+		ret = append(ret, GS1Element{AI: ai, Value: value})
+	}
+	slices.SortFunc(ret, func(a GS1Element, b GS1Element) int {
+		if a.fixedLength() != b.fixedLength() {
+			if a.fixedLength() {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.AI, b.AI)
+	})
+	return ret
+}
+
+// Converts dates in common formats to YYMMDD, other values are kept.
+func normalizeGS1Date(value string) string {
+	for _, layout := range []string{"2006-01-02", "2.1.2006", "20060102"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Format("060102")
+		}
+	}
+	return value
+}
+
+// Parses mapping of Application Identifiers to column headers,
+// e.g. "01=GTIN,17=Expiry,10=Batch". Parentheses around AIs are optional.
+func ParseGS1Columns(s string) (map[string]string, error) {
+	ret := map[string]string{}
+	if strings.TrimSpace(s) == "" {
+		return ret, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		ai, header, ok := strings.Cut(item, "=")
+		ai = strings.Trim(strings.TrimSpace(ai), "()")
+		header = strings.TrimSpace(header)
+		if !ok || header == "" {
+			return nil, fmt.Errorf("GS1 column must be in format AI=HEADER, got %q", item)
+		}
+		ret[ai] = header
+	}
+	return ret, ValidateGS1Columns(ret)
+}
+
+// Validates mapping of Application Identifiers to column headers.
+func ValidateGS1Columns(columns map[string]string) error {
+	for ai, header := range columns {
+		if _, ok := gs1AIs[ai]; !ok {
+			return fmt.Errorf("Unsupported GS1 Application Identifier (%s), supported are %s", ai, strings.Join(GS1AIs(), ", "))
+		}
+		if strings.TrimSpace(header) == "" {
+			return fmt.Errorf("Column header of AI (%s) cannot be empty", ai)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/boombuler/barcode"
+)
+
+// Module width of EAN and UPC symbols at 100 % magnification in mm.
+const NominalModuleWidth = 0.33
+
+// Magnification range allowed by the GS1 General Specifications.
+const (
+	MinMagnification = 0.8
+	MaxMagnification = 2.0
+)
+
+// Height of the human readable digits and extension of guard bars below
+// the other bars, both in modules.
+const (
+	eanDigitsHeight = 10
+	eanGuardsHeight = 5
+)
+
+// Reports whether the symbology is drawn as EAN or UPC symbol by GS1 rendering.
+func (s Symbology) isEanUpc() bool {
+	return s == SymbologyEAN || s == "" || s == SymbologyUPCA || s == SymbologyISBN
+}
+
+// Validates the magnification, zero means it is not set.
+func validateMagnification(magnification float64) error {
+	if magnification != 0 && (magnification < MinMagnification || magnification > MaxMagnification) {
+		return fmt.Errorf("Magnification must be between %g and %g, got %g", MinMagnification, MaxMagnification, magnification)
+	}
+	return nil
+}
+
+// Human readable digit printed under the symbol. Position and width are in modules
+// relative to the first bar, digits outside of the symbol are printed smaller.
+type eanDigit struct {
+	digit    string
+	position int
+	width    int
+	align    string
+	small    bool
+	// Digits of add-ons are printed above their bars.
+	above bool
+}
+
+// EAN or UPC symbol with guard bars and human readable digits laid out
+// as required by the GS1 General Specifications.
+type eanSymbol struct {
+	modules []bool
+	// Modules of the guard bars extended below the other bars.
+	extended []bool
+	// Modules of the add-on, nil without add-on.
+	addOn []bool
+	// Mandatory light margins left and right of the symbol in modules.
+	quietLeft  int
+	quietRight int
+	digits     []eanDigit
+}
+
+// Width of the symbol including light margins in modules.
+func (s eanSymbol) width() int {
+	return s.quietLeft + len(s.modules) + s.quietRight
+}
+
+// Marks modules in [start, end) as extended guard bars.
+func (s *eanSymbol) extend(start int, end int) {
+	for i := start; i < end; i++ {
+		s.extended[i] = true
+	}
+}
+
+// Adds digits each placed centered under its seven module symbol character.
+func (s *eanSymbol) addDigits(digits string, position int) {
+	for i, digit := range digits {
+		s.digits = append(s.digits, eanDigit{digit: string(digit), position: position + 7*i, width: 7, align: "C"})
+	}
+}
+
+// Lays out the encoded EAN-13, EAN-8 or UPC-A code with optional EAN-2
+// or EAN-5 add-on. The first digit of EAN-13 is printed in the left light
+// margin, the first and last digits of UPC-A outside of the symbol next
+// to their extended bars.
+func newEanSymbol(code barcode.Barcode, symbology Symbology, addOn string) (eanSymbol, error) {
+	rows := barcodeModules(code)
+	if len(rows) != 1 {
+		return eanSymbol{}, fmt.Errorf("Code %q is not an EAN or UPC symbol", code.Content())
+	}
+	content := code.Content()
+	symbol := eanSymbol{modules: rows[0], extended: make([]bool, len(rows[0]))}
+	switch {
+	case len(symbol.modules) == 95 && len(content) == 13 && symbology == SymbologyUPCA:
+		// UPC-A is encoded as EAN-13 with leading zero.
+		upc := content[1:]
+		symbol.quietLeft, symbol.quietRight = 9, 9
+		symbol.extend(0, 10)
+		symbol.extend(45, 50)
+		symbol.extend(85, 95)
+		symbol.digits = append(symbol.digits, eanDigit{digit: upc[:1], position: -8, width: 7, align: "R", small: true})
+		symbol.addDigits(upc[1:6], 10)
+		symbol.addDigits(upc[6:11], 50)
+		symbol.digits = append(symbol.digits, eanDigit{digit: upc[11:], position: 96, width: 7, align: "L", small: true})
+	case len(symbol.modules) == 95 && len(content) == 13:
+		symbol.quietLeft, symbol.quietRight = 11, 7
+		symbol.extend(0, 3)
+		symbol.extend(45, 50)
+		symbol.extend(92, 95)
+		symbol.digits = append(symbol.digits, eanDigit{digit: content[:1], position: -10, width: 9, align: "R"})
+		symbol.addDigits(content[1:7], 3)
+		symbol.addDigits(content[7:], 50)
+	case len(symbol.modules) == 67 && len(content) == 8:
+		symbol.quietLeft, symbol.quietRight = 7, 7
+		symbol.extend(0, 3)
+		symbol.extend(31, 36)
+		symbol.extend(64, 67)
+		symbol.addDigits(content[:4], 3)
+		symbol.addDigits(content[4:], 36)
+	default:
+		return eanSymbol{}, fmt.Errorf("Code %q is not an EAN or UPC symbol", content)
+	}
+	if addOn != "" {
+		if err := symbol.appendAddOn(addOn); err != nil {
+			return eanSymbol{}, err
+		}
+	}
+	return symbol, nil
+}
+
+// Returns the module width and bar height of the symbol drawn to the box.
+// Module width is given by the magnification, the module width of the layout
+// or fills the box including light margins, in this order.
+// Returns an error if the symbol does not fit the box.
+func (s eanSymbol) size(box Box, moduleWidth float64, magnification float64) (float64, float64, error) {
+	width := box.Width / float64(s.width())
+	if magnification > 0 {
+		width = NominalModuleWidth * magnification
+	} else if moduleWidth > 0 {
+		width = moduleWidth
+	}
+	// Small tolerance for float rounding of scaled layouts.
+	const eps = 1e-9
+	if width*float64(s.width()) > box.Width+eps {
+		return 0, 0, fmt.Errorf(
+			"Barcode with light margins %g mm wide does not fit the barcode box %g mm wide",
+			width*float64(s.width()), box.Width)
+	}
+	height := box.Height - eanDigitsHeight*width
+	// Add-on bars are shorter by its digits, but reach to the end of guard bars.
+	if height <= 0 || (s.addOn != nil && height+(eanGuardsHeight-eanDigitsHeight)*width <= 0) {
+		return 0, 0, fmt.Errorf("Barcode box %g mm high leaves no space for bars above the digits", box.Height)
+	}
+	return width, height, nil
+}
+
+// Draws the symbol centered in the box with light margins, extended guard
+// bars, digits under the bars and add-on digits above its bars.
+func (p *Pdf) drawEanSymbol(symbol eanSymbol, box Box) error {
+	width, height, err := symbol.size(box, p.layout.ModuleWidth, p.magnification)
+	if err != nil {
+		return err
+	}
+	x0 := box.X + (box.Width-width*float64(symbol.width()))/2 + width*float64(symbol.quietLeft)
+	y0 := box.Y
+	// Millimeters to points.
+	const ptPerMm = 72 / 25.4
+	digitsHeight := eanDigitsHeight * width
+
+	p.pdf.SetFillColor(0, 0, 0)
+	for x := 0; x < len(symbol.modules); {
+		if !symbol.modules[x] {
+			x++
+			continue
+		}
+		start := x
+		for x < len(symbol.modules) && symbol.modules[x] && symbol.extended[x] == symbol.extended[start] {
+			x++
+		}
+		top, barHeight := y0, height
+		if symbol.extended[start] {
+			barHeight += eanGuardsHeight * width
+		}
+		if symbol.addOn != nil && symbol.addOn[start] {
+			top, barHeight = y0+digitsHeight, height+(eanGuardsHeight-eanDigitsHeight)*width
+		}
+		p.pdf.Rect(x0+float64(start)*width, top, float64(x-start)*width, barHeight, "F")
+	}
+
+	for _, digit := range symbol.digits {
+		size := digitsHeight * ptPerMm
+		if digit.small {
+			size *= 0.75
+		}
+		p.pdf.SetFont("Arial", "", size)
+		top := y0 + height
+		if digit.above {
+			top = y0
+		}
+		p.pdf.SetXY(x0+float64(digit.position)*width, top)
+		p.pdf.CellFormat(float64(digit.width)*width, digitsHeight, digit.digit, "", 0, digit.align+"M", false, 0, "")
+	}
+	p.pdf.SetFont("Arial", "", p.layout.FontSize)
+	return nil
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestNewEanSymbol(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		symbology  Symbology
+		quietLeft  int
+		quietRight int
+		digits     string
+		extended   int
+		wantErr    bool
+	}{
+		{"EAN-13", "4006381333931", SymbologyEAN, 11, 7, "4006381333931", 6, false},
+		{"EAN-8", "96385074", SymbologyEAN, 7, 7, "96385074", 6, false},
+		{"UPC-A", "036000291452", SymbologyUPCA, 9, 9, "036000291452", 13, false},
+		{"Code 128", "ABC", SymbologyCode128, 0, 0, "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := tt.symbology.Encode(tt.content)
+			if err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			symbol, err := newEanSymbol(code, tt.symbology, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newEanSymbol() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if symbol.quietLeft != tt.quietLeft || symbol.quietRight != tt.quietRight {
+				t.Errorf("light margins = %d, %d, want %d, %d", symbol.quietLeft, symbol.quietRight, tt.quietLeft, tt.quietRight)
+			}
+			digits := ""
+			for _, d := range symbol.digits {
+				digits += d.digit
+			}
+			if digits != tt.digits {
+				t.Errorf("digits = %q, want %q", digits, tt.digits)
+			}
+			// Dark modules of the extended bars.
+			extended := 0
+			for i, dark := range symbol.modules {
+				if dark && symbol.extended[i] {
+					extended++
+				}
+			}
+			if extended != tt.extended {
+				t.Errorf("extended dark modules = %d, want %d", extended, tt.extended)
+			}
+		})
+	}
+}
+
+func TestEanSymbol_Size(t *testing.T) {
+	code, err := SymbologyEAN.Encode("4006381333931")
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	symbol, err := newEanSymbol(code, SymbologyEAN, "")
+	if err != nil {
+		t.Fatalf("newEanSymbol() failed: %v", err)
+	}
+	tests := []struct {
+		name          string
+		box           Box
+		moduleWidth   float64
+		magnification float64
+		wantWidth     float64
+		wantErr       bool
+	}{
+		{"Fill box", Box{Width: 56.5, Height: 20}, 0, 0, 0.5, false},
+		{"Module width", Box{Width: 56.5, Height: 20}, 0.4, 0, 0.4, false},
+		{"Magnification wins", Box{Width: 56.5, Height: 20}, 0.4, 1, NominalModuleWidth, false},
+		{"Too narrow", Box{Width: 30, Height: 20}, 0, 1, 0, true},
+		{"Too low", Box{Width: 56.5, Height: 3}, 0, 1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height, err := symbol.size(tt.box, tt.moduleWidth, tt.magnification)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("size() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if width != tt.wantWidth {
+				t.Errorf("size() width = %g, want %g", width, tt.wantWidth)
+			}
+			if !tt.wantErr && height != tt.box.Height-eanDigitsHeight*width {
+				t.Errorf("size() height = %g, want %g", height, tt.box.Height-eanDigitsHeight*width)
+			}
+		})
+	}
+}
+
+func TestPdf_GS1Rendering(t *testing.T) {
+	pdf, err := NewPdfWithLayout(NewLayout(50, 30))
+	if err != nil {
+		t.Fatalf("NewPdfWithLayout() failed: %v", err)
+	}
+	if err := pdf.SetGS1Rendering(true, 0.9); err != nil {
+		t.Fatalf("SetGS1Rendering() failed: %v", err)
+	}
+	if err := pdf.SetGS1Rendering(true, 2.5); err == nil {
+		t.Error("SetGS1Rendering() should fail for magnification above 2")
+	}
+	pdf.pdf.SetCompression(false)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := pdf.AddPages([]Record{{Ean: "4006381333931", Times: 1}}, 1, log); err != nil {
+		t.Fatalf("AddPages() failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := pdf.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() failed: %v", err)
+	}
+	// Every digit is printed on its own and the bottom slot is left out.
+	if n := bytes.Count(buf.Bytes(), []byte(")Tj")); n != 13 {
+		t.Errorf("PDF contains %d texts, want 13 digits", n)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("(4006381333931)")) {
+		t.Error("PDF contains the bottom slot text")
+	}
+}
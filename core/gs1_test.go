@@ -0,0 +1,174 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/boombuler/barcode/code128"
+)
+
+func TestParseGS1ElementString(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    GS1ElementString
+		wantErr bool
+	}{
+		{
+			name:  "GTIN, expiry and batch",
+			value: "(01)09501101530003(17)251231(10)ABC123",
+			want:  GS1ElementString{{"01", "09501101530003"}, {"17", "251231"}, {"10", "ABC123"}},
+		},
+		{
+			name:  "Parenthesis in data",
+			value: "(10)A(B)(21)1",
+			want:  GS1ElementString{{"10", "A(B)"}, {"21", "1"}},
+		},
+		{name: "Without AI", value: "09501101530003", wantErr: true},
+		{name: "Unclosed AI", value: "(01", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGS1ElementString(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGS1ElementString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseGS1ElementString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGS1ElementString_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "Valid", value: "(01)09501101530003(17)251231(10)ABC123(21)X-1"},
+		{name: "Day 00", value: "(17)250200"},
+		{name: "Leap day", value: "(11)240229"},
+		{name: "SSCC", value: "(00)106141411234567897"},
+		{name: "Count", value: "(02)09501101530003(37)24"},
+		{name: "Short GTIN", value: "(01)5901234123457", wantErr: true},
+		{name: "Wrong GTIN check digit", value: "(01)09501101530004", wantErr: true},
+		{name: "Invalid month", value: "(17)251301", wantErr: true},
+		{name: "Invalid day", value: "(15)250230", wantErr: true},
+		{name: "Non leap day", value: "(11)250229", wantErr: true},
+		{name: "Batch too long", value: "(10)123456789012345678901", wantErr: true},
+		{name: "Invalid character", value: "(21)ABC#1", wantErr: true},
+		{name: "Non numeric count", value: "(37)1A", wantErr: true},
+		{name: "Unsupported AI", value: "(99)123", wantErr: true},
+		{name: "Repeated AI", value: "(10)A(10)B", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elements, err := ParseGS1ElementString(tt.value)
+			if err != nil {
+				t.Fatalf("ParseGS1ElementString() failed: %v", err)
+			}
+			if err := elements.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGS1ElementString_encode(t *testing.T) {
+	fnc1 := string(code128.FNC1)
+	tests := []struct {
+		name     string
+		elements GS1ElementString
+		want     string
+	}{
+		{
+			name:     "Fixed length needs no separator",
+			elements: GS1ElementString{{"01", "09501101530003"}, {"17", "251231"}},
+			want:     fnc1 + "0109501101530003" + "17251231",
+		},
+		{
+			name:     "Variable length is terminated unless last",
+			elements: GS1ElementString{{"01", "09501101530003"}, {"10", "ABC"}, {"21", "42"}},
+			want:     fnc1 + "0109501101530003" + "10ABC" + fnc1 + "2142",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.elements.encode(code128.FNC1); got != tt.want {
+				t.Errorf("encode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildGS1ElementString(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]string
+		want   string
+	}{
+		{
+			name:   "Fixed length first",
+			values: map[string]string{"21": "S1", "10": "B1", "17": "251231", "01": "09501101530003"},
+			want:   "(01)09501101530003(17)251231(10)B1(21)S1",
+		},
+		{
+			name:   "GTIN-13 padded and ISO date",
+			values: map[string]string{"01": " 5901234123457 ", "17": "2025-12-31"},
+			want:   "(01)05901234123457(17)251231",
+		},
+		{
+			name:   "Czech date",
+			values: map[string]string{"15": "1.2.2026"},
+			want:   "(15)260201",
+		},
+		{
+			name:   "Empty values skipped",
+			values: map[string]string{"01": "", "10": "B1"},
+			want:   "(10)B1",
+		},
+		{
+			name:   "All empty",
+			values: map[string]string{"01": "", "10": " "},
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildGS1ElementString(tt.values).HumanReadable(); got != tt.want {
+				t.Errorf("BuildGS1ElementString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGS1Columns(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "Empty", value: "", want: map[string]string{}},
+		{
+			name:  "Mapping",
+			value: "01=GTIN, (17)=Expiry date,10=Batch",
+			want:  map[string]string{"01": "GTIN", "17": "Expiry date", "10": "Batch"},
+		},
+		{name: "Missing header", value: "01=", wantErr: true},
+		{name: "Missing separator", value: "01", wantErr: true},
+		{name: "Unsupported AI", value: "99=Other", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGS1Columns(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGS1Columns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseGS1Columns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
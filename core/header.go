@@ -0,0 +1,158 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Number of first table rows searched for the header row when not configured.
+const DefaultHeaderSearchRows = 10
+
+// Returns the string with diacritics removed, e.g. "č" as "c".
+func removeDiacritics(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if stripped, _, err := transform.String(t, s); err == nil {
+		return stripped
+	}
+	return s
+}
+
+// Returns the header in the form used for matching: lower case,
+// without diacritics and with single spaces between words.
+func normalizeHeader(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(removeDiacritics(s)), " "))
+}
+
+// Returns the zero based index of the column given by its one based
+// position ("#3"), or -1 if the column is not given by position.
+func columnPosition(column string) int {
+	position, ok := strings.CutPrefix(strings.TrimSpace(column), "#")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.Atoi(position)
+	if err != nil || n < 1 {
+		return -1
+	}
+	return n - 1
+}
+
+// Returns the zero based index of the column given by spreadsheet letters
+// ("C", "AB"), or -1 if the column is not given by letters. Only upper case
+// letters are accepted, so common lower case headers are not mistaken for them.
+func columnLetters(column string) int {
+	column = strings.TrimSpace(column)
+	if len(column) == 0 || len(column) > 3 {
+		return -1
+	}
+	index := 0
+	for _, c := range column {
+		if c < 'A' || c > 'Z' {
+			return -1
+		}
+		index = index*26 + int(c-'A') + 1
+	}
+	return index - 1
+}
+
+// Returns spreadsheet letters of the column with zero based index ("A", "AB").
+func ColumnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// Returns the index of the column in the header row. Column is given by
+// explicit position ("#3"), by header matched regardless of case, spaces
+// and diacritics, or by letters ("C") if no header matches and the table
+// is wide enough. Returns -1 if the column is not found.
+func findColumn(header []string, column string, width int) int {
+	if index := columnPosition(column); index >= 0 {
+		return index
+	}
+	normalized := normalizeHeader(column)
+	for i, item := range header {
+		if normalizeHeader(item) == normalized {
+			return i
+		}
+	}
+	if index := columnLetters(column); index >= 0 && index < width {
+		return index
+	}
+	return -1
+}
+
+// Result of searching the configured columns in the table.
+type headerMatch struct {
+	// Zero based index of the header row.
+	row int
+	// Column indexes in order of columns, -1 if the column is not found or not set.
+	indexes []int
+	// Number of found columns.
+	found int
+}
+
+// Searches the first searchRows rows of the table for the header row, which
+// is the first one where all not empty columns are found. If there is none,
+// returns the row with the most columns found (the first one on tie)
+// and false.
+func findHeaderRow(table Table, columns []string, searchRows int) (headerMatch, bool) {
+	if searchRows <= 0 {
+		searchRows = DefaultHeaderSearchRows
+	}
+	width := 0
+	for _, row := range table {
+		width = max(width, len(row))
+	}
+	wanted := 0
+	for _, column := range columns {
+		if strings.TrimSpace(column) != "" {
+			wanted++
+		}
+	}
+
+	best := headerMatch{found: -1}
+	for r := 0; r < min(searchRows, len(table)); r++ {
+		match := headerMatch{row: r, indexes: make([]int, len(columns))}
+		for i, column := range columns {
+			match.indexes[i] = -1
+			if strings.TrimSpace(column) == "" {
+				continue
+			}
+			match.indexes[i] = findColumn(table[r], column, width)
+			if match.indexes[i] != -1 {
+				match.found++
+			}
+		}
+		if match.found == wanted {
+			return match, true
+		}
+		// Prefer rows with some content, empty rows above title are common.
+		if match.found > best.found || (match.found == best.found && len(table[best.row]) == 0) {
+			best = match
+		}
+	}
+	return best, false
+}
+
+// Returns not empty cells of the row as a quoted list for error messages.
+func availableHeaders(row []string) string {
+	headers := []string{}
+	for _, item := range row {
+		if strings.TrimSpace(item) != "" {
+			headers = append(headers, fmt.Sprintf("'%s'", item))
+		}
+	}
+	if len(headers) == 0 {
+		return "none"
+	}
+	return strings.Join(headers, ", ")
+}
@@ -0,0 +1,76 @@
+package core
+
+import "testing"
+
+func TestNormalizeHeader(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "EAN", want: "ean"},
+		{input: " EAN code ", want: "ean code"},
+		{input: "EAN  \tcode", want: "ean code"},
+		{input: "Čárový kód", want: "carovy kod"},
+		{input: "Množství", want: "mnozstvi"},
+		{input: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := normalizeHeader(tt.input); got != tt.want {
+				t.Errorf("normalizeHeader() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindColumn(t *testing.T) {
+	header := []string{"Name", "EAN code ", "Počet", "A"}
+	tests := []struct {
+		name   string
+		column string
+		width  int
+		want   int
+	}{
+		{name: "Header", column: "name", width: 4, want: 0},
+		{name: "Trailing spaces", column: "EAN code", width: 4, want: 1},
+		{name: "Diacritics", column: "pocet", width: 4, want: 2},
+		{name: "Letter", column: "C", width: 4, want: 2},
+		{name: "Header wins over letter", column: "A", width: 4, want: 3},
+		{name: "Letter outside of table", column: "E", width: 4, want: -1},
+		{name: "Lower case is not a letter", column: "c", width: 4, want: -1},
+		{name: "Position", column: "#2", width: 4, want: 1},
+		{name: "Position outside of header", column: "#6", width: 4, want: 5},
+		{name: "Invalid position", column: "#0", width: 4, want: -1},
+		{name: "Unknown", column: "Price", width: 4, want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findColumn(header, tt.column, tt.width); got != tt.want {
+				t.Errorf("findColumn() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnLetters(t *testing.T) {
+	tests := []struct {
+		column string
+		want   int
+	}{
+		{column: "A", want: 0},
+		{column: "Z", want: 25},
+		{column: "AA", want: 26},
+		{column: "AB", want: 27},
+		{column: "XFD", want: 16383},
+		{column: "ABCD", want: -1},
+		{column: "A1", want: -1},
+		{column: "", want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			if got := columnLetters(tt.column); got != tt.want {
+				t.Errorf("columnLetters() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type InputFormat string
+
+const (
+	FormatCsv  InputFormat = "csv"
+	FormatTsv  InputFormat = "tsv"
+	FormatXlsx InputFormat = "xlsx"
+	// Legacy Excel 97-2003 workbook.
+	FormatXls InputFormat = "xls"
+	FormatOds InputFormat = "ods"
+)
+
+// Supported input formats.
+var InputFormats = []InputFormat{FormatCsv, FormatTsv, FormatXlsx, FormatXls, FormatOds}
+
+// Path of the standard input or output, e.g. "-csv -".
+const StdStream = "-"
+
+// Converts a string to an InputFormat, matching is case insensitive and
+// leading dot is ignored. Empty string means the format is detected.
+func InputFormatFromString(s string) (InputFormat, error) {
+	normalized := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), ".")
+	if normalized == "" {
+		return "", nil
+	}
+	for _, format := range InputFormats {
+		if string(format) == normalized {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown input format %q, expected csv, tsv, xlsx, xls or ods", s)
+}
+
+// Implements the json.Unmarshaler interface for InputFormat.
+func (f *InputFormat) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	format, err := InputFormatFromString(s)
+	if err != nil {
+		return err
+	}
+	*f = format
+	return nil
+}
+
+// Returns the input format given by the file name extension. Returns
+// an empty format for unknown extensions, which must be sniffed from
+// the content.
+func InputFormatFromFilename(filename string) (InputFormat, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCsv, nil
+	case ".tsv":
+		return FormatTsv, nil
+	case ".xlsx":
+		return FormatXlsx, nil
+	case ".ods":
+		return FormatOds, nil
+	case ".xls":
+		return FormatXls, nil
+	default:
+		return "", nil
+	}
+}
+
+// Detects the input format from the content. Zip archives are OpenDocument
+// spreadsheets if they say so in their mimetype, Excel workbooks otherwise.
+// Compound documents are legacy Excel workbooks. Everything else is read as CSV.
+func SniffInputFormat(content io.ReadSeeker) (InputFormat, error) {
+	header := make([]byte, 8)
+	n, err := io.ReadFull(content, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	header = header[:n]
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	switch {
+	// Compound document header of legacy Office files.
+	case bytes.HasPrefix(header, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}):
+		return FormatXls, nil
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")):
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return "", err
+		}
+		if _, err := content.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		if isOds(data) {
+			return FormatOds, nil
+		}
+		return FormatXlsx, nil
+	default:
+		return FormatCsv, nil
+	}
+}
+
+// Reports whether the zip archive is an OpenDocument spreadsheet.
+func isOds(data []byte) bool {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	for _, file := range archive.File {
+		if file.Name != "mimetype" {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return false
+		}
+		defer reader.Close()
+		mimetype, err := io.ReadAll(reader)
+		return err == nil && strings.TrimSpace(string(mimetype)) == odsMimetype
+	}
+	return false
+}
+
+// Returns the input format given by the file name, or sniffed
+// from the content if the extension is not known.
+func DetectInputFormat(filename string, content io.ReadSeeker) (InputFormat, error) {
+	format, err := InputFormatFromFilename(filename)
+	if err != nil || format != "" {
+		return format, err
+	}
+	return SniffInputFormat(content)
+}
+
+// Returns names of all sheets of the workbook in their order.
+// Formats without sheets (CSV, TSV) have no sheet names.
+func SheetNames(filename string, content io.ReadSeeker) ([]string, error) {
+	format, err := DetectInputFormat(filename, content)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatXlsx:
+		return ExcelSheetNames(content)
+	case FormatXls:
+		return XlsSheetNames(content)
+	case FormatOds:
+		return OdsSheetNames(content)
+	default:
+		return nil, nil
+	}
+}
+
+// Returns the selected sheet names, all sheets if merge is set.
+// Sheet is selected by name or one based position as in FindSheet.
+func selectSheets(sheets []string, sheet string, merge bool) ([]string, error) {
+	if merge {
+		if len(sheets) == 0 {
+			return nil, errors.New("Workbook containing 0 sheets.")
+		}
+		return sheets, nil
+	}
+	index, err := FindSheet(sheets, sheet)
+	if err != nil {
+		return nil, err
+	}
+	return sheets[index : index+1], nil
+}
+
+// Human readable list of supported input file extensions.
+const supportedExtensions = ".csv, .tsv, .xlsx, .xls or .ods"
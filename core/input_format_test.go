@@ -0,0 +1,107 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestInputFormatFromFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     InputFormat
+		wantErr  bool
+	}{
+		{filename: "data.csv", want: FormatCsv},
+		{filename: "DATA.TSV", want: FormatTsv},
+		{filename: "data.xlsx", want: FormatXlsx},
+		{filename: "data.ods", want: FormatOds},
+		{filename: "data.xls", want: FormatXls},
+		{filename: "data.txt", want: ""},
+		{filename: "data", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			got, err := InputFormatFromFilename(tt.filename)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InputFormatFromFilename() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("InputFormatFromFilename() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInputFormatFromString(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    InputFormat
+		wantErr bool
+	}{
+		{input: "csv", want: FormatCsv},
+		{input: "XLSX", want: FormatXlsx},
+		{input: ".ods", want: FormatOds},
+		{input: "", want: ""},
+		{input: "xls", want: FormatXls},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := InputFormatFromString(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InputFormatFromString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("InputFormatFromString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSniffInputFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		content *bytes.Reader
+		want    InputFormat
+		wantErr bool
+	}{
+		{name: "CSV", content: bytes.NewReader([]byte("name,ean\nMilk,123\n")), want: FormatCsv},
+		{name: "Empty", content: bytes.NewReader(nil), want: FormatCsv},
+		{name: "Excel", content: excelWorkbook(t, []string{"Sheet1"}, nil), want: FormatXlsx},
+		{name: "OpenDocument", content: odsFile(t, odsTwoSheets), want: FormatOds},
+		{name: "Legacy Excel", content: xlsTwoSheets(t), want: FormatXls},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SniffInputFormat(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SniffInputFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SniffInputFormat() = %q, want %q", got, tt.want)
+			}
+			if offset, _ := tt.content.Seek(0, 1); offset != 0 {
+				t.Errorf("SniffInputFormat() left content at offset %d", offset)
+			}
+		})
+	}
+}
+
+func TestSheetNames(t *testing.T) {
+	names, err := SheetNames("data", odsFile(t, odsTwoSheets))
+	if err != nil || strings.Join(names, ",") != "Products,Prices" {
+		t.Errorf("SheetNames() = %v, %v, want ods sheets", names, err)
+	}
+	names, err = SheetNames("data.xlsx", excelWorkbook(t, []string{"A", "B"}, nil))
+	if err != nil || strings.Join(names, ",") != "A,B" {
+		t.Errorf("SheetNames() = %v, %v, want excel sheets", names, err)
+	}
+	names, err = SheetNames("data.xls", xlsTwoSheets(t))
+	if err != nil || strings.Join(names, ",") != "Products,Empty" {
+		t.Errorf("SheetNames() = %v, %v, want xls sheets", names, err)
+	}
+	names, err = SheetNames("data.csv", strings.NewReader("a,b"))
+	if err != nil || names != nil {
+		t.Errorf("SheetNames() = %v, %v, want no sheets for CSV", names, err)
+	}
+}
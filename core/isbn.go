@@ -0,0 +1,47 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Removes hyphens and spaces used to write ISBN in groups, e.g. "978-80-7200-010-3".
+func cleanISBN(s string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(s))
+}
+
+// Reports whether the code has the ISBN prefix 978 or 979 of EAN-13.
+func hasISBNPrefix(code string) bool {
+	return strings.HasPrefix(code, "978") || strings.HasPrefix(code, "979")
+}
+
+// Converts ISBN-10 to ISBN-13 with prefix 978 and new check digit.
+// Hyphens and spaces are ignored. Returns an error if the ISBN-10 is not
+// valid, its check digit is modulo 11 and may be X.
+func ISBN10ToISBN13(isbn string) (string, error) {
+	isbn = strings.ToUpper(cleanISBN(isbn))
+	if len(isbn) != 10 {
+		return "", fmt.Errorf("ISBN-10 must have 10 characters, got %q", isbn)
+	}
+	sum := 0
+	for i, c := range isbn {
+		digit := int(c - '0')
+		switch {
+		case c >= '0' && c <= '9':
+		case c == 'X' && i == 9:
+			digit = 10
+		default:
+			return "", fmt.Errorf("ISBN-10 %q contains invalid character %q", isbn, c)
+		}
+		sum += (10 - i) * digit
+	}
+	if sum%11 != 0 {
+		return "", fmt.Errorf("ISBN-10 %q has wrong check digit", isbn)
+	}
+	code := "978" + isbn[:9]
+	check, err := GS1CheckDigit(code)
+	if err != nil {
+		return "", err
+	}
+	return code + string(check), nil
+}
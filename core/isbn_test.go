@@ -0,0 +1,29 @@
+package core
+
+import "testing"
+
+func TestISBN10ToISBN13(t *testing.T) {
+	tests := []struct {
+		isbn    string
+		want    string
+		wantErr bool
+	}{
+		{"0-306-40615-2", "9780306406157", false},
+		{"043942089X", "9780439420891", false},
+		{"043942089x", "9780439420891", false},
+		{"0306406153", "", true},
+		{"03064061", "", true},
+		{"X306406152", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.isbn, func(t *testing.T) {
+			got, err := ISBN10ToISBN13(tt.isbn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ISBN10ToISBN13() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ISBN10ToISBN13() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Sheet of adhesive labels arranged in a grid, e.g. Avery-style A4 sheets.
+// All values are in millimeters. Margins give position of the top left label,
+// pitch is the distance between the same edges of neighbouring labels. When
+// pitch is zero, it is computed from label size and gutter.
+type LabelSheet struct {
+	Name             string  `json:"name,omitempty"`
+	PageWidth        float64 `json:"page_width"`
+	PageHeight       float64 `json:"page_height"`
+	Margins          Margins `json:"margins"`
+	Columns          int     `json:"columns"`
+	Rows             int     `json:"rows"`
+	LabelWidth       float64 `json:"label_width"`
+	LabelHeight      float64 `json:"label_height"`
+	HorizontalPitch  float64 `json:"horizontal_pitch,omitempty"`
+	VerticalPitch    float64 `json:"vertical_pitch,omitempty"`
+	HorizontalGutter float64 `json:"horizontal_gutter,omitempty"`
+	VerticalGutter   float64 `json:"vertical_gutter,omitempty"`
+}
+
+const (
+	a4Width      = 210.0
+	a4Height     = 297.0
+	letterWidth  = 215.9
+	letterHeight = 279.4
+)
+
+// Built-in presets for common label sheet products.
+var LabelSheetPresets = map[string]LabelSheet{
+	"avery-l7160": {
+		Name: "avery-l7160", PageWidth: a4Width, PageHeight: a4Height,
+		Margins: Margins{Top: 15.15, Left: 7.25}, Columns: 3, Rows: 7,
+		LabelWidth: 63.5, LabelHeight: 38.1, HorizontalPitch: 66.04, VerticalPitch: 38.1,
+	},
+	"avery-l7163": {
+		Name: "avery-l7163", PageWidth: a4Width, PageHeight: a4Height,
+		Margins: Margins{Top: 15.15, Left: 4.65}, Columns: 2, Rows: 7,
+		LabelWidth: 99.1, LabelHeight: 38.1, HorizontalPitch: 101.6, VerticalPitch: 38.1,
+	},
+	"avery-l7651": {
+		Name: "avery-l7651", PageWidth: a4Width, PageHeight: a4Height,
+		Margins: Margins{Top: 10.7, Left: 4.75}, Columns: 5, Rows: 13,
+		LabelWidth: 38.1, LabelHeight: 21.2, HorizontalPitch: 40.64, VerticalPitch: 21.2,
+	},
+	"a4-3x8": {
+		Name: "a4-3x8", PageWidth: a4Width, PageHeight: a4Height,
+		Margins: Margins{Top: 0.5}, Columns: 3, Rows: 8,
+		LabelWidth: 70, LabelHeight: 37,
+	},
+	"a4-4x10": {
+		Name: "a4-4x10", PageWidth: a4Width, PageHeight: a4Height,
+		Columns: 4, Rows: 10,
+		LabelWidth: 52.5, LabelHeight: 29.7,
+	},
+	"avery-5160": {
+		Name: "avery-5160", PageWidth: letterWidth, PageHeight: letterHeight,
+		Margins: Margins{Top: 12.7, Left: 4.76}, Columns: 3, Rows: 10,
+		LabelWidth: 66.7, LabelHeight: 25.4, HorizontalPitch: 69.85, VerticalPitch: 25.4,
+	},
+}
+
+// Returns sorted names of the built-in label sheet presets.
+func LabelSheetPresetNames() []string {
+	names := []string{}
+	for name := range LabelSheetPresets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Returns the label sheet preset with the given (case insensitive) name,
+// or loads the label sheet from a JSON file if no preset matches.
+func LabelSheetFromString(s string) (LabelSheet, error) {
+	if sheet, ok := LabelSheetPresets[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return sheet, nil
+	}
+	if strings.ToLower(filepath.Ext(s)) != ".json" {
+		return LabelSheet{}, fmt.Errorf(
+			"Unknown label sheet %q, use JSON file or one of: %s",
+			s, strings.Join(LabelSheetPresetNames(), ", "))
+	}
+	return LoadLabelSheet(s)
+}
+
+// Reads and deserializes a label sheet from a JSON file and validates it.
+func LoadLabelSheet(path string) (LabelSheet, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return LabelSheet{}, errors.Join(errors.New("Cannot load label sheet"), err)
+	}
+	defer file.Close()
+
+	var sheet LabelSheet
+	if err := json.NewDecoder(file).Decode(&sheet); err != nil {
+		return LabelSheet{}, errors.Join(errors.New("Cannot decode label sheet"), err)
+	}
+	return sheet, sheet.Validate()
+}
+
+// Returns horizontal and vertical distance between neighbouring labels.
+func (s LabelSheet) Pitch() (float64, float64) {
+	horizontal := s.HorizontalPitch
+	if horizontal == 0 {
+		horizontal = s.LabelWidth + s.HorizontalGutter
+	}
+	vertical := s.VerticalPitch
+	if vertical == 0 {
+		vertical = s.LabelHeight + s.VerticalGutter
+	}
+	return horizontal, vertical
+}
+
+// Returns number of labels on a single sheet.
+func (s LabelSheet) PerPage() int {
+	return s.Columns * s.Rows
+}
+
+// Returns the top left corner of the label at the zero based position
+// on the sheet. Labels are filled row by row.
+func (s LabelSheet) Origin(position int) (float64, float64) {
+	horizontal, vertical := s.Pitch()
+	column := position % s.Columns
+	row := (position / s.Columns) % s.Rows
+	return s.Margins.Left + float64(column)*horizontal, s.Margins.Top + float64(row)*vertical
+}
+
+// Validate checks that the sheet has positive size, non empty grid
+// and that all labels fit on the page inside the margins.
+func (s LabelSheet) Validate() error {
+	if s.PageWidth <= 0 || s.PageHeight <= 0 {
+		return fmt.Errorf("Sheet size must be positive, got %gx%g mm", s.PageWidth, s.PageHeight)
+	}
+	if s.LabelWidth <= 0 || s.LabelHeight <= 0 {
+		return fmt.Errorf("Label size must be positive, got %gx%g mm", s.LabelWidth, s.LabelHeight)
+	}
+	if s.Columns < 1 || s.Rows < 1 {
+		return fmt.Errorf("Sheet must have at least one column and row, got %dx%d", s.Columns, s.Rows)
+	}
+	m := s.Margins
+	if m.Top < 0 || m.Right < 0 || m.Bottom < 0 || m.Left < 0 {
+		return errors.New("Sheet margins cannot be negative")
+	}
+	if s.HorizontalGutter < 0 || s.VerticalGutter < 0 {
+		return errors.New("Sheet gutters cannot be negative")
+	}
+	horizontal, vertical := s.Pitch()
+	if horizontal < s.LabelWidth || vertical < s.LabelHeight {
+		return errors.New("Sheet pitch cannot be smaller than label size")
+	}
+	// Small tolerance for rounded sizes of sheet products.
+	const eps = 0.01
+	if m.Left+float64(s.Columns-1)*horizontal+s.LabelWidth > s.PageWidth-m.Right+eps {
+		return errors.New("Sheet columns do not fit on the page")
+	}
+	if m.Top+float64(s.Rows-1)*vertical+s.LabelHeight > s.PageHeight-m.Bottom+eps {
+		return errors.New("Sheet rows do not fit on the page")
+	}
+	return nil
+}
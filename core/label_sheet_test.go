@@ -0,0 +1,123 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLabelSheetPresets_Validate(t *testing.T) {
+	for _, name := range LabelSheetPresetNames() {
+		t.Run(name, func(t *testing.T) {
+			sheet := LabelSheetPresets[name]
+			if sheet.Name != name {
+				t.Errorf("Preset name = %v, want %v", sheet.Name, name)
+			}
+			if err := sheet.Validate(); err != nil {
+				t.Errorf("Validate() failed: %v", err)
+			}
+			if err := NewLayout(sheet.LabelWidth, sheet.LabelHeight).Validate(); err != nil {
+				t.Errorf("Scaled layout is invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestLabelSheet_Origin(t *testing.T) {
+	sheet := LabelSheet{
+		PageWidth: 100, PageHeight: 100,
+		Margins: Margins{Top: 5, Left: 2}, Columns: 3, Rows: 2,
+		LabelWidth: 30, LabelHeight: 40, HorizontalGutter: 2, VerticalGutter: 5,
+	}
+	tests := []struct {
+		position int
+		x, y     float64
+	}{
+		{position: 0, x: 2, y: 5},
+		{position: 1, x: 34, y: 5},
+		{position: 2, x: 66, y: 5},
+		{position: 3, x: 2, y: 50},
+		{position: 5, x: 66, y: 50},
+		{position: 6, x: 2, y: 5}, // next sheet
+	}
+	for _, tt := range tests {
+		x, y := sheet.Origin(tt.position)
+		if x != tt.x || y != tt.y {
+			t.Errorf("Origin(%d) = %g,%g, want %g,%g", tt.position, x, y, tt.x, tt.y)
+		}
+	}
+	if sheet.PerPage() != 6 {
+		t.Errorf("PerPage() = %d, want 6", sheet.PerPage())
+	}
+}
+
+func TestLabelSheet_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(s *LabelSheet)
+		wantErr bool
+	}{
+		{name: "Valid", modify: func(s *LabelSheet) {}},
+		{name: "Zero page", modify: func(s *LabelSheet) { s.PageWidth = 0 }, wantErr: true},
+		{name: "Zero label", modify: func(s *LabelSheet) { s.LabelHeight = 0 }, wantErr: true},
+		{name: "No columns", modify: func(s *LabelSheet) { s.Columns = 0 }, wantErr: true},
+		{name: "Negative margin", modify: func(s *LabelSheet) { s.Margins.Top = -1 }, wantErr: true},
+		{name: "Negative gutter", modify: func(s *LabelSheet) { s.VerticalGutter = -1 }, wantErr: true},
+		{name: "Pitch under label size", modify: func(s *LabelSheet) { s.HorizontalPitch = 10 }, wantErr: true},
+		{name: "Too many columns", modify: func(s *LabelSheet) { s.Columns = 4 }, wantErr: true},
+		{name: "Too many rows", modify: func(s *LabelSheet) { s.Rows = 11 }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet := LabelSheetPresets["a4-3x8"]
+			tt.modify(&sheet)
+			err := sheet.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLabelSheetFromString(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sheet-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "sheet.json")
+	err = os.WriteFile(path, []byte(`{
+		"page_width": 210, "page_height": 297,
+		"margins": {"top": 10, "left": 10},
+		"columns": 2, "rows": 4, "label_width": 90, "label_height": 60,
+		"horizontal_gutter": 5, "vertical_gutter": 5
+	}`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write sheet: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{name: "Preset", value: "avery-l7160", want: 21},
+		{name: "Preset upper case", value: "A4-4X10", want: 40},
+		{name: "JSON file", value: path, want: 8},
+		{name: "Unknown preset", value: "avery-0000", wantErr: true},
+		{name: "Missing file", value: filepath.Join(tmpDir, "missing.json"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet, err := LabelSheetFromString(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LabelSheetFromString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && sheet.PerPage() != tt.want {
+				t.Errorf("PerPage() = %d, want %d", sheet.PerPage(), tt.want)
+			}
+		})
+	}
+}
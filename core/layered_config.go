@@ -0,0 +1,450 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Prefix of environment variables with configuration values. Name of the
+// variable is the JSON key in upper case with dots replaced by underscores,
+// e.g. EANBAKER_TEXT_HEADER or EANBAKER_SERIAL_START.
+const EnvPrefix = "EANBAKER_"
+
+// Name of the configuration file of the user, it is next to the profile directory.
+const UserConfigName = "config.json"
+
+// Layer of the configuration. Values of later layers override earlier ones.
+type ConfigLayer struct {
+	// Source of the values, e.g. "project ./.EANBaker.json".
+	Source string
+	// Sets values of the layer to the generator and returns their JSON keys.
+	Apply func(g *Generator) ([]string, error)
+}
+
+// Generator merged from configuration layers with the source of every value.
+type ResolvedConfig struct {
+	Generator Generator
+	// Sources of values by their JSON keys, e.g. "serial.start".
+	Sources map[string]string
+}
+
+// Effective value of the resolved configuration.
+type ResolvedValue struct {
+	// JSON key, keys of nested objects are separated by dots.
+	Key string
+	// Value in JSON.
+	Value  string
+	Source string
+}
+
+// Returns the layers of the configuration directory of the user as
+// ProfileStore.ConfigLayers. Configuration file of the user is skipped
+// if there is no configuration directory.
+func ConfigLayers(app string, projectPath string, profile string, environ []string, log *slog.Logger) ([]ConfigLayer, error) {
+	store, err := UserProfileStore(app)
+	if err != nil && profile != "" {
+		return nil, err
+	}
+	return store.ConfigLayers(projectPath, profile, environ, log)
+}
+
+// Returns the configuration file of the user next to the profile
+// directory, e.g. ~/.config/EANBaker/config.json on Linux.
+func (s ProfileStore) UserConfigPath() string {
+	if s.Dir == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(s.Dir), UserConfigName)
+}
+
+// Returns the layers in order of precedence: built-in defaults, configuration
+// file of the user, project configuration file, the profile if it is not
+// empty and EANBAKER_* variables of the environment. Missing configuration
+// files are skipped, missing profile is an error.
+func (s ProfileStore) ConfigLayers(projectPath string, profile string, environ []string, log *slog.Logger) ([]ConfigLayer, error) {
+	layers := []ConfigLayer{DefaultsLayer()}
+	if userPath := s.UserConfigPath(); userPath != "" {
+		layers = append(layers, FileLayer("user "+userPath, userPath, false))
+	}
+	layers = append(layers, FileLayer("project "+projectPath, projectPath, false))
+	if profile != "" {
+		if err := s.checkExists(profile); err != nil {
+			return nil, err
+		}
+		layers = append(layers, FileLayer("profile "+profile, s.Path(profile), true))
+	}
+	return append(layers, EnvLayers(environ, log)...), nil
+}
+
+// Returns the layer of DefaultGenerator values.
+func DefaultsLayer() ConfigLayer {
+	return ConfigLayer{
+		Source: "default",
+		Apply: func(g *Generator) ([]string, error) {
+			*g = DefaultGenerator()
+			leaves, err := generatorLeaves(g)
+			if err != nil {
+				return nil, err
+			}
+			keys := []string{}
+			for _, leaf := range leaves {
+				keys = append(keys, leaf.Key)
+			}
+			return keys, nil
+		},
+	}
+}
+
+// Returns the layer of the generator JSON file of any supported version.
+// Only values present in the file are set. Missing file is skipped unless
+// it is required.
+func FileLayer(source string, path string, required bool) ConfigLayer {
+	return ConfigLayer{
+		Source: source,
+		Apply: func(g *Generator) ([]string, error) {
+			content, err := os.ReadFile(path)
+			if errors.Is(err, os.ErrNotExist) && !required {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			config, err := decodeConfig(content)
+			if err != nil {
+				return nil, err
+			}
+			if content, err = json.Marshal(config); err != nil {
+				return nil, err
+			}
+			if err := clearCollections(reflect.ValueOf(g).Elem(), content); err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(content, g); err != nil {
+				return nil, err
+			}
+			leaves, err := flattenJSON(content)
+			if err != nil {
+				return nil, err
+			}
+			known := configKeySet()
+			keys := []string{}
+			seen := map[string]bool{}
+			for _, leaf := range leaves {
+				key := configKey(leaf.Key, known)
+				if !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+			return keys, nil
+		},
+	}
+}
+
+// Returns the nearest known key of the flattened JSON key, so values of
+// maps belong to the map, e.g. "gs1_columns.17" to "gs1_columns".
+func configKey(key string, known map[string]bool) string {
+	for parent := key; ; {
+		if _, ok := known[parent]; ok {
+			return parent
+		}
+		i := strings.LastIndex(parent, ".")
+		if i < 0 {
+			return key
+		}
+		parent = parent[:i]
+	}
+}
+
+// Returns a layer of every EANBAKER_* variable of the environment,
+// e.g. from os.Environ. Value is JSON or a plain string as for SetValue.
+// Variables of unknown keys are logged and ignored.
+func EnvLayers(environ []string, log *slog.Logger) []ConfigLayer {
+	keys := map[string]string{}
+	for _, key := range ConfigKeys() {
+		keys[EnvName(key)] = key
+	}
+	type variable struct{ name, key, value string }
+	variables := []variable{}
+	unknown := []string{}
+	for _, env := range environ {
+		name, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		key, ok := keys[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		variables = append(variables, variable{name, key, value})
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		log.Warn("Unknown configuration variables are ignored", "variables", strings.Join(unknown, ", "))
+	}
+	// Whole objects are set before their values, e.g. EANBAKER_LAYOUT before EANBAKER_LAYOUT_FONT_SIZE.
+	sort.SliceStable(variables, func(i, j int) bool {
+		return strings.Count(variables[i].key, ".") < strings.Count(variables[j].key, ".")
+	})
+	layers := []ConfigLayer{}
+	for _, v := range variables {
+		layers = append(layers, ConfigLayer{
+			Source: "env " + v.name,
+			Apply: func(g *Generator) ([]string, error) {
+				return []string{v.key}, g.SetValue(v.key, v.value)
+			},
+		})
+	}
+	return layers
+}
+
+// Clears maps and slices of the struct that are set by the JSON object,
+// so values of the layer replace them instead of being merged with
+// values of earlier layers, e.g. gs1_columns.
+func clearCollections(v reflect.Value, content []byte) error {
+	object := map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &object); err != nil {
+		return err
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		raw, ok := object[name]
+		if !field.IsExported() || name == "-" || name == "" || !ok {
+			continue
+		}
+		value := v.Field(i)
+		if value.Kind() == reflect.Pointer && !value.IsNil() && value.Elem().Kind() == reflect.Struct {
+			value = value.Elem()
+		}
+		switch value.Kind() {
+		case reflect.Map, reflect.Slice:
+			value.Set(reflect.Zero(value.Type()))
+		case reflect.Struct:
+			if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+				if err := clearCollections(value, raw); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the layer that changes the generator by the function,
+// e.g. by command line flags. Changed values are the values of the layer.
+func FuncLayer(source string, apply func(g *Generator) error) ConfigLayer {
+	return ConfigLayer{
+		Source: source,
+		Apply: func(g *Generator) ([]string, error) {
+			before, err := generatorLeaves(g)
+			if err != nil {
+				return nil, err
+			}
+			if err := apply(g); err != nil {
+				return nil, err
+			}
+			after, err := generatorLeaves(g)
+			if err != nil {
+				return nil, err
+			}
+			values := map[string]string{}
+			for _, leaf := range before {
+				values[leaf.Key] = leaf.Value
+			}
+			keys := []string{}
+			for _, leaf := range after {
+				if value, ok := values[leaf.Key]; !ok || value != leaf.Value {
+					keys = append(keys, leaf.Key)
+				}
+			}
+			return keys, nil
+		},
+	}
+}
+
+// Returns the generator of the layers applied in their order with the
+// source of every value, the last layer that set it. Returns an error
+// of the first invalid layer.
+func ResolveConfig(layers ...ConfigLayer) (*ResolvedConfig, error) {
+	resolved := &ResolvedConfig{Sources: map[string]string{}}
+	for _, layer := range layers {
+		keys, err := layer.Apply(&resolved.Generator)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("Invalid configuration of %s", layer.Source), err)
+		}
+		leaves, err := generatorLeaves(&resolved.Generator)
+		if err != nil {
+			return nil, err
+		}
+		// Key of an object sets all its values.
+		for _, key := range keys {
+			resolved.Sources[key] = layer.Source
+			for _, leaf := range leaves {
+				if strings.HasPrefix(leaf.Key, key+".") {
+					resolved.Sources[leaf.Key] = layer.Source
+				}
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// Returns every value of the resolved generator with its source
+// in order of the generator JSON.
+func (r *ResolvedConfig) Values() ([]ResolvedValue, error) {
+	leaves, err := generatorLeaves(&r.Generator)
+	if err != nil {
+		return nil, err
+	}
+	for i := range leaves {
+		leaves[i].Source = r.Sources[leaves[i].Key]
+		if leaves[i].Source == "" {
+			leaves[i].Source = "default"
+		}
+	}
+	return leaves, nil
+}
+
+// Returns name of the environment variable of the JSON key.
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Returns JSON keys of all generator values including nested objects,
+// e.g. "serial" and "serial.start", in order of the generator fields.
+func ConfigKeys() []string {
+	return jsonKeys(reflect.TypeOf(Generator{}), "")
+}
+
+// Returns JSON keys of the struct fields and their nested struct fields.
+func jsonKeys(t reflect.Type, prefix string) []string {
+	keys := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" || name == "" {
+			continue
+		}
+		key := prefix + name
+		keys = append(keys, key)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct {
+			keys = append(keys, jsonKeys(fieldType, key+".")...)
+		}
+	}
+	return keys
+}
+
+// Returns values of the generator with their dotted JSON keys including
+// values omitted from JSON when empty.
+func generatorLeaves(g *Generator) ([]ResolvedValue, error) {
+	leaves := []ResolvedValue{}
+	if err := structLeaves(reflect.ValueOf(g).Elem(), "", &leaves); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// Appends values of the struct fields, nested structs are flattened.
+func structLeaves(v reflect.Value, prefix string, leaves *[]ResolvedValue) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" || name == "" {
+			continue
+		}
+		value := v.Field(i)
+		if value.Kind() == reflect.Pointer && !value.IsNil() && value.Elem().Kind() == reflect.Struct {
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Struct {
+			if err := structLeaves(value, prefix+name+".", leaves); err != nil {
+				return err
+			}
+			continue
+		}
+		content, err := json.Marshal(value.Interface())
+		if err != nil {
+			return err
+		}
+		*leaves = append(*leaves, ResolvedValue{Key: prefix + name, Value: string(content)})
+	}
+	return nil
+}
+
+// Returns values of the JSON object with their dotted keys in order of
+// the object. Arrays and empty objects are single values.
+func flattenJSON(content []byte) ([]ResolvedValue, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+		return nil, errors.New("Configuration must be a JSON object")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	leaves := []ResolvedValue{}
+	if err := flattenValue(decoder, "", &leaves); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// Reads the next JSON value of the decoder and appends its values.
+func flattenValue(decoder *json.Decoder, key string, leaves *[]ResolvedValue) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		count := 0
+		for decoder.More() {
+			name, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			child := name.(string)
+			if key != "" {
+				child = key + "." + child
+			}
+			if err := flattenValue(decoder, child, leaves); err != nil {
+				return err
+			}
+			count++
+		}
+		if count == 0 && key != "" {
+			*leaves = append(*leaves, ResolvedValue{Key: key, Value: "{}"})
+		}
+		_, err = decoder.Token()
+		return err
+	case json.Delim('['):
+		items := []any{}
+		for decoder.More() {
+			var item any
+			if err := decoder.Decode(&item); err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+		value, err := json.Marshal(items)
+		*leaves = append(*leaves, ResolvedValue{Key: key, Value: string(value)})
+		return err
+	}
+	value, err := json.Marshal(token)
+	*leaves = append(*leaves, ResolvedValue{Key: key, Value: string(value)})
+	return err
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "text_header", want: "EANBAKER_TEXT_HEADER"},
+		{key: "serial.start", want: "EANBAKER_SERIAL_START"},
+		{key: "layout.margins.top", want: "EANBAKER_LAYOUT_MARGINS_TOP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := EnvName(tt.key); got != tt.want {
+				t.Errorf("EnvName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvLayers(t *testing.T) {
+	tests := []struct {
+		name    string
+		environ []string
+		want    int
+		warning string
+	}{
+		{name: "other variables", environ: []string{"HOME=/root", "LOG=stderr"}, want: 0},
+		{name: "known keys", environ: []string{"EANBAKER_TEXT_HEADER=Name", "EANBAKER_SERIAL_START=5"}, want: 2},
+		{name: "nested object", environ: []string{"EANBAKER_LAYOUT_MARGINS_TOP=3"}, want: 1},
+		{name: "unknown key", environ: []string{"EANBAKER_TEXT_HEADER=Name", "EANBAKER_FOO=1"}, want: 1, warning: "EANBAKER_FOO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			layers := EnvLayers(tt.environ, slog.New(slog.NewTextHandler(&logs, nil)))
+			if len(layers) != tt.want {
+				t.Errorf("EnvLayers() returned %d layers, want %d", len(layers), tt.want)
+			}
+			if warned := strings.Contains(logs.String(), "level=WARN"); warned != (tt.warning != "") || !strings.Contains(logs.String(), tt.warning) {
+				t.Errorf("EnvLayers() logged %q, want warning %q", logs.String(), tt.warning)
+			}
+		})
+	}
+}
+
+func TestResolveConfig(t *testing.T) {
+	dir := t.TempDir()
+	store := ProfileStore{Dir: filepath.Join(dir, "profiles")}
+	write := func(path string, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(store.UserConfigPath(), `{"text_header": "User", "ean_header": "User", "serial": {"start": 5}, "gs1_columns": {"10": "Batch", "17": "Expiry"}}`)
+	project := filepath.Join(dir, "project.json")
+	write(project, `{"ean_header": "Project", "times_header": "Project", "gs1_columns": {"21": "Serial"}}`)
+	write(store.Path("shop"), `{"times_header": "Profile", "symbology": "qr"}`)
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	layers, err := store.ConfigLayers(project, "shop", []string{"EANBAKER_SYMBOLOGY=code128", "EANBAKER_SERIAL_STEP=3"}, log)
+	if err != nil {
+		t.Fatalf("ConfigLayers() failed: %v", err)
+	}
+	flags := FuncLayer("flag", func(g *Generator) error {
+		g.Serial.Step = 10
+		return nil
+	})
+	resolved, err := ResolveConfig(append(layers, flags)...)
+	if err != nil {
+		t.Fatalf("ResolveConfig() failed: %v", err)
+	}
+
+	values, err := resolved.Values()
+	if err != nil {
+		t.Fatalf("Values() failed: %v", err)
+	}
+	got := map[string]ResolvedValue{}
+	for _, value := range values {
+		got[value.Key] = value
+	}
+	want := []ResolvedValue{
+		{Key: "text_header", Value: `"User"`, Source: "user " + store.UserConfigPath()},
+		{Key: "ean_header", Value: `"Project"`, Source: "project " + project},
+		{Key: "times_header", Value: `"Profile"`, Source: "profile shop"},
+		{Key: "symbology", Value: `"code128"`, Source: "env EANBAKER_SYMBOLOGY"},
+		{Key: "serial.start", Value: "5", Source: "user " + store.UserConfigPath()},
+		{Key: "serial.step", Value: "10", Source: "flag"},
+		{Key: "symbology_header", Value: `""`, Source: "default"},
+		{Key: "templates.top", Value: `""`, Source: "default"},
+		{Key: "layout", Value: "null", Source: "default"},
+		{Key: "gs1_columns", Value: `{"21":"Serial"}`, Source: "project " + project},
+	}
+	for _, w := range want {
+		if got[w.Key] != w {
+			t.Errorf("Values()[%s] = %+v, want %+v", w.Key, got[w.Key], w)
+		}
+	}
+	if resolved.Generator.Serial.Start != 5 || resolved.Generator.Symbology != SymbologyCode128 {
+		t.Errorf("Generator = %+v, want resolved values", resolved.Generator)
+	}
+	if _, ok := resolved.Sources["gs1_columns.21"]; ok {
+		t.Errorf("Sources = %v, want values of maps under the map key", resolved.Sources)
+	}
+	// Maps of later files replace earlier ones.
+	if columns := resolved.Generator.GS1Columns; len(columns) != 1 || columns["21"] != "Serial" {
+		t.Errorf("GS1Columns = %v, want only the project columns", columns)
+	}
+}
+
+func TestResolveConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	store := ProfileStore{Dir: filepath.Join(dir, "profiles")}
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"times_each_ean": "many"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if _, err := store.ConfigLayers(filepath.Join(dir, "missing.json"), "missing", nil, log); err == nil {
+		t.Error("ConfigLayers() should fail for missing profile")
+	}
+	layers, err := store.ConfigLayers(filepath.Join(dir, "missing.json"), "", nil, log)
+	if err != nil {
+		t.Fatalf("ConfigLayers() failed for missing files: %v", err)
+	}
+	if _, err := ResolveConfig(layers...); err != nil {
+		t.Errorf("ResolveConfig() failed for missing files: %v", err)
+	}
+	if _, err := ResolveConfig(DefaultsLayer(), FileLayer("project", invalid, false)); err == nil {
+		t.Error("ResolveConfig() should fail for invalid file")
+	}
+	if _, err := ResolveConfig(DefaultsLayer(), FileLayer("profile", filepath.Join(dir, "missing.json"), true)); err == nil {
+		t.Error("ResolveConfig() should fail for missing required file")
+	}
+}
@@ -0,0 +1,206 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Rectangle on the label in millimeters, relative to the top left corner.
+// Box with zero width or height is not rendered.
+type Box struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Reports whether the box has no area and therefore is not rendered.
+func (b Box) Empty() bool {
+	return b.Width <= 0 || b.Height <= 0
+}
+
+// Scales position and size of the box.
+func (b Box) scale(x float64, y float64) Box {
+	return Box{X: b.X * x, Y: b.Y * y, Width: b.Width * x, Height: b.Height * y}
+}
+
+// Moves the box by the given offset.
+func (b Box) move(x float64, y float64) Box {
+	return Box{X: b.X + x, Y: b.Y + y, Width: b.Width, Height: b.Height}
+}
+
+type Margins struct {
+	Top    float64 `json:"top"`
+	Right  float64 `json:"right"`
+	Bottom float64 `json:"bottom"`
+	Left   float64 `json:"left"`
+}
+
+// Geometry of a single label in millimeters.
+// Text box holds the record text (top slot), barcode box the barcode image,
+// content box the human readable barcode content (bottom slot), side box
+// the side slot and price box the price with unit price. Side and price
+// boxes are not rendered by default.
+type Layout struct {
+	PageWidth  float64 `json:"page_width"`
+	PageHeight float64 `json:"page_height"`
+	Margins    Margins `json:"margins"`
+	Barcode    Box     `json:"barcode"`
+	Text       Box     `json:"text"`
+	Content    Box     `json:"content"`
+	Side       Box     `json:"side"`
+	Price      Box     `json:"price"`
+	FontSize   float64 `json:"font_size"`
+	// Width of a single barcode module (the narrowest bar) in mm, e.g. 0.33
+	// for EAN at 100 %. Barcode is stretched to the barcode box when zero.
+	ModuleWidth float64 `json:"module_width,omitempty"`
+}
+
+// Returns the original 30x15 mm label layout.
+func DefaultLayout() Layout {
+	return Layout{
+		PageWidth:  30,
+		PageHeight: 15,
+		Margins:    Margins{Top: 1, Right: 1, Bottom: 1, Left: 1},
+		Text:       Box{X: 1, Y: 1, Width: 27, Height: 4},
+		Barcode:    Box{X: 1.5, Y: 5, Width: 27, Height: 7},
+		Content:    Box{X: 1, Y: 12, Width: 28, Height: 2},
+		FontSize:   4,
+	}
+}
+
+// Returns the default layout scaled to the given page size.
+// Margins and boxes are scaled proportionally and font size
+// grows with the smaller of both scale factors.
+func NewLayout(width float64, height float64) Layout {
+	layout := DefaultLayout()
+	x := width / layout.PageWidth
+	y := height / layout.PageHeight
+	layout.PageWidth = width
+	layout.PageHeight = height
+	layout.Margins = Margins{
+		Top:    layout.Margins.Top * y,
+		Right:  layout.Margins.Right * x,
+		Bottom: layout.Margins.Bottom * y,
+		Left:   layout.Margins.Left * x,
+	}
+	layout.Text = layout.Text.scale(x, y)
+	layout.Barcode = layout.Barcode.scale(x, y)
+	layout.Content = layout.Content.scale(x, y)
+	layout.Side = layout.Side.scale(x, y)
+	layout.Price = layout.Price.scale(x, y)
+	layout.FontSize *= min(x, y)
+	return layout
+}
+
+// Parses label size in format WIDTHxHEIGHT in millimeters (e.g. "50x30")
+// and returns the default layout scaled to it.
+func LayoutFromSize(size string) (Layout, error) {
+	width_str, height_str, ok := strings.Cut(strings.ToLower(strings.TrimSpace(size)), "x")
+	if !ok {
+		return Layout{}, fmt.Errorf("Label size must be in format WIDTHxHEIGHT, got %q", size)
+	}
+	width, err := strconv.ParseFloat(strings.TrimSpace(width_str), 64)
+	if err != nil {
+		return Layout{}, fmt.Errorf("Invalid label width %q", width_str)
+	}
+	height, err := strconv.ParseFloat(strings.TrimSpace(height_str), 64)
+	if err != nil {
+		return Layout{}, fmt.Errorf("Invalid label height %q", height_str)
+	}
+	layout := NewLayout(width, height)
+	return layout, layout.Validate()
+}
+
+// Reads and deserializes a layout from a JSON file and validates it.
+func LoadLayout(path string) (Layout, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Layout{}, errors.Join(errors.New("Cannot load layout"), err)
+	}
+	defer file.Close()
+
+	var layout Layout
+	if err := json.NewDecoder(file).Decode(&layout); err != nil {
+		return Layout{}, errors.Join(errors.New("Cannot decode layout"), err)
+	}
+	return layout, layout.Validate()
+}
+
+// Validate checks that the page has positive size, margins leave
+// a printable area, font size is positive and every rendered box
+// fits on the page inside the margins.
+func (l Layout) Validate() error {
+	if l.PageWidth <= 0 || l.PageHeight <= 0 {
+		return fmt.Errorf("Page size must be positive, got %gx%g mm", l.PageWidth, l.PageHeight)
+	}
+	m := l.Margins
+	if m.Top < 0 || m.Right < 0 || m.Bottom < 0 || m.Left < 0 {
+		return errors.New("Page margins cannot be negative")
+	}
+	if m.Left+m.Right >= l.PageWidth || m.Top+m.Bottom >= l.PageHeight {
+		return errors.New("Page margins leave no printable area")
+	}
+	if l.FontSize <= 0 {
+		return fmt.Errorf("Font size must be positive, got %g", l.FontSize)
+	}
+	if l.ModuleWidth < 0 {
+		return fmt.Errorf("Module width cannot be negative, got %g", l.ModuleWidth)
+	}
+	if l.Barcode.Empty() {
+		return errors.New("Barcode box cannot be empty")
+	}
+	boxes := []struct {
+		name string
+		box  Box
+	}{
+		{"Barcode", l.Barcode},
+		{"Text", l.Text},
+		{"Content", l.Content},
+		{"Side", l.Side},
+		{"Price", l.Price},
+	}
+	for _, b := range boxes {
+		if b.box.Empty() {
+			continue
+		}
+		// Small tolerance for float rounding of scaled layouts.
+		const eps = 1e-9
+		if b.box.X+eps < m.Left || b.box.Y+eps < m.Top ||
+			b.box.X+b.box.Width > l.PageWidth-m.Right+eps ||
+			b.box.Y+b.box.Height > l.PageHeight-m.Bottom+eps {
+			return fmt.Errorf("%s box does not fit on the page inside margins", b.name)
+		}
+	}
+	return nil
+}
+
+// Returns the layout used for two dimensional codes.
+// Square code takes the left part of the printable area,
+// text and content boxes move to the right of it.
+func (l Layout) layout2D() Layout {
+	printableHeight := l.PageHeight - l.Margins.Top - l.Margins.Bottom
+	side := min(printableHeight, l.Barcode.Width)
+	ret := l
+	ret.Barcode = Box{
+		X:      l.Margins.Left,
+		Y:      l.Margins.Top + (printableHeight-side)/2,
+		Width:  side,
+		Height: side,
+	}
+	right := ret.Barcode.X + side + 1
+	width := max(0, l.PageWidth-l.Margins.Right-right)
+	contentHeight := 0.0
+	if !l.Content.Empty() {
+		contentHeight = l.Content.Height
+		ret.Content = Box{X: right, Y: l.PageHeight - l.Margins.Bottom - contentHeight, Width: width, Height: contentHeight}
+	}
+	if !l.Text.Empty() {
+		ret.Text = Box{X: right, Y: l.Margins.Top, Width: width, Height: printableHeight - contentHeight}
+	}
+	return ret
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultLayout_Validate(t *testing.T) {
+	layout := DefaultLayout()
+	if err := layout.Validate(); err != nil {
+		t.Errorf("DefaultLayout().Validate() failed: %v", err)
+	}
+}
+
+func TestNewLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  float64
+		height float64
+	}{
+		{name: "Default size", width: 30, height: 15},
+		{name: "40x25", width: 40, height: 25},
+		{name: "50x30", width: 50, height: 30},
+		{name: "62 mm roll", width: 62, height: 29},
+		{name: "Smaller than default", width: 20, height: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := NewLayout(tt.width, tt.height)
+			if layout.PageWidth != tt.width || layout.PageHeight != tt.height {
+				t.Errorf("NewLayout() page = %gx%g, want %gx%g", layout.PageWidth, layout.PageHeight, tt.width, tt.height)
+			}
+			if err := layout.Validate(); err != nil {
+				t.Errorf("NewLayout().Validate() failed: %v", err)
+			}
+			if err := layout.layout2D().Validate(); err != nil {
+				t.Errorf("layout2D().Validate() failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestLayoutFromSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    string
+		wantErr bool
+	}{
+		{name: "Integer size", size: "50x30"},
+		{name: "Decimal size with spaces", size: " 62.5 X 29 "},
+		{name: "Missing separator", size: "50", wantErr: true},
+		{name: "Invalid width", size: "ax30", wantErr: true},
+		{name: "Invalid height", size: "50xb", wantErr: true},
+		{name: "Zero size", size: "0x0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LayoutFromSize(tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LayoutFromSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLayout_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(l *Layout)
+		wantErr bool
+	}{
+		{name: "Valid", modify: func(l *Layout) {}},
+		{name: "Zero page width", modify: func(l *Layout) { l.PageWidth = 0 }, wantErr: true},
+		{name: "Negative margin", modify: func(l *Layout) { l.Margins.Left = -1 }, wantErr: true},
+		{name: "Margins over page", modify: func(l *Layout) { l.Margins.Top = 8; l.Margins.Bottom = 8 }, wantErr: true},
+		{name: "Zero font", modify: func(l *Layout) { l.FontSize = 0 }, wantErr: true},
+		{name: "Empty barcode", modify: func(l *Layout) { l.Barcode = Box{} }, wantErr: true},
+		{name: "Hidden text", modify: func(l *Layout) { l.Text = Box{} }},
+		{name: "Barcode over right edge", modify: func(l *Layout) { l.Barcode.Width = 30 }, wantErr: true},
+		{name: "Text in top margin", modify: func(l *Layout) { l.Text.Y = 0.5 }, wantErr: true},
+		{name: "Side box", modify: func(l *Layout) { l.Text.Width = 17; l.Side = Box{X: 19, Y: 1, Width: 9, Height: 4} }},
+		{name: "Side box over right edge", modify: func(l *Layout) { l.Side = Box{X: 25, Y: 1, Width: 9, Height: 4} }, wantErr: true},
+		{name: "Content over bottom edge", modify: func(l *Layout) { l.Content.Height = 3 }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := DefaultLayout()
+			tt.modify(&layout)
+			err := layout.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadLayout(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "layout-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	valid := filepath.Join(tmpDir, "valid.json")
+	err = os.WriteFile(valid, []byte(`{
+		"page_width": 40, "page_height": 25,
+		"margins": {"top": 1, "right": 1, "bottom": 1, "left": 1},
+		"barcode": {"x": 2, "y": 8, "width": 36, "height": 10},
+		"text": {"x": 1, "y": 1, "width": 38, "height": 6},
+		"content": {"x": 1, "y": 20, "width": 38, "height": 3},
+		"font_size": 6
+	}`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write layout: %v", err)
+	}
+	layout, err := LoadLayout(valid)
+	if err != nil {
+		t.Fatalf("LoadLayout() failed: %v", err)
+	}
+	if layout.PageWidth != 40 || layout.Barcode.Width != 36 || layout.FontSize != 6 {
+		t.Errorf("LoadLayout() = %+v", layout)
+	}
+
+	invalid := filepath.Join(tmpDir, "invalid.json")
+	err = os.WriteFile(invalid, []byte(`{"page_width": 40, "page_height": 25, "font_size": 6,
+		"barcode": {"x": 2, "y": 8, "width": 50, "height": 10}}`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write layout: %v", err)
+	}
+	if _, err := LoadLayout(invalid); err == nil {
+		t.Error("LoadLayout() should fail for barcode outside of the page")
+	}
+
+	if _, err := LoadLayout(filepath.Join(tmpDir, "missing.json")); err == nil {
+		t.Error("LoadLayout() should fail for missing file")
+	}
+}
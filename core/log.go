@@ -14,6 +14,7 @@ type MultiLogger struct {
 	*slog.Logger
 	buffer *syncBuffer
 	multi  io.Writer
+	level  slog.Level
 }
 
 // thread-safe buffer
@@ -69,9 +70,16 @@ func NewMultiLogger(level slog.Level) *MultiLogger {
 		Logger: slog.New(handler),
 		buffer: buf,
 		multi:  multi,
+		level:  level,
 	}
 }
 
+// Returns a logger with the same level that writes only to the terminal.
+// Long running commands use it, so their logs are not kept in memory.
+func (l *MultiLogger) TerminalLogger() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: l.level}))
+}
+
 // SaveToFile writes all buffered logs to a file
 func (l *MultiLogger) SaveToFile(path string) error {
 	return os.WriteFile(path, l.buffer.Bytes(), 0644)
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -113,6 +114,18 @@ func TestMultiLogger_Logging(t *testing.T) {
 	}
 }
 
+func TestMultiLogger_TerminalLogger(t *testing.T) {
+	logger := NewMultiLogger(slog.LevelWarn)
+	terminal := logger.TerminalLogger()
+	terminal.Warn("terminal message")
+	if strings.Contains(string(logger.buffer.Bytes()), "terminal message") {
+		t.Error("TerminalLogger() should not write to the buffer")
+	}
+	if terminal.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("TerminalLogger() should keep the level of the logger")
+	}
+}
+
 func TestMultiLogger_LevelFiltering(t *testing.T) {
 	// Create logger with INFO level - should filter out DEBUG
 	logger := NewMultiLogger(slog.LevelInfo)
@@ -0,0 +1,234 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const odsMimetype = "application/vnd.oasis.opendocument.spreadsheet"
+
+// Returns names of all sheets of the OpenDocument spreadsheet in their order.
+func OdsSheetNames(r io.Reader) ([]string, error) {
+	tables, err := readOds(r)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, table := range tables {
+		names = append(names, table.Sheet)
+	}
+	return names, nil
+}
+
+// Reads OpenDocument spreadsheet (.ods) data from an io.Reader and returns
+// the selected sheet, or all sheets in document order if merge is set.
+// Sheet is selected by name or one based position as in FindSheet.
+func SheetTablesFromOds(r io.Reader, sheet string, merge bool) ([]SheetTable, error) {
+	tables, err := readOds(r)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, table := range tables {
+		names = append(names, table.Sheet)
+	}
+	selected, err := selectSheets(names, sheet, merge)
+	if err != nil {
+		return nil, err
+	}
+	ret := []SheetTable{}
+	for _, table := range tables {
+		for _, name := range selected {
+			if table.Sheet == name {
+				ret = append(ret, table)
+			}
+		}
+	}
+	return ret, nil
+}
+
+// Reads all sheets of the OpenDocument spreadsheet.
+func readOds(r io.Reader) ([]SheetTable, error) {
+	if r == nil {
+		return nil, errors.New("Reader is <nil>")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.Join(errors.New("Cannot open ods file"), err)
+	}
+	for _, file := range archive.File {
+		if file.Name != "content.xml" {
+			continue
+		}
+		content, err := file.Open()
+		if err != nil {
+			return nil, errors.Join(errors.New("Cannot open ods content"), err)
+		}
+		defer content.Close()
+		return parseOdsContent(content)
+	}
+	return nil, errors.New("Cannot open ods file, content.xml is missing")
+}
+
+// Returns value of the attribute with the local name, or empty string.
+func odsAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// Returns the repeat count given by the attribute, at least one.
+func odsRepeat(element xml.StartElement, name string) int {
+	n, err := strconv.Atoi(odsAttr(element, name))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// Parses content.xml of the OpenDocument spreadsheet into tables. Cells
+// are read as displayed, numbers use their raw value so long codes are not
+// shown in scientific notation. Repeated rows and cells are expanded, but
+// trailing empty rows and cells that fill the whole sheet are dropped.
+func parseOdsContent(r io.Reader) ([]SheetTable, error) {
+	decoder := xml.NewDecoder(r)
+	tables := []SheetTable{}
+	// Index of the current table, -1 outside of tables. Nested
+	// tables are read as a part of the outer table.
+	current, depth := -1, 0
+	var row []string
+	var text strings.Builder
+	inCell, inParagraph, paragraphs := false, false, 0
+	// Cell comments are not a part of the cell text.
+	annotations := 0
+	rowRepeat, cellRepeat := 1, 1
+	value := ""
+	// Empty rows and cells are added only when something follows them.
+	pendingRows, pendingCells := 0, 0
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Join(errors.New("Cannot parse ods content"), err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "annotation" {
+				annotations++
+			}
+			if annotations > 0 {
+				continue
+			}
+			switch t.Name.Local {
+			case "table":
+				depth++
+				if depth > 1 {
+					continue
+				}
+				tables = append(tables, SheetTable{Sheet: odsAttr(t, "name"), Table: Table{}})
+				current = len(tables) - 1
+				pendingRows = 0
+			case "table-row":
+				row = []string{}
+				rowRepeat = odsRepeat(t, "number-rows-repeated")
+				pendingCells = 0
+			case "table-cell", "covered-table-cell":
+				inCell, paragraphs = true, 0
+				cellRepeat = odsRepeat(t, "number-columns-repeated")
+				text.Reset()
+				value = ""
+				switch odsAttr(t, "value-type") {
+				case "float", "percentage", "currency":
+					value = odsAttr(t, "value")
+				}
+			case "p":
+				if inCell {
+					if paragraphs > 0 {
+						text.WriteString("\n")
+					}
+					paragraphs++
+					inParagraph = true
+				}
+			case "s":
+				if inCell {
+					text.WriteString(strings.Repeat(" ", odsRepeat(t, "c")))
+				}
+			case "tab":
+				if inCell {
+					text.WriteString("\t")
+				}
+			case "line-break":
+				if inCell {
+					text.WriteString("\n")
+				}
+			}
+		case xml.CharData:
+			if inCell && inParagraph {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			if t.Name.Local == "annotation" {
+				annotations--
+			}
+			if annotations > 0 {
+				continue
+			}
+			switch t.Name.Local {
+			case "p":
+				inParagraph = false
+			case "table-cell", "covered-table-cell":
+				inCell = false
+				cell := text.String()
+				if value != "" {
+					cell = value
+				}
+				if cell == "" {
+					pendingCells += cellRepeat
+					continue
+				}
+				for ; pendingCells > 0; pendingCells-- {
+					row = append(row, "")
+				}
+				for i := 0; i < cellRepeat; i++ {
+					row = append(row, cell)
+				}
+			case "table-row":
+				if current < 0 {
+					continue
+				}
+				if len(row) == 0 {
+					pendingRows += rowRepeat
+					continue
+				}
+				table := &tables[current]
+				for ; pendingRows > 0; pendingRows-- {
+					table.Table = append(table.Table, []string{})
+				}
+				for i := 0; i < rowRepeat; i++ {
+					table.Table = append(table.Table, append([]string{}, row...))
+				}
+			case "table":
+				depth--
+				if depth == 0 {
+					current = -1
+				}
+			}
+		}
+	}
+	return tables, nil
+}
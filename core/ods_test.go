@@ -0,0 +1,136 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Creates an in memory OpenDocument spreadsheet with the given
+// body of office:spreadsheet element.
+func odsFile(t *testing.T, spreadsheet string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	files := []struct{ name, content string }{
+		{"mimetype", odsMimetype},
+		{"content.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content
+	xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:spreadsheet>` + spreadsheet + `</office:spreadsheet></office:body>
+</office:document-content>`},
+	}
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			t.Fatalf("Cannot create ods: %v", err)
+		}
+		w.Write([]byte(file.content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Cannot create ods: %v", err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+const odsTwoSheets = `
+<table:table table:name="Products">
+	<table:table-column table:number-columns-repeated="3"/>
+	<table:table-header-rows>
+		<table:table-row>
+			<table:table-cell office:value-type="string"><text:p>Name</text:p></table:table-cell>
+			<table:table-cell office:value-type="string"><text:p>EAN</text:p></table:table-cell>
+		</table:table-row>
+	</table:table-header-rows>
+	<table:table-row>
+		<table:table-cell office:value-type="string"><text:p>Milk <text:span>fresh</text:span></text:p><text:p>1<text:s text:c="2"/>l</text:p>
+			<office:annotation><text:p>Comment</text:p></office:annotation>
+		</table:table-cell>
+		<table:table-cell office:value-type="float" office:value="4006381333931"><text:p>4.01E+12</text:p></table:table-cell>
+	</table:table-row>
+	<table:table-row table:number-rows-repeated="2">
+		<table:table-cell table:number-columns-repeated="2"/>
+	</table:table-row>
+	<table:table-row table:number-rows-repeated="2">
+		<table:table-cell/>
+		<table:table-cell office:value-type="string"><text:p>96385074</text:p></table:table-cell>
+		<table:table-cell table:number-columns-repeated="16382"/>
+	</table:table-row>
+	<table:table-row table:number-rows-repeated="1048570">
+		<table:table-cell table:number-columns-repeated="16384"/>
+	</table:table-row>
+</table:table>
+<table:table table:name="Prices">
+	<table:table-row>
+		<table:table-cell office:value-type="string"><text:p>Price</text:p></table:table-cell>
+	</table:table-row>
+</table:table>`
+
+func TestSheetTablesFromOds(t *testing.T) {
+	tables, err := SheetTablesFromOds(odsFile(t, odsTwoSheets), "", false)
+	if err != nil {
+		t.Fatalf("SheetTablesFromOds() failed: %v", err)
+	}
+	if len(tables) != 1 || tables[0].Sheet != "Products" {
+		t.Fatalf("SheetTablesFromOds() = %v, want sheet Products", tables)
+	}
+	want := Table{
+		{"Name", "EAN"},
+		{"Milk fresh\n1  l", "4006381333931"},
+		{},
+		{},
+		{"", "96385074"},
+		{"", "96385074"},
+	}
+	got := tables[0].Table
+	if len(got) != len(want) {
+		t.Fatalf("SheetTablesFromOds() table = %q, want %q", got, want)
+	}
+	for i := range want {
+		if strings.Join(got[i], "\x00") != strings.Join(want[i], "\x00") {
+			t.Errorf("SheetTablesFromOds() row %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	tables, err = SheetTablesFromOds(odsFile(t, odsTwoSheets), "prices", false)
+	if err != nil {
+		t.Fatalf("SheetTablesFromOds() by name failed: %v", err)
+	}
+	if len(tables) != 1 || tables[0].Table[0][0] != "Price" {
+		t.Errorf("SheetTablesFromOds() by name = %v, want sheet Prices", tables)
+	}
+
+	tables, err = SheetTablesFromOds(odsFile(t, odsTwoSheets), "", true)
+	if err != nil {
+		t.Fatalf("SheetTablesFromOds() merge failed: %v", err)
+	}
+	if len(tables) != 2 || tables[1].Sheet != "Prices" {
+		t.Errorf("SheetTablesFromOds() merge = %v, want both sheets", tables)
+	}
+
+	if _, err := SheetTablesFromOds(odsFile(t, odsTwoSheets), "Stock", false); err == nil {
+		t.Error("SheetTablesFromOds() should fail for unknown sheet")
+	}
+}
+
+func TestOdsSheetNames(t *testing.T) {
+	names, err := OdsSheetNames(odsFile(t, odsTwoSheets))
+	if err != nil {
+		t.Fatalf("OdsSheetNames() failed: %v", err)
+	}
+	if strings.Join(names, ",") != "Products,Prices" {
+		t.Errorf("OdsSheetNames() = %v, want [Products Prices]", names)
+	}
+}
+
+func TestSheetTablesFromOds_InvalidData(t *testing.T) {
+	if _, err := SheetTablesFromOds(strings.NewReader("name,ean"), "", false); err == nil {
+		t.Error("SheetTablesFromOds() should fail for non zip data")
+	}
+	if _, err := SheetTablesFromOds(nil, "", false); err == nil {
+		t.Error("SheetTablesFromOds() should fail for nil reader")
+	}
+}
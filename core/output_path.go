@@ -0,0 +1,211 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Policy applied when the PDF file already exists.
+type OverwritePolicy string
+
+const (
+	// Replace the existing file.
+	OverwriteReplace OverwritePolicy = "overwrite"
+	// Keep the existing file and fail.
+	OverwriteFail OverwritePolicy = "fail"
+	// Add the first free number to the name, e.g. "labels-1.pdf".
+	OverwriteIncrement OverwritePolicy = "increment"
+)
+
+// List of all overwrite policies.
+var OverwritePolicies = []OverwritePolicy{
+	OverwriteReplace,
+	OverwriteFail,
+	OverwriteIncrement,
+}
+
+// Maximal number added to the name by the increment policy.
+const MaxOutputIncrement = 9999
+
+// Default layout of the {date} placeholder.
+const DefaultDateLayout = "2006-01-02"
+
+// Converts a string to an OverwritePolicy, matching is case insensitive.
+// Empty string is converted to the default overwrite policy.
+func OverwritePolicyFromString(s string) (OverwritePolicy, error) {
+	normalized := strings.ToLower(strings.TrimSpace(s))
+	if normalized == "" {
+		return OverwriteReplace, nil
+	}
+	for _, policy := range OverwritePolicies {
+		if string(policy) == normalized {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown overwrite policy %q, expected overwrite, fail or increment", s)
+}
+
+// Implements the json.Unmarshaler interface for OverwritePolicy.
+// Accepts the same values as OverwritePolicyFromString.
+func (p *OverwritePolicy) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	policy, err := OverwritePolicyFromString(s)
+	if err != nil {
+		return err
+	}
+	*p = policy
+	return nil
+}
+
+// Values of placeholders of output path templates.
+type OutputPathValues struct {
+	// Input path, {input} is its name without extension and {dir} its directory.
+	Input string
+	// Name of the active profile, {profile} is "default" if it is empty.
+	Profile string
+	// Time of {date}, e.g. "{date:2006-01-02_15-04}" with Go time layout.
+	Time time.Time
+}
+
+// Returns the path with placeholders replaced by the values. Placeholders
+// are {input}, {dir}, {profile} and {date} with optional Go time layout,
+// e.g. "out/{input}_{date:2006-01-02}_{profile}.pdf". Braces are written
+// twice to be literal, e.g. "{{draft}}.pdf". Returns an error for unknown
+// placeholders and for {input} or {dir} without input.
+func ExpandOutputPath(template string, values OutputPathValues) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if (c == '{' || c == '}') && i+1 < len(template) && template[i+1] == c {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		if c == '}' {
+			return "", fmt.Errorf("Unexpected } in output path %q, literal brace is written as }}", template)
+		}
+		if c != '{' {
+			b.WriteByte(c)
+			continue
+		}
+		end := strings.IndexByte(template[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("Unclosed placeholder in output path %q", template)
+		}
+		value, err := placeholderValue(template[i+1:i+end], values)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(value)
+		i += end
+	}
+	return b.String(), nil
+}
+
+// Returns the value of the placeholder without braces, e.g. "date:2006".
+func placeholderValue(placeholder string, values OutputPathValues) (string, error) {
+	name, arg, hasArg := strings.Cut(placeholder, ":")
+	switch name {
+	case "input", "dir":
+		if values.Input == "" {
+			return "", fmt.Errorf("Placeholder {%s} needs an input file", name)
+		}
+		if name == "dir" {
+			return filepath.Dir(values.Input), nil
+		}
+		if values.Input == StdStream {
+			return "stdin", nil
+		}
+		return strings.TrimSuffix(filepath.Base(values.Input), filepath.Ext(values.Input)), nil
+	case "profile":
+		if values.Profile == "" {
+			return "default", nil
+		}
+		return values.Profile, nil
+	case "date":
+		if !hasArg || arg == "" {
+			arg = DefaultDateLayout
+		}
+		return values.Time.Format(arg), nil
+	}
+	return "", fmt.Errorf("Unknown placeholder {%s}, expected {input}, {dir}, {profile} or {date}", placeholder)
+}
+
+// Returns the path with braces escaped, so it is not expanded as a template.
+func escapeOutputPath(path string) string {
+	return strings.NewReplacer("{", "{{", "}", "}}").Replace(path)
+}
+
+// Reports whether the output path is a directory, it ends with
+// a separator or it is an existing directory.
+func isOutputDir(path string) bool {
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(filepath.Separator)) {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// Returns the path of the PDF given by the PDF path template. PDF of a
+// directory is named by the input. Existing PDF is handled by the overwrite
+// policy, see FreeOutputPath. Missing directories are created when the PDF
+// is saved, so the result is meant for Generator.OutputFile.
+func (g *Generator) OutputPath(profile string, now time.Time) (string, error) {
+	if g.PdfPath == StdStream {
+		return StdStream, nil
+	}
+	template := g.PdfPath
+	if template == "" {
+		template = "{input}.pdf"
+	}
+	path, err := ExpandOutputPath(template, OutputPathValues{Input: g.CsvPath, Profile: profile, Time: now})
+	if err != nil {
+		return "", err
+	}
+	if isOutputDir(path) {
+		name, err := placeholderValue("input", OutputPathValues{Input: g.CsvPath})
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(path, name+".pdf")
+	}
+	policy, err := OverwritePolicyFromString(string(g.OverwritePolicy))
+	if err != nil {
+		return "", err
+	}
+	return FreeOutputPath(path, policy)
+}
+
+// Returns the path the PDF is written to by the policy when the path
+// already exists: the same path, an error, or the path with the first
+// free number added to the name, e.g. "labels-2.pdf".
+func FreeOutputPath(path string, policy OverwritePolicy) (string, error) {
+	if policy == OverwriteReplace || policy == "" || !exists(path) {
+		return path, nil
+	}
+	if policy == OverwriteFail {
+		return "", fmt.Errorf("Output '%s' already exists", path)
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; i <= MaxOutputIncrement; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, i, ext)
+		if !exists(candidate) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("Output '%s' and its %d numbered versions already exist", path, MaxOutputIncrement)
+}
+
+// Reports whether a file or directory exists at the path.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
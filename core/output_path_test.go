@@ -0,0 +1,211 @@
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOverwritePolicyFromString(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    OverwritePolicy
+		wantErr bool
+	}{
+		{value: "", want: OverwriteReplace},
+		{value: "overwrite", want: OverwriteReplace},
+		{value: " Fail ", want: OverwriteFail},
+		{value: "INCREMENT", want: OverwriteIncrement},
+		{value: "skip", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := OverwritePolicyFromString(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OverwritePolicyFromString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("OverwritePolicyFromString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverwritePolicy_UnmarshalJSON(t *testing.T) {
+	var p OverwritePolicy
+	if err := json.Unmarshal([]byte(`"increment"`), &p); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	if p != OverwriteIncrement {
+		t.Errorf("Unmarshal() = %v, want %v", p, OverwriteIncrement)
+	}
+	if err := json.Unmarshal([]byte(`"skip"`), &p); err == nil {
+		t.Error("Unmarshal() should fail for unknown policy")
+	}
+}
+
+func TestExpandOutputPath(t *testing.T) {
+	values := OutputPathValues{
+		Input:   filepath.Join("in", "prices.v2.xlsx"),
+		Profile: "shop",
+		Time:    time.Date(2025, 3, 7, 14, 5, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		name     string
+		template string
+		values   OutputPathValues
+		want     string
+		wantErr  bool
+	}{
+		{name: "plain path", template: "labels.pdf", values: values, want: "labels.pdf"},
+		{name: "input", template: "{input}.pdf", values: values, want: "prices.v2.pdf"},
+		{name: "all placeholders", template: "{dir}/{input}_{date:2006-01-02}_{profile}.pdf", values: values, want: "in/prices.v2_2025-03-07_shop.pdf"},
+		{name: "default date layout", template: "{date}.pdf", values: values, want: "2025-03-07.pdf"},
+		{name: "time layout", template: "{date:20060102-1504}.pdf", values: values, want: "20250307-1405.pdf"},
+		{name: "no profile", template: "{profile}.pdf", values: OutputPathValues{}, want: "default.pdf"},
+		{name: "standard input", template: "{input}.pdf", values: OutputPathValues{Input: StdStream}, want: "stdin.pdf"},
+		{name: "literal braces", template: "{{draft}}.pdf", values: values, want: "{draft}.pdf"},
+		{name: "unknown placeholder", template: "{name}.pdf", values: values, wantErr: true},
+		{name: "unclosed placeholder", template: "{input.pdf", values: values, wantErr: true},
+		{name: "single closing brace", template: "input}.pdf", values: values, wantErr: true},
+		{name: "input without input", template: "{input}.pdf", values: OutputPathValues{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandOutputPath(tt.template, tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandOutputPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != filepath.FromSlash(tt.want) && got != tt.want {
+				t.Errorf("ExpandOutputPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeOutputPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "labels.pdf")
+	for _, name := range []string{"labels.pdf", "labels-1.pdf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name    string
+		path    string
+		policy  OverwritePolicy
+		want    string
+		wantErr bool
+	}{
+		{name: "overwrite", path: path, policy: OverwriteReplace, want: path},
+		{name: "fail", path: path, policy: OverwriteFail, wantErr: true},
+		{name: "increment", path: path, policy: OverwriteIncrement, want: filepath.Join(dir, "labels-2.pdf")},
+		{name: "missing file", path: filepath.Join(dir, "new.pdf"), policy: OverwriteFail, want: filepath.Join(dir, "new.pdf")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FreeOutputPath(tt.path, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FreeOutputPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FreeOutputPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerator_OutputPath(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+	if err := os.WriteFile(filepath.Join(dir, "data.pdf"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		gen     Generator
+		want    string
+		wantErr bool
+	}{
+		{name: "standard output", gen: Generator{CsvPath: StdStream, PdfPath: StdStream}, want: StdStream},
+		{name: "template", gen: Generator{CsvPath: "data.csv", PdfPath: filepath.Join(dir, "out", "{date}", "{input}_{profile}.pdf")}, want: filepath.Join(dir, "out", "2025-03-07", "data_zebra.pdf")},
+		{name: "directory", gen: Generator{CsvPath: "data.csv", PdfPath: dir + string(filepath.Separator)}, want: filepath.Join(dir, "data.pdf")},
+		{name: "existing directory", gen: Generator{CsvPath: "data.csv", PdfPath: dir, OverwritePolicy: OverwriteIncrement}, want: filepath.Join(dir, "data-1.pdf")},
+		{name: "existing file", gen: Generator{CsvPath: "data.csv", PdfPath: filepath.Join(dir, "data.pdf"), OverwritePolicy: OverwriteFail}, wantErr: true},
+		{name: "invalid template", gen: Generator{CsvPath: "data.csv", PdfPath: "{data}.pdf"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.gen.OutputPath("zebra", now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OutputPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("OutputPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	// Directories are created when the PDF is saved.
+	if _, err := os.Stat(filepath.Join(dir, "out")); !os.IsNotExist(err) {
+		t.Errorf("OutputPath() created the directory: %v", err)
+	}
+}
+
+func TestGenerator_OutputPath_Generate(t *testing.T) {
+	dir := t.TempDir()
+	// PDF of the input is named by the input in the current directory.
+	t.Chdir(dir)
+	input := "data{1}.csv"
+	if err := os.WriteFile(input, []byte("Text,EAN\nA,5901234123457\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tests := []struct {
+		name    string
+		pdfPath string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{name: "braces in input name", want: "data{1}.pdf"},
+		{name: "literal braces", pdfPath: filepath.Join(dir, "new", "{{draft}}.pdf"), want: filepath.Join(dir, "new", "{draft}.pdf")},
+		{name: "failed run", pdfPath: filepath.Join(dir, "failed", "{input}.pdf"), content: "Name,Code\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := Generator{CsvPath: input, PdfPath: tt.pdfPath, TextHeader: "Text", EanHeader: "EAN", TimesEachEAN: 1}
+			gen.UpdatePdfPath()
+			path, err := gen.OutputPath("", time.Now())
+			if err != nil {
+				t.Fatalf("OutputPath() failed: %v", err)
+			}
+			gen.OutputFile = path
+			content := tt.content
+			if content == "" {
+				content = "Text,EAN\nA,5901234123457\n"
+			}
+			err = gen.GenerateToWriter(input, strings.NewReader(content), nil, log)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateToWriter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+					t.Errorf("Failed run left directory %s", filepath.Dir(path))
+				}
+				return
+			}
+			if path != tt.want {
+				t.Errorf("OutputPath() = %q, want %q", path, tt.want)
+			}
+			if _, err := os.Stat(tt.want); err != nil {
+				t.Errorf("PDF not written: %v", err)
+			}
+		})
+	}
+}
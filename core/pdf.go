@@ -2,97 +2,503 @@ package core
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"codeberg.org/go-pdf/fpdf"
+	"github.com/boombuler/barcode"
+	"golang.org/x/text/encoding/charmap"
 )
 
 type Pdf struct {
-	pdf *fpdf.Fpdf
+	pdf    *fpdf.Fpdf
+	layout Layout
+	// Sheet the labels are tiled on, each label is on its own page if nil.
+	sheet *LabelSheet
+	// Zero based position of the next label on the sheet.
+	position int
+	// Templates of the label slots, default texts are used when not set.
+	templates labelTemplates
+	// Formatting of prices drawn to the price box.
+	priceFormat PriceFormat
+	// How barcodes are drawn, vector when empty.
+	renderer BarcodeRenderer
+	// Draw EAN and UPC codes as GS1 symbols with light margins and digits under bars.
+	gs1 bool
+	// Magnification of GS1 symbols, module width of the layout is used when zero.
+	magnification float64
+	// Serial numbers of labels, Last is updated as labels are added.
+	serial SerialNumbers
+	// Every label copy added or skipped, in order.
+	report []ReportEntry
+}
+
+// Barcode of a label prepared for drawing, placeholder is drawn if both are empty.
+type labelBarcode struct {
+	// Encoded barcode drawn by the vector renderer.
+	code barcode.Barcode
+	// EAN or UPC symbol of the code drawn by GS1 rendering.
+	ean *eanSymbol
+	// Path to the barcode image drawn by the PNG renderer.
+	image string
 }
 
 // Creates and configures a new PDF document for barcode generation.
-// Sets up landscape orientation with custom dimensions (15x30mm) suitable for barcode labels.
-// Disables auto page breaks and removes top margin for optimal barcode layout.
+// Uses the default layout with landscape 15x30mm page suitable for barcode labels.
 func NewPdf() Pdf {
-	// Create pdf
+	pdf, _ := NewPdfWithLayout(DefaultLayout())
+	return pdf
+}
+
+// Creates and configures a new PDF document with page size given by the layout.
+// Disables auto page breaks and removes top margin for optimal barcode layout.
+// Returns an error if the layout is not valid.
+func NewPdfWithLayout(layout Layout) (Pdf, error) {
+	if err := layout.Validate(); err != nil {
+		return Pdf{}, err
+	}
+	// Create pdf, landscape pages are described by swapped size.
+	orientation := "P"
+	size := fpdf.SizeType{Wd: layout.PageWidth, Ht: layout.PageHeight}
+	if layout.PageWidth > layout.PageHeight {
+		orientation = "L"
+		size = fpdf.SizeType{Wd: layout.PageHeight, Ht: layout.PageWidth}
+	}
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		OrientationStr: orientation,
+		UnitStr:        "mm",
+		Size:           size,
+	})
+	pdf.SetTopMargin(0)
+	pdf.SetAutoPageBreak(false, 0)
+	return Pdf{pdf: pdf, layout: layout}, nil
+}
+
+// Creates and configures a new PDF document that tiles labels on sheets.
+// Label layout must have the same size as labels of the sheet. Start is
+// the one based position of the first label, which allows to reuse partially
+// used sheets. Returns an error if the layout, sheet or start is not valid.
+func NewPdfOnSheet(layout Layout, sheet LabelSheet, start int) (Pdf, error) {
+	if err := layout.Validate(); err != nil {
+		return Pdf{}, err
+	}
+	if err := sheet.Validate(); err != nil {
+		return Pdf{}, err
+	}
+	const eps = 0.01
+	if math.Abs(layout.PageWidth-sheet.LabelWidth) > eps || math.Abs(layout.PageHeight-sheet.LabelHeight) > eps {
+		return Pdf{}, fmt.Errorf(
+			"Label layout size %gx%g mm does not match sheet label size %gx%g mm",
+			layout.PageWidth, layout.PageHeight, sheet.LabelWidth, sheet.LabelHeight)
+	}
+	if start < 1 || start > sheet.PerPage() {
+		return Pdf{}, fmt.Errorf("Start position must be between 1 and %d, got %d", sheet.PerPage(), start)
+	}
 	pdf := fpdf.NewCustom(&fpdf.InitType{
-		OrientationStr: "L",
+		OrientationStr: "P",
 		UnitStr:        "mm",
-		Size: fpdf.SizeType{
-			Wd: 15,
-			Ht: 30,
-		},
+		Size:           fpdf.SizeType{Wd: sheet.PageWidth, Ht: sheet.PageHeight},
 	})
 	pdf.SetTopMargin(0)
 	pdf.SetAutoPageBreak(false, 0)
-	return Pdf{pdf: pdf}
+	return Pdf{pdf: pdf, layout: layout, sheet: &sheet, position: start - 1}, nil
+}
+
+// Sets templates of the label slots used for the following pages.
+// Returns an error if a template cannot be parsed.
+func (p *Pdf) SetTemplates(templates LabelTemplates) error {
+	parsed, err := templates.parse()
+	if err != nil {
+		return err
+	}
+	p.templates = parsed
+	return nil
+}
+
+// Sets formatting of prices used for the following pages.
+// Returns an error if the format is not valid.
+func (p *Pdf) SetPriceFormat(format PriceFormat) error {
+	if err := format.Validate(); err != nil {
+		return err
+	}
+	p.priceFormat = format
+	return nil
+}
+
+// Sets how barcodes of the following pages are drawn.
+// Returns an error if the renderer is not known.
+func (p *Pdf) SetBarcodeRenderer(renderer BarcodeRenderer) error {
+	renderer, err := BarcodeRendererFromString(string(renderer))
+	if err != nil {
+		return err
+	}
+	p.renderer = renderer
+	return nil
+}
+
+// Enables GS1 rendering of EAN and UPC codes used for the following pages:
+// light margins, extended guard bars and digits split into groups under
+// the bars. Module width is given by magnification (1 is 0.33 mm) when not zero.
+// Returns an error if the magnification is out of the allowed range.
+func (p *Pdf) SetGS1Rendering(enabled bool, magnification float64) error {
+	if err := validateMagnification(magnification); err != nil {
+		return err
+	}
+	p.gs1, p.magnification = enabled, magnification
+	return nil
+}
+
+// Sets serial numbers of the following labels.
+// Returns an error if the serial numbers are not valid.
+func (p *Pdf) SetSerialNumbers(serial SerialNumbers) error {
+	if err := serial.Validate(); err != nil {
+		return err
+	}
+	p.serial = serial
+	return nil
+}
+
+// Returns every label copy added to the document or skipped with
+// its page, position and status.
+func (p *Pdf) Report() []ReportEntry {
+	return p.report
+}
+
+// Returns serial numbers with the last number issued to a label.
+func (p *Pdf) SerialNumbers() SerialNumbers {
+	return p.serial
 }
 
 // Adds barcode pages to the PDF for each record.
-// Creates temporary barcode images and adds the specified number of pages per record.
-// Each page contains the record text, barcode image, and EAN number.
+// Adds the specified number of pages per record, PNG renderer creates
+// temporary barcode images. Each page contains the record text, barcode and EAN number.
 func (p *Pdf) AddPages(records []Record, times uint, log *slog.Logger) error {
+	_, err := p.AddPagesWithPolicy(records, times, PolicyFailFast, log)
+	return err
+}
+
+// Adds barcode pages to the PDF for each record as AddPages does.
+// Records whose barcode or label text cannot be generated are handled by the policy:
+// fail-fast returns the error, skip-invalid leaves them out and placeholder
+// renders a label marked as INVALID. With serial numbers every copy
+// is a label of its own with the next number. Returns the records that failed.
+func (p *Pdf) AddPagesWithPolicy(records []Record, times uint, policy ErrorPolicy, log *slog.Logger) ([]SkippedRecord, error) {
 	if times == 0 {
 		const ERR_MSG string = "Bar code must be added at lease once time."
 		log.Error(ERR_MSG)
-		return errors.New(ERR_MSG)
+		return nil, errors.New(ERR_MSG)
 	}
-	// Create temporary directory
-	dir, err := os.MkdirTemp("", "generate-barcodes-*")
-	if err != nil {
-		log.Error("Failed to create temporary dir", "err", err)
-		return err
+	// Create temporary directory for images
+	dir := ""
+	if p.renderer == RendererPng {
+		var err error
+		dir, err = os.MkdirTemp("", "generate-barcodes-*")
+		if err != nil {
+			log.Error("Failed to create temporary dir", "err", err)
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
 	}
-	defer os.RemoveAll(dir)
 
 	// Add records to pdf
-	for _, record := range records {
-		barcode_path := filepath.Join(dir, record.Ean+".png")
-		err := record.GenerateBarcode(barcode_path)
+	skipped := []SkippedRecord{}
+	for i, record := range records {
+		copies := int(times) * record.Times
+		text, label, err := p.prepareLabel(record, dir, fmt.Sprint(i))
 		if err != nil {
-			log.Error("Failed to generate barcode", "err", err)
-			return err
+			if err := handleFailed(record, err, policy, &skipped, log); err != nil {
+				return skipped, err
+			}
+			if policy == PolicySkipInvalid {
+				for c := 0; c < copies; c++ {
+					p.report = append(p.report, ReportEntry{Record: record, Ean: record.Ean, Copy: c + 1, Status: ReportSkipped, Err: err})
+				}
+				continue
+			}
 		}
+		labelErr := err
 		if record.Times == 0 {
 			log.Warn("Row EAN repetition is zero, skip", "record", record)
 		}
-		for i := 0; i < int(times)*record.Times; i++ {
-			log.Debug("Add page", "record", record, "barcode", barcode_path)
-			p.addPage(record, barcode_path)
+		for c := 0; c < copies; c++ {
+			if c > 0 && labelErr == nil && p.serial.Enabled {
+				// Every copy has own serial number, so its label is prepared again.
+				text, label, err = p.prepareLabel(record, dir, fmt.Sprintf("%d-%d", i, c))
+				if err != nil {
+					if err := handleFailed(record, err, policy, &skipped, log); err != nil {
+						return skipped, err
+					}
+					if policy == PolicySkipInvalid {
+						p.report = append(p.report, ReportEntry{Record: record, Ean: text.Barcode, Copy: c + 1, Status: ReportSkipped, Err: err})
+						continue
+					}
+				}
+			}
+			log.Debug("Add page", "record", record, "image", label.image)
+			if err := p.addPage(record, text, label); err != nil {
+				log.Error("Failed to draw label", "row", record.Row, "err", err)
+				return skipped, err
+			}
+			entry := ReportEntry{
+				Page:     p.pdf.PageCount(),
+				Position: 1,
+				Record:   record,
+				Ean:      text.Barcode,
+				Label:    text,
+				Copy:     c + 1,
+				Status:   ReportPrinted,
+			}
+			if p.sheet != nil {
+				entry.Position = (p.position-1)%p.sheet.PerPage() + 1
+			}
+			if err != nil {
+				entry.Status, entry.Err = ReportInvalid, err
+			} else {
+				entry.Serial = p.serial.peek()
+				p.serial.issue()
+			}
+			if entry.Ean == "" {
+				entry.Ean = record.Ean
+			}
+			p.report = append(p.report, entry)
 		}
 	}
 	log.Info("Pages added", "count", p.pdf.PageCount())
 
+	return skipped, nil
+}
+
+// Handles the record that failed by the policy. Fail-fast policy returns
+// the error, other policies add the record to skipped.
+func handleFailed(record Record, err error, policy ErrorPolicy, skipped *[]SkippedRecord, log *slog.Logger) error {
+	if policy == PolicyFailFast || policy == "" {
+		log.Error("Failed to generate barcode", "row", record.Row, "err", err)
+		return err
+	}
+	log.Warn("Failed to generate barcode", "row", record.Row, "policy", policy, "err", err)
+	*skipped = append(*skipped, SkippedRecord{Record: record, Err: err})
 	return nil
 }
 
-// Adds a single barcode page to the PDF document.
-// Layouts the record text at the top, barcode image
-// in the center, and EAN number at the bottom.
-func (p *Pdf) addPage(record Record, image string) error {
-	p.pdf.AddPage()
-	p.pdf.SetFont("Arial", "", 4)
+// Renders texts of the next label of the record and prepares its barcode.
+// The name is used for the barcode image. If it fails, label is the placeholder
+// and texts are the record text and code, unless only the barcode failed.
+func (p *Pdf) prepareLabel(record Record, dir string, name string) (LabelText, labelBarcode, error) {
+	text, err := p.templates.render(record, p.priceFormat, p.serial.peek())
+	if err != nil {
+		err = errors.Join(fmt.Errorf("Cannot render label template of row %d", record.Row), err)
+		// Placeholder label shows at least the record text.
+		return LabelText{Top: record.Text, Bottom: record.Ean}, labelBarcode{}, err
+	}
+	record.Ean = text.Barcode
+	label, err := p.prepareBarcode(record, dir, name)
+	if err != nil {
+		return text, labelBarcode{}, err
+	}
+	return text, label, nil
+}
+
+// Prepares the barcode of the record for drawing. PNG renderer writes
+// the image named by name to the dir, vector renderer checks that the
+// barcode fits its box. Codes with add-on are always drawn as GS1 symbols.
+func (p *Pdf) prepareBarcode(record Record, dir string, name string) (labelBarcode, error) {
+	if record.AddOn != "" && !record.Symbology.isEanUpc() {
+		return labelBarcode{}, fmt.Errorf("Add-on can be used only with EAN, UPC-A or ISBN, not %s", record.Symbology)
+	}
+	if p.renderer == RendererPng {
+		if record.AddOn != "" {
+			return labelBarcode{}, errors.New("Add-on needs the vector barcode renderer")
+		}
+		// Content of non EAN codes may contain characters not allowed in file names.
+		path := filepath.Join(dir, name+".png")
+		return labelBarcode{image: path}, record.GenerateBarcode(path)
+	}
+	code, err := record.Symbology.Encode(record.Ean)
+	if err != nil {
+		return labelBarcode{}, err
+	}
+	layout := p.layout
+	if (p.gs1 || record.AddOn != "") && record.Symbology.isEanUpc() {
+		symbol, err := newEanSymbol(code, record.Symbology, record.AddOn)
+		if err != nil {
+			return labelBarcode{}, err
+		}
+		if _, _, err := symbol.size(layout.Barcode, layout.ModuleWidth, p.magnification); err != nil {
+			return labelBarcode{}, err
+		}
+		return labelBarcode{code: code, ean: &symbol}, nil
+	}
+	if record.Symbology.Is2D() {
+		layout = layout.layout2D()
+	}
+	if _, _, err := moduleSize(barcodeModules(code), layout.Barcode, layout.ModuleWidth, record.Symbology.Is2D()); err != nil {
+		return labelBarcode{}, err
+	}
+	return labelBarcode{code: code}, nil
+}
+
+// Adds a single barcode label to the PDF document.
+// Without sheet every label is on a new page, otherwise the label is placed
+// to the next free position on the sheet and new sheet is added when needed.
+func (p *Pdf) addPage(record Record, text LabelText, label labelBarcode) error {
+	if p.sheet == nil {
+		p.pdf.AddPage()
+		return p.drawLabel(record, text, label, 0, 0)
+	}
+	if p.pdf.PageCount() == 0 || p.position%p.sheet.PerPage() == 0 {
+		p.pdf.AddPage()
+	}
+	x, y := p.sheet.Origin(p.position)
+	p.position++
+	return p.drawLabel(record, text, label, x, y)
+}
+
+// Draws a single label with top left corner at the given position.
+// Layouts the top text, barcode image, bottom text (EAN number by default),
+// side text and price into the boxes given by the layout.
+// Two dimensional codes are placed as a square on the left side instead.
+func (p *Pdf) drawLabel(record Record, text LabelText, label labelBarcode, x float64, y float64) error {
+	p.pdf.SetFont("Arial", "", p.layout.FontSize)
+
+	layout := p.layout
+	if record.Symbology.Is2D() {
+		layout = layout.layout2D()
+	}
+	// Points to millimeters with a bit of line spacing.
+	lineHeight := layout.FontSize * 0.4
 
 	// Top text
-	p.pdf.SetXY(1, 1)
-	p.pdf.MultiCell(27.0, 1.6, record.Text, "", "L", false)
-
-	// Center image
-	imageWidth := 27.0
-	imageHeight := 7.0
-	p.pdf.ImageOptions(image, 1.5, 5.0, imageWidth, imageHeight, false, fpdf.ImageOptions{}, 0, "")
-
-	// Footer EAN in text
-	p.pdf.SetFooterFuncLpi(func(lastPage bool) {
-		p.pdf.SetXY(0, 0)
-		p.pdf.CellFormat(30.0, 14.0, record.Ean, "", 0, "CB", false, 0, "")
-	})
+	if !layout.Text.Empty() {
+		p.drawText(layout.Text.move(x, y), lineHeight, text.Top)
+	}
+
+	// Side text
+	if !layout.Side.Empty() && text.Side != "" {
+		p.drawText(layout.Side.move(x, y), lineHeight, text.Side)
+	}
+
+	// Price
+	if !layout.Price.Empty() && text.Price != "" {
+		p.drawPrice(layout.Price.move(x, y), text.Price, text.UnitPrice)
+	}
+
+	// Center barcode
+	box := layout.Barcode.move(x, y)
+	switch {
+	case label.ean != nil:
+		if err := p.drawEanSymbol(*label.ean, box); err != nil {
+			return err
+		}
+	case label.code != nil:
+		if err := p.drawVectorBarcode(label.code, box, record.Symbology.Is2D()); err != nil {
+			return err
+		}
+	case label.image != "":
+		p.pdf.ImageOptions(label.image, box.X, box.Y, box.Width, box.Height, false, fpdf.ImageOptions{}, 0, "")
+	default:
+		p.drawPlaceholder(box)
+	}
+
+	// Bottom EAN in text, GS1 symbols have own digits unless the bottom template is set
+	if !layout.Content.Empty() && (label.ean == nil || p.templates.bottom != nil) {
+		box := layout.Content.move(x, y)
+		align := "CB"
+		if record.Symbology.Is2D() {
+			align = "LB"
+		}
+		p.pdf.SetXY(box.X, box.Y)
+		p.pdf.CellFormat(box.Width, box.Height, pdfText(text.Bottom), "", 0, align, false, 0, "")
+	}
 	return nil
 }
 
+// Draws multi line text clipped to the box.
+func (p *Pdf) drawText(box Box, lineHeight float64, text string) {
+	p.pdf.ClipRect(box.X, box.Y, box.Width, box.Height, false)
+	p.pdf.SetXY(box.X, box.Y)
+	p.pdf.MultiCell(box.Width, lineHeight, pdfText(text), "", "L", false)
+	p.pdf.ClipEnd()
+}
+
+// Draws the price in bold as large as fits the box. Unit price is drawn
+// in a smaller line at the bottom of the box, if it is not empty.
+func (p *Pdf) drawPrice(box Box, price string, unitPrice string) {
+	// Millimeters to points.
+	const ptPerMm = 72 / 25.4
+	priceBox, unitBox := box, Box{}
+	if unitPrice != "" {
+		unitBox = Box{X: box.X, Y: box.Y + box.Height*0.7, Width: box.Width, Height: box.Height * 0.3}
+		priceBox.Height = box.Height * 0.7
+	}
+	for _, item := range []struct {
+		box   Box
+		text  string
+		style string
+	}{{priceBox, price, "B"}, {unitBox, unitPrice, ""}} {
+		if item.text == "" {
+			continue
+		}
+		text := pdfText(item.text)
+		size := item.box.Height * ptPerMm
+		p.pdf.SetFont("Arial", item.style, size)
+		if width := p.pdf.GetStringWidth(text); width > item.box.Width {
+			size *= item.box.Width / width
+			p.pdf.SetFont("Arial", item.style, size)
+		}
+		p.pdf.SetXY(item.box.X, item.box.Y)
+		p.pdf.CellFormat(item.box.Width, item.box.Height, text, "", 0, "CM", false, 0, "")
+	}
+	p.pdf.SetFont("Arial", "", p.layout.FontSize)
+}
+
+// Converts the text to Windows-1252 used by the core PDF fonts. Letters
+// missing in it are written without diacritics ("č" as "c"), other
+// characters as "?".
+func pdfText(s string) string {
+	var text strings.Builder
+	for _, r := range s {
+		if b, ok := charmap.Windows1252.EncodeRune(r); ok {
+			text.WriteByte(b)
+			continue
+		}
+		if base := []rune(removeDiacritics(string(r))); len(base) == 1 {
+			if b, ok := charmap.Windows1252.EncodeRune(base[0]); ok {
+				text.WriteByte(b)
+				continue
+			}
+		}
+		text.WriteByte('?')
+	}
+	return text.String()
+}
+
+// Draws a crossed red box with INVALID text in place of the barcode.
+func (p *Pdf) drawPlaceholder(box Box) {
+	p.pdf.SetDrawColor(200, 0, 0)
+	p.pdf.SetTextColor(200, 0, 0)
+	p.pdf.SetLineWidth(0.3)
+	p.pdf.Rect(box.X, box.Y, box.Width, box.Height, "D")
+	p.pdf.Line(box.X, box.Y, box.X+box.Width, box.Y+box.Height)
+	p.pdf.Line(box.X, box.Y+box.Height, box.X+box.Width, box.Y)
+	p.pdf.SetFont("Arial", "B", p.layout.FontSize*2)
+	p.pdf.SetXY(box.X, box.Y)
+	p.pdf.CellFormat(box.Width, box.Height, "INVALID", "", 0, "CM", false, 0, "")
+	// Restore defaults for the rest of the label.
+	p.pdf.SetFont("Arial", "", p.layout.FontSize)
+	p.pdf.SetDrawColor(0, 0, 0)
+	p.pdf.SetTextColor(0, 0, 0)
+}
+
+// Write writes the PDF document to the writer and closes it.
+func (p *Pdf) Write(w io.Writer) error {
+	return p.pdf.Output(w)
+}
+
 // Save writes the PDF document to the specified file path and closes it.
 // Returns an error if the file cannot be created or written.
 func (p *Pdf) Save(path string) error {
@@ -416,3 +416,132 @@ func TestPdf_SpecialCharactersInText(t *testing.T) {
 		t.Errorf("Save() failed: %v", err)
 	}
 }
+
+func TestPdf_AddPages_MixedSymbologies(t *testing.T) {
+	pdf := NewPdf()
+	records := []Record{
+		{Text: "Product", Ean: "5901234123457", Times: 1},
+		{Text: "Pallet", Ean: "15400141288763", Times: 1, Symbology: SymbologyITF14},
+		{Text: "Asset", Ean: "ASSET/0042", Times: 2, Symbology: SymbologyDataMatrix},
+		{Text: "Lot", Ean: "(01)09501101530003(17)251231(10)A1", Times: 1, Symbology: SymbologyGS1128},
+		{Text: "Lot", Ean: "(01)09501101530003(21)42", Times: 1, Symbology: SymbologyGS1DataMatrix},
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	err := pdf.AddPages(records, 1, log)
+	if err != nil {
+		t.Fatalf("AddPages() failed: %v", err)
+	}
+
+	if pdf.pdf.PageCount() != 6 {
+		t.Errorf("PageCount() = %d, want 6", pdf.pdf.PageCount())
+	}
+}
+
+func TestNewPdfWithLayout(t *testing.T) {
+	if _, err := NewPdfWithLayout(Layout{}); err == nil {
+		t.Error("NewPdfWithLayout() should fail for invalid layout")
+	}
+
+	pdf, err := NewPdfWithLayout(NewLayout(40, 25))
+	if err != nil {
+		t.Fatalf("NewPdfWithLayout() failed: %v", err)
+	}
+	records := []Record{
+		{Text: "Product", Ean: "5901234123457", Times: 1},
+		{Text: "Asset", Ean: "ASSET/0042", Times: 1, Symbology: SymbologyQR},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := pdf.AddPages(records, 1, log); err != nil {
+		t.Fatalf("AddPages() failed: %v", err)
+	}
+	width, height := pdf.pdf.GetPageSize()
+	if width != 40 || height != 25 {
+		t.Errorf("GetPageSize() = %gx%g, want 40x25", width, height)
+	}
+}
+
+func TestNewPdfOnSheet(t *testing.T) {
+	sheet := LabelSheetPresets["avery-l7160"] // 3x7 labels
+	layout := NewLayout(sheet.LabelWidth, sheet.LabelHeight)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	records := []Record{
+		{Text: "Product", Ean: "5901234123457", Times: 10},
+	}
+
+	tests := []struct {
+		name      string
+		start     int
+		times     uint
+		wantPages int
+	}{
+		{name: "Single sheet", start: 1, times: 1, wantPages: 1},
+		{name: "Exactly full sheet", start: 12, times: 1, wantPages: 1},
+		{name: "Overflow to next sheet", start: 13, times: 1, wantPages: 2},
+		{name: "Multiple sheets", start: 1, times: 5, wantPages: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf, err := NewPdfOnSheet(layout, sheet, tt.start)
+			if err != nil {
+				t.Fatalf("NewPdfOnSheet() failed: %v", err)
+			}
+			if err := pdf.AddPages(records, tt.times, log); err != nil {
+				t.Fatalf("AddPages() failed: %v", err)
+			}
+			if pdf.pdf.PageCount() != tt.wantPages {
+				t.Errorf("PageCount() = %d, want %d", pdf.pdf.PageCount(), tt.wantPages)
+			}
+		})
+	}
+
+	if _, err := NewPdfOnSheet(layout, sheet, 0); err == nil {
+		t.Error("NewPdfOnSheet() should fail for zero start position")
+	}
+	if _, err := NewPdfOnSheet(layout, sheet, 22); err == nil {
+		t.Error("NewPdfOnSheet() should fail for start position after the sheet")
+	}
+	if _, err := NewPdfOnSheet(DefaultLayout(), sheet, 1); err == nil {
+		t.Error("NewPdfOnSheet() should fail for layout of different size")
+	}
+}
+
+func TestPdf_AddPagesWithPolicy(t *testing.T) {
+	records := []Record{
+		{Text: "Product A", Ean: "5901234123457", Times: 1, Row: 2},
+		{Text: "Broken", Ean: "5901234123458", Times: 2, Row: 3},
+		{Text: "Product B", Ean: "4006381333931", Times: 1, Row: 4},
+	}
+	tests := []struct {
+		policy      ErrorPolicy
+		wantErr     bool
+		wantPages   int
+		wantSkipped int
+	}{
+		{policy: PolicyFailFast, wantErr: true, wantSkipped: 0},
+		{policy: PolicySkipInvalid, wantPages: 2, wantSkipped: 1},
+		{policy: PolicyPlaceholder, wantPages: 4, wantSkipped: 1},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			pdf := NewPdf()
+			skipped, err := pdf.AddPagesWithPolicy(records, 1, tt.policy, log)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AddPagesWithPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(skipped) != tt.wantSkipped {
+				t.Fatalf("len(skipped) = %d, want %d", len(skipped), tt.wantSkipped)
+			}
+			if tt.wantErr {
+				return
+			}
+			if skipped[0].Record.Row != 3 || skipped[0].Err == nil {
+				t.Errorf("skipped[0] = %+v, want row 3 with error", skipped[0])
+			}
+			if pdf.pdf.PageCount() != tt.wantPages {
+				t.Errorf("PageCount() = %d, want %d", pdf.pdf.PageCount(), tt.wantPages)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Number formatting and currency of a locale.
+type PriceLocale struct {
+	Decimal   string
+	Thousands string
+	Currency  string
+	// Currency symbol is written before the amount ("$12.90"), after it otherwise ("12,90 Kč").
+	SymbolBefore bool
+}
+
+// Built-in locales for price formatting.
+var PriceLocales = map[string]PriceLocale{
+	"en-us": {Decimal: ".", Thousands: ",", Currency: "$", SymbolBefore: true},
+	"en-gb": {Decimal: ".", Thousands: ",", Currency: "£", SymbolBefore: true},
+	"cs-cz": {Decimal: ",", Thousands: "\u00a0", Currency: "Kč"},
+	"sk-sk": {Decimal: ",", Thousands: "\u00a0", Currency: "€"},
+	"de-de": {Decimal: ",", Thousands: ".", Currency: "€"},
+	"fr-fr": {Decimal: ",", Thousands: "\u00a0", Currency: "€"},
+	"pl-pl": {Decimal: ",", Thousands: "\u00a0", Currency: "zł"},
+}
+
+// Returns sorted names of the built-in price locales.
+func PriceLocaleNames() []string {
+	names := []string{}
+	for name := range PriceLocales {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Formatting of prices printed on labels. Locale gives separators and
+// currency, currency symbol and its position can be overridden.
+type PriceFormat struct {
+	// Locale name such as "cs-CZ", "en-US" is used when empty.
+	Locale   string `json:"locale,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	// Currency symbol position "before" or "after", locale default when empty.
+	SymbolPosition string `json:"symbol_position,omitempty"`
+	// Unit of quantities given without unit, e.g. "kg" or "l".
+	QuantityUnit string `json:"quantity_unit,omitempty"`
+}
+
+// Returns the locale with the overrides of the format applied.
+func (f PriceFormat) locale() (PriceLocale, error) {
+	name := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(f.Locale), "_", "-"))
+	if name == "" {
+		name = "en-us"
+	}
+	locale, ok := PriceLocales[name]
+	if !ok {
+		return PriceLocale{}, fmt.Errorf("Unknown price locale %q, expected one of: %s", f.Locale, strings.Join(PriceLocaleNames(), ", "))
+	}
+	if f.Currency != "" {
+		locale.Currency = f.Currency
+	}
+	switch strings.ToLower(strings.TrimSpace(f.SymbolPosition)) {
+	case "":
+	case "before":
+		locale.SymbolBefore = true
+	case "after":
+		locale.SymbolBefore = false
+	default:
+		return PriceLocale{}, fmt.Errorf("Currency symbol position must be before or after, got %q", f.SymbolPosition)
+	}
+	return locale, nil
+}
+
+// Validate checks that the locale, symbol position and quantity unit are known.
+func (f PriceFormat) Validate() error {
+	if _, err := f.locale(); err != nil {
+		return err
+	}
+	if f.QuantityUnit != "" {
+		if _, err := ParseQuantity("1 " + f.QuantityUnit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Formats the amount with two decimals, thousands separator and currency symbol.
+// Spaces in the price are non-breaking, so the price is never wrapped.
+func (f PriceFormat) Format(amount float64) (string, error) {
+	locale, err := f.locale()
+	if err != nil {
+		return "", err
+	}
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	integer := strconv.FormatInt(cents/100, 10)
+	// Group digits by thousands from the right.
+	groups := []string{}
+	for len(integer) > 3 {
+		groups = append([]string{integer[len(integer)-3:]}, groups...)
+		integer = integer[:len(integer)-3]
+	}
+	groups = append([]string{integer}, groups...)
+	number := strings.Join(groups, locale.Thousands) + locale.Decimal + fmt.Sprintf("%02d", cents%100)
+	sign := ""
+	if amount < 0 && cents != 0 {
+		sign = "-"
+	}
+	switch {
+	case locale.Currency == "":
+		return sign + number, nil
+	case locale.SymbolBefore:
+		return sign + locale.Currency + number, nil
+	default:
+		return sign + number + "\u00a0" + locale.Currency, nil
+	}
+}
+
+// Returns formatted price and unit price of the record, empty strings
+// when the record has no price. Unit price needs quantity of the record.
+func (f PriceFormat) Record(record Record) (string, string, error) {
+	if record.Price == nil {
+		return "", "", nil
+	}
+	price, err := f.Format(*record.Price)
+	if err != nil {
+		return "", "", err
+	}
+	if record.Quantity == nil || record.Quantity.Amount == 0 {
+		return price, "", nil
+	}
+	unitPrice, err := f.Format(*record.Price / record.Quantity.Amount)
+	if err != nil {
+		return "", "", err
+	}
+	return price, unitPrice + "/" + record.Quantity.Unit, nil
+}
+
+// Parses a price written with decimal comma or point and optional thousands
+// separators and currency symbol, e.g. "1 234,50 Kč", "$1,234.50" or "12.9".
+// Single separator followed by exactly three digits separates thousands.
+func ParsePrice(s string) (float64, error) {
+	number := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r == ',', r == '.', r == '-':
+			return r
+		case unicode.IsSpace(r), r == '\'':
+			// Thousands separators.
+			return -1
+		case unicode.IsLetter(r), unicode.Is(unicode.Sc, r):
+			// Currency symbols and codes.
+			return -1
+		}
+		return '?'
+	}, strings.TrimSpace(s))
+	if number == "" || strings.Contains(number, "?") {
+		return 0, fmt.Errorf("Invalid price '%s'", s)
+	}
+
+	decimal := ""
+	lastComma, lastPoint := strings.LastIndex(number, ","), strings.LastIndex(number, ".")
+	switch {
+	case lastComma >= 0 && lastPoint >= 0:
+		decimal = string(number[max(lastComma, lastPoint)])
+	case lastComma >= 0 || lastPoint >= 0:
+		separator := string(number[max(lastComma, lastPoint)])
+		if strings.Count(number, separator) == 1 && len(number)-max(lastComma, lastPoint)-1 != 3 {
+			decimal = separator
+		}
+	}
+	for _, separator := range []string{",", "."} {
+		if separator != decimal {
+			number = strings.ReplaceAll(number, separator, "")
+		}
+	}
+	if decimal != "" {
+		number = strings.Replace(number, decimal, ".", 1)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid price '%s'", s)
+	}
+	return value, nil
+}
+
+// Quantity of a product, amount is in the unit used for unit prices.
+type Quantity struct {
+	Amount float64
+	// Unit of the amount, "kg", "l" or the unit of pieces as written.
+	Unit string
+}
+
+// Units of quantity with their base unit and ratio to it.
+var quantityUnits = map[string]struct {
+	base  string
+	ratio float64
+}{
+	"mg":  {"kg", 1e-6},
+	"g":   {"kg", 1e-3},
+	"dkg": {"kg", 1e-2},
+	"kg":  {"kg", 1},
+	"ml":  {"l", 1e-3},
+	"cl":  {"l", 1e-2},
+	"dl":  {"l", 1e-1},
+	"l":   {"l", 1},
+}
+
+// Units of pieces kept as written.
+var pieceUnits = []string{"pc", "pcs", "ks"}
+
+// Parses a quantity with unit such as "500 g", "0,75 l" or "1.5kg".
+// Masses are converted to kilograms and volumes to liters.
+func ParseQuantity(s string) (Quantity, error) {
+	s = strings.TrimSpace(s)
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == ',' || r == '.' || unicode.IsSpace(r))
+	})
+	if end == -1 {
+		return Quantity{}, fmt.Errorf("Quantity '%s' has no unit", s)
+	}
+	number, unit := strings.TrimSpace(s[:end]), strings.ToLower(strings.TrimSpace(s[end:]))
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(number, ",", "."), 64)
+	if err != nil {
+		return Quantity{}, fmt.Errorf("Invalid quantity '%s'", s)
+	}
+	if amount <= 0 {
+		return Quantity{}, fmt.Errorf("Quantity must be positive, got '%s'", s)
+	}
+	if u, ok := quantityUnits[unit]; ok {
+		return Quantity{Amount: amount * u.ratio, Unit: u.base}, nil
+	}
+	if slices.Contains(pieceUnits, unit) {
+		return Quantity{Amount: amount, Unit: unit}, nil
+	}
+	return Quantity{}, fmt.Errorf("Unknown unit of quantity '%s', expected g, kg, ml, l or pcs", s)
+}
+
+// Parses a quantity as ParseQuantity does, quantities given only
+// by a number get the unit, if it is not empty.
+func parseQuantityWithUnit(s string, unit string) (Quantity, error) {
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", "."), 64); err == nil && unit != "" {
+		s += " " + unit
+	}
+	return ParseQuantity(s)
+}
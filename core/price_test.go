@@ -0,0 +1,192 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestPriceFormat_Format(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  PriceFormat
+		amount  float64
+		want    string
+		wantErr bool
+	}{
+		{"Default locale", PriceFormat{}, 1234.5, "$1,234.50", false},
+		{"Czech", PriceFormat{Locale: "cs-CZ"}, 1234.5, "1\u00a0234,50\u00a0Kč", false},
+		{"German underscore", PriceFormat{Locale: "de_DE"}, 1234567.891, "1.234.567,89\u00a0€", false},
+		{"British", PriceFormat{Locale: "en-gb"}, 0.5, "£0.50", false},
+		{"Small amount", PriceFormat{Locale: "cs-cz"}, 12.9, "12,90\u00a0Kč", false},
+		{"Negative", PriceFormat{}, -3.2, "-$3.20", false},
+		{"Currency override", PriceFormat{Locale: "de-de", Currency: "CHF"}, 5, "5,00\u00a0CHF", false},
+		{"Symbol before", PriceFormat{Locale: "cs-cz", SymbolPosition: "before"}, 5, "Kč5,00", false},
+		{"Symbol after", PriceFormat{SymbolPosition: "After"}, 5, "5.00\u00a0$", false},
+		{"Unknown locale", PriceFormat{Locale: "xx-xx"}, 5, "", true},
+		{"Invalid position", PriceFormat{SymbolPosition: "middle"}, 5, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.format.Format(tt.amount)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Format() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceFormat_Record(t *testing.T) {
+	price := 25.0
+	format := PriceFormat{Locale: "cs-cz"}
+	tests := []struct {
+		name          string
+		record        Record
+		wantPrice     string
+		wantUnitPrice string
+	}{
+		{"No price", Record{}, "", ""},
+		{"Price only", Record{Price: &price}, "25,00\u00a0Kč", ""},
+		{"Mass", Record{Price: &price, Quantity: &Quantity{Amount: 0.5, Unit: "kg"}}, "25,00\u00a0Kč", "50,00\u00a0Kč/kg"},
+		{"Pieces", Record{Price: &price, Quantity: &Quantity{Amount: 10, Unit: "pcs"}}, "25,00\u00a0Kč", "2,50\u00a0Kč/pcs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPrice, gotUnitPrice, err := format.Record(tt.record)
+			if err != nil {
+				t.Fatalf("Record() failed: %v", err)
+			}
+			if gotPrice != tt.wantPrice || gotUnitPrice != tt.wantUnitPrice {
+				t.Errorf("Record() = %q, %q, want %q, %q", gotPrice, gotUnitPrice, tt.wantPrice, tt.wantUnitPrice)
+			}
+		})
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{"12.9", 12.9, false},
+		{"12,90", 12.9, false},
+		{"1 234,50 Kč", 1234.5, false},
+		{"$1,234.50", 1234.5, false},
+		{"1.234.567,89 €", 1234567.89, false},
+		{"1,234", 1234, false},
+		{"1.234", 1234, false},
+		{"1'234.5", 1234.5, false},
+		{"-3,20", -3.2, false},
+		{"", 0, true},
+		{"Kč", 0, true},
+		{"12/3", 0, true},
+		{"1,2,3.4.5", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePrice(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePrice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePrice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Quantity
+		wantErr bool
+	}{
+		{"500 g", Quantity{Amount: 0.5, Unit: "kg"}, false},
+		{"1.5kg", Quantity{Amount: 1.5, Unit: "kg"}, false},
+		{"0,75 l", Quantity{Amount: 0.75, Unit: "l"}, false},
+		{"250 ML", Quantity{Amount: 0.25, Unit: "l"}, false},
+		{"6 pcs", Quantity{Amount: 6, Unit: "pcs"}, false},
+		{"500", Quantity{}, true},
+		{"0 g", Quantity{}, true},
+		{"5 oz", Quantity{}, true},
+		{"g", Quantity{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseQuantity(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQuantity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseQuantity() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPdfText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Bread", "Bread"},
+		{"12,90 €", "12,90 \x80"},
+		{"Žluťoučký kůň", "\x8elutouck\xfd kun"},
+		{"Müller £", "M\xfcller \xa3"},
+		{"日本", "??"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := pdfText(tt.input); got != tt.want {
+				t.Errorf("pdfText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPdf_Price(t *testing.T) {
+	layout := DefaultLayout()
+	layout.Price = Box{X: 20, Y: 1, Width: 9, Height: 4}
+	pdf, err := NewPdfWithLayout(layout)
+	if err != nil {
+		t.Fatalf("NewPdfWithLayout() failed: %v", err)
+	}
+	if err := pdf.SetPriceFormat(PriceFormat{Locale: "de-de"}); err != nil {
+		t.Fatalf("SetPriceFormat() failed: %v", err)
+	}
+	if err := pdf.SetTemplates(LabelTemplates{Bottom: "{{.UnitPrice}}"}); err != nil {
+		t.Fatalf("SetTemplates() failed: %v", err)
+	}
+	pdf.pdf.SetCompression(false)
+	price := 2.5
+	records := []Record{
+		{Text: "Milk", Ean: "4006381333931", Times: 1, Price: &price, Quantity: &Quantity{Amount: 0.5, Unit: "l"}},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := pdf.AddPages(records, 1, log); err != nil {
+		t.Fatalf("AddPages() failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := pdf.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() failed: %v", err)
+	}
+	for _, want := range []string{"2,50\xa0\x80", "5,00\xa0\x80/l"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("PDF does not contain %q", want)
+		}
+	}
+}
+
+func TestPriceFormat_Validate(t *testing.T) {
+	if err := (PriceFormat{QuantityUnit: "oz"}).Validate(); err == nil {
+		t.Error("Validate() should fail for unknown quantity unit")
+	}
+	if err := (PriceFormat{Locale: "pl-PL", QuantityUnit: "g"}).Validate(); err != nil {
+		t.Errorf("Validate() failed: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Maximal length of profile name.
+const MaxProfileNameLength = 64
+
+// Directory of named generator configurations (profiles), e.g. one per
+// label printer or customer. Every profile is a generator JSON file.
+type ProfileStore struct {
+	Dir string
+}
+
+// Returns the profile store in the configuration directory of the user,
+// e.g. ~/.config/EANBaker/profiles on Linux.
+func UserProfileStore(app string) (ProfileStore, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ProfileStore{}, errors.Join(errors.New("Cannot find user configuration directory"), err)
+	}
+	return ProfileStore{Dir: filepath.Join(dir, app, "profiles")}, nil
+}
+
+// Checks the profile name can be used as a file name. Name consists
+// of letters, digits, spaces, dots, dashes and underscores and starts
+// with a letter or digit.
+func ValidateProfileName(name string) error {
+	if name == "" {
+		return errors.New("Profile name cannot be empty")
+	}
+	if len(name) > MaxProfileNameLength {
+		return fmt.Errorf("Profile name can have at most %d characters", MaxProfileNameLength)
+	}
+	for i, r := range name {
+		letter := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if i == 0 && !letter {
+			return fmt.Errorf("Profile name '%s' must start with a letter or digit", name)
+		}
+		if !letter && !strings.ContainsRune(" ._-", r) {
+			return fmt.Errorf("Profile name '%s' contains invalid character %q", name, r)
+		}
+	}
+	return nil
+}
+
+// Returns the path of the profile file.
+func (s ProfileStore) Path(name string) string {
+	return filepath.Join(s.Dir, name+".json")
+}
+
+// Returns names of all profiles sorted by name. Store without
+// directory has no profiles.
+func (s ProfileStore) Names() ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !entry.IsDir() && ok && ValidateProfileName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Reports whether the profile exists.
+func (s ProfileStore) Exists(name string) bool {
+	if ValidateProfileName(name) != nil {
+		return false
+	}
+	_, err := os.Stat(s.Path(name))
+	return err == nil
+}
+
+// Loads the generator of the profile.
+func (s ProfileStore) Load(name string) (*Generator, error) {
+	if err := s.checkExists(name); err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(s.Path(name))
+	if err != nil {
+		return nil, err
+	}
+	g, err := DecodeGenerator(content)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("Cannot decode profile '%s'", name), err)
+	}
+	return g, nil
+}
+
+// Saves the generator to the profile, existing profile is overwritten.
+func (s ProfileStore) Save(name string, g *Generator) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	content, err := g.MarshalConfig()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	return WriteFileAtomic(s.Path(name), content)
+}
+
+// Creates a new profile with the generator. Returns an error
+// if the profile already exists.
+func (s ProfileStore) Create(name string, g *Generator) error {
+	if err := s.checkFree(name); err != nil {
+		return err
+	}
+	return s.Save(name, g)
+}
+
+// Creates a new profile with the same configuration as an existing one.
+func (s ProfileStore) Duplicate(from string, to string) error {
+	g, err := s.Load(from)
+	if err != nil {
+		return err
+	}
+	return s.Create(to, g)
+}
+
+// Deletes the profile.
+func (s ProfileStore) Delete(name string) error {
+	if err := s.checkExists(name); err != nil {
+		return err
+	}
+	return os.Remove(s.Path(name))
+}
+
+// Writes the profile as generator JSON, which can be imported on another computer.
+func (s ProfileStore) Export(name string, w io.Writer) error {
+	if err := s.checkExists(name); err != nil {
+		return err
+	}
+	content, err := os.ReadFile(s.Path(name))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// Creates the profile from generator JSON, e.g. an exported profile or
+// .EANBaker.json of any supported version. Unknown keys are rejected, so other
+// JSON files are not imported by mistake. Existing profile is replaced only
+// if overwrite is set.
+func (s ProfileStore) Import(name string, r io.Reader, overwrite bool) error {
+	if !overwrite {
+		if err := s.checkFree(name); err != nil {
+			return err
+		}
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	g, err := DecodeGenerator(content)
+	if err != nil {
+		return errors.Join(fmt.Errorf("Cannot import profile '%s'", name), err)
+	}
+	return s.Save(name, g)
+}
+
+// Returns the profile name without the .json extension and directory
+// of the file, e.g. to import "exports/shop.json" as "shop".
+func ProfileNameFromPath(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// Returns an error if the name is not valid or the profile does not exist.
+func (s ProfileStore) checkExists(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if !s.Exists(name) {
+		return fmt.Errorf("Profile '%s' does not exist", name)
+	}
+	return nil
+}
+
+// Returns an error if the name is not valid or the profile already exists.
+func (s ProfileStore) checkFree(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if s.Exists(name) {
+		return fmt.Errorf("Profile '%s' already exists", name)
+	}
+	return nil
+}
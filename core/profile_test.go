@@ -0,0 +1,94 @@
+package core
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateProfileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "zebra-50x30", wantErr: false},
+		{name: "Shop 2 v1.0", wantErr: false},
+		{name: "Žluťoučký_kůň", wantErr: false},
+		{name: "", wantErr: true},
+		{name: ".hidden", wantErr: true},
+		{name: "../etc", wantErr: true},
+		{name: "a/b", wantErr: true},
+		{name: strings.Repeat("a", MaxProfileNameLength+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateProfileName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProfileName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProfileStore(t *testing.T) {
+	store := ProfileStore{Dir: t.TempDir() + "/profiles"}
+
+	names, err := store.Names()
+	if err != nil || len(names) != 0 {
+		t.Fatalf("Names() = %v, %v, want no profiles", names, err)
+	}
+	gen := DefaultGenerator()
+	gen.TextHeader = "Name"
+	if err := store.Create("zebra", &gen); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := store.Create("zebra", &gen); err == nil {
+		t.Error("Create() should fail for existing profile")
+	}
+	if err := store.Duplicate("zebra", "brother"); err != nil {
+		t.Fatalf("Duplicate() failed: %v", err)
+	}
+	loaded, err := store.Load("brother")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if loaded.TextHeader != "Name" {
+		t.Errorf("Load().TextHeader = %q, want Name", loaded.TextHeader)
+	}
+	if names, _ := store.Names(); !slices.Equal(names, []string{"brother", "zebra"}) {
+		t.Errorf("Names() = %v, want [brother zebra]", names)
+	}
+
+	var exported bytes.Buffer
+	if err := store.Export("zebra", &exported); err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+	if err := store.Import("zebra", bytes.NewReader(exported.Bytes()), false); err == nil {
+		t.Error("Import() should not overwrite existing profile")
+	}
+	if err := store.Import("copy", bytes.NewReader(exported.Bytes()), false); err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+	if err := store.Import("other", strings.NewReader(`{"registry": []}`), false); err == nil {
+		t.Error("Import() should reject unknown keys")
+	}
+
+	if err := store.Delete("zebra"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, err := store.Load("zebra"); err == nil {
+		t.Error("Load() should fail for deleted profile")
+	}
+	if err := store.Delete("zebra"); err == nil {
+		t.Error("Delete() should fail for missing profile")
+	}
+	if names, _ := store.Names(); !slices.Equal(names, []string{"brother", "copy"}) {
+		t.Errorf("Names() = %v, want [brother copy]", names)
+	}
+}
+
+func TestProfileNameFromPath(t *testing.T) {
+	if got := ProfileNameFromPath("exports/shop.json"); got != "shop" {
+		t.Errorf("ProfileNameFromPath() = %q, want shop", got)
+	}
+}
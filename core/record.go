@@ -5,18 +5,57 @@ import (
 	"fmt"
 	"image/png"
 	"log"
+	"maps"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/boombuler/barcode"
-	"github.com/boombuler/barcode/ean"
 )
 
 type Record struct {
-	Text  string
-	Ean   string
-	Times int
+	Text string
+	// Content of the barcode. Named after EAN, but holds the payload
+	// of any symbology.
+	Ean       string
+	Times     int
+	Symbology Symbology
+	// One based number of the source table row (header is row 1), zero if unknown.
+	Row int
+	// Name of the source Excel sheet, empty for CSV.
+	Sheet string
+	// Price in major currency units, nil if the row has no price.
+	Price *float64
+	// Quantity of the product used for unit price, nil if the row has no quantity.
+	Quantity *Quantity
+	// EAN-2 or EAN-5 add-on printed right of EAN, UPC-A or ISBN code, e.g. issue number.
+	AddOn string
+	// All cells of the row by their column header. Columns
+	// without header are named by their letters ("C").
+	Fields map[string]string
+}
+
+// Headers of the table columns used to build records.
+// Ean is required unless GS1 columns are set, the rest is optional
+// and ignored when empty.
+// Column is given by its header, letters ("C") or one based position ("#3").
+type Columns struct {
+	Text      string
+	Ean       string
+	Times     string
+	Symbology string
+	Price     string
+	Quantity  string
+	AddOn     string
+	// Columns of GS1 Application Identifiers by the AI, e.g. "17" to "Expiry".
+	// Barcode content is the GS1 element string built from them, Ean column
+	// is optional and used as GTIN (01) unless the AI has its own column.
+	GS1 map[string]string
+	// Unit of quantities given only by a number, e.g. "kg".
+	QuantityUnit string
+	// Number of first rows searched for the header row, DefaultHeaderSearchRows if zero.
+	HeaderSearchRows int
 }
 
 // Extracts Record structures from a 2D string table using column headers.
@@ -26,48 +65,73 @@ func RecordsFromTable(table [][]string, text string, ean string, times string) (
 	if text == "" {
 		return nil, errors.New("Text column header cannot be empty")
 	}
-	if ean == "" {
+	return RecordsFromColumns(table, Columns{Text: text, Ean: ean, Times: times})
+}
+
+// Extracts Record structures from a 2D string table using the given columns.
+// Behaves as RecordsFromTable, additionally reads per-row symbology when
+// the symbology column is set. Rows with empty symbology cell keep the
+// default (empty) symbology, which is resolved later by the caller.
+// Header row is the first row containing all columns, so title rows above
+// it are skipped. Headers are matched regardless of case, spaces and diacritics.
+// Text column is optional, records have empty text without it.
+func RecordsFromColumns(table [][]string, columns Columns) ([]Record, error) {
+	text := columns.Text
+	ean := columns.Ean
+	times := columns.Times
+	symbology := columns.Symbology
+	if ean == "" && len(columns.GS1) == 0 {
 		return nil, errors.New("Ean column header cannot be empty")
 	}
+	if err := ValidateGS1Columns(columns.GS1); err != nil {
+		return nil, err
+	}
+	if _, ok := columns.GS1["01"]; ok {
+		// GTIN has its own column, so Ean column is not needed.
+		ean = ""
+	}
 	if len(table) == 0 {
 		return nil, errors.New("Table with data cannot be empty")
 	}
 
 	// Find headers
-	text_index := -1
-	ean_index := -1
-	times_index := -1
-	text_lower := strings.ToLower(text)
-	ean_lower := strings.ToLower(ean)
-	times_lower := strings.ToLower(times)
-	for i, item := range table[0] {
-		switch strings.ToLower(item) {
-		case text_lower:
-			text_index = i
-		case ean_lower:
-			ean_index = i
-		case times_lower:
-			times_index = i
-		}
-	}
-	// Check if headers was found
-	if text_index == -1 {
-		return nil, fmt.Errorf("Cannot find text header '%s'", text)
-	}
-	if ean_index == -1 {
-		return nil, fmt.Errorf("Cannot find ean header '%s'", ean)
+	headers := []string{text, ean, times, symbology, columns.Price, columns.Quantity, columns.AddOn}
+	header_names := []string{"text", "ean", "times", "symbology", "price", "quantity", "add-on"}
+	gs1_ais := slices.Sorted(maps.Keys(columns.GS1))
+	for _, ai := range gs1_ais {
+		headers = append(headers, columns.GS1[ai])
+		header_names = append(header_names, fmt.Sprintf("AI (%s)", ai))
 	}
-	if times_index == -1 && strings.TrimSpace(times) != "" {
-		return nil, fmt.Errorf("Cannot find times header '%s'", times)
+	match, ok := findHeaderRow(table, headers, columns.HeaderSearchRows)
+	if !ok {
+		for i, name := range header_names {
+			if match.indexes[i] == -1 && strings.TrimSpace(headers[i]) != "" {
+				return nil, fmt.Errorf(
+					"Cannot find %s header '%s', available headers: %s",
+					name, headers[i], availableHeaders(table[match.row]))
+			}
+		}
 	}
+	text_index := match.indexes[0]
+	ean_index := match.indexes[1]
+	times_index := match.indexes[2]
+	symbology_index := match.indexes[3]
+	price_index := match.indexes[4]
+	quantity_index := match.indexes[5]
+	add_on_index := match.indexes[6]
+	field_names := fieldNames(table[match.row])
 
 	// Print each record
 	ret := []Record{}
-	for _, csv_line := range table[1:] {
-		if csv_line[ean_index] != "" {
+	for i, csv_line := range table[match.row+1:] {
+		ean_value := cell(csv_line, ean_index)
+		if len(gs1_ais) > 0 {
+			ean_value = gs1Content(csv_line, gs1_ais, match.indexes[7:], ean_index)
+		}
+		if ean_value != "" {
 			times_value := 1
 			if times_index != -1 {
-				times_str := strings.TrimSpace(csv_line[times_index])
+				times_str := strings.TrimSpace(cell(csv_line, times_index))
 				// Some countries use ',' instead of '.' as the decimal separator
 				times_str = strings.ReplaceAll(times_str, ",", ".")
 				value_float, err := strconv.ParseFloat(times_str, 0)
@@ -81,11 +145,41 @@ func RecordsFromTable(table [][]string, text string, ean string, times string) (
 					times_value = int(value_float)
 				}
 			}
+			var symbology_value Symbology
+			if symbology_index != -1 && strings.TrimSpace(cell(csv_line, symbology_index)) != "" {
+				value, err := SymbologyFromString(cell(csv_line, symbology_index))
+				if err != nil {
+					return nil, err
+				}
+				symbology_value = value
+			}
+			var price_value *float64
+			if price_str := strings.TrimSpace(cell(csv_line, price_index)); price_str != "" {
+				value, err := ParsePrice(price_str)
+				if err != nil {
+					return nil, fmt.Errorf("Price column contain invalid value in row %d: %w", match.row+i+2, err)
+				}
+				price_value = &value
+			}
+			var quantity_value *Quantity
+			if quantity_str := strings.TrimSpace(cell(csv_line, quantity_index)); quantity_str != "" {
+				value, err := parseQuantityWithUnit(quantity_str, columns.QuantityUnit)
+				if err != nil {
+					return nil, fmt.Errorf("Quantity column contain invalid value in row %d: %w", match.row+i+2, err)
+				}
+				quantity_value = &value
+			}
 			record :=
 				Record{
-					Text:  csv_line[text_index],
-					Ean:   csv_line[ean_index],
-					Times: times_value,
+					Text:      cell(csv_line, text_index),
+					Ean:       ean_value,
+					Times:     times_value,
+					Symbology: symbology_value,
+					Row:       match.row + i + 2,
+					Price:     price_value,
+					Quantity:  quantity_value,
+					AddOn:     strings.TrimSpace(cell(csv_line, add_on_index)),
+					Fields:    fields(field_names, csv_line),
 				}
 			log.Println("Append record:", record)
 			ret = append(ret, record)
@@ -95,17 +189,75 @@ func RecordsFromTable(table [][]string, text string, ean string, times string) (
 	return ret, nil
 }
 
+// Returns human readable GS1 element string built from cells of the AI
+// columns at the indexes. Ean column is GTIN (01) unless it has own column.
+// Returns empty string if all the cells are empty.
+func gs1Content(row []string, ais []string, indexes []int, ean_index int) string {
+	values := map[string]string{}
+	if ean_index != -1 {
+		values["01"] = cell(row, ean_index)
+	}
+	for i, ai := range ais {
+		values[ai] = cell(row, indexes[i])
+	}
+	return BuildGS1ElementString(values).HumanReadable()
+}
+
+// Returns names of the row fields, which are trimmed headers of the header
+// row. Columns without header or with the header of a previous column are
+// named by their letters.
+func fieldNames(header []string) []string {
+	names := make([]string, len(header))
+	seen := map[string]bool{}
+	for i, item := range header {
+		name := strings.TrimSpace(item)
+		if name == "" || seen[name] {
+			name = ColumnName(i)
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+// Returns cells of the row by their field names. Cells after
+// the last named column are named by their letters.
+func fields(names []string, row []string) map[string]string {
+	ret := make(map[string]string, max(len(names), len(row)))
+	for i := range max(len(names), len(row)) {
+		if i < len(names) {
+			ret[names[i]] = cell(row, i)
+		} else {
+			ret[ColumnName(i)] = cell(row, i)
+		}
+	}
+	return ret
+}
+
+// Returns the cell at the index or empty string if the row is shorter.
+// Excel rows are trimmed after the last non empty cell.
+func cell(row []string, index int) string {
+	if index < 0 || index >= len(row) {
+		return ""
+	}
+	return row[index]
+}
+
 // Creates a PNG barcode image file for the record's EAN code.
-// Generates an EAN barcode, scales it to 200x200 pixels, and saves it to the specified path.
+// Generates a barcode in the record symbology (EAN by default), scales it
+// to at least 200x200 pixels, and saves it to the specified path.
 func (r *Record) GenerateBarcode(path string) error {
 	// Create the barcode
-	qrCode, err := ean.Encode(r.Ean)
+	code, err := r.Symbology.Encode(r.Ean)
 	if err != nil {
 		return err
 	}
 
-	// Scale the barcode to 200x200 pixels
-	ean, err := barcode.Scale(qrCode, 200, 200)
+	// Scale the barcode to 200x200 pixels, wide 1D codes need more
+	// pixels than modules to stay scannable.
+	width := max(200, code.Bounds().Dx())
+	height := max(200, code.Bounds().Dy())
+	scaled, err := barcode.Scale(code, width, height)
 	if err != nil {
 		return err
 	}
@@ -118,5 +270,5 @@ func (r *Record) GenerateBarcode(path string) error {
 	defer file.Close()
 
 	// encode the barcode as png
-	return png.Encode(file, ean)
+	return png.Encode(file, scaled)
 }
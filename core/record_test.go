@@ -3,6 +3,7 @@ package core
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -458,3 +459,238 @@ func TestRecord_ZeroValue(t *testing.T) {
 		t.Errorf("Zero Record.Times = %v, want 0", record.Times)
 	}
 }
+
+func TestRecordsFromColumns_Symbology(t *testing.T) {
+	table := Table{
+		{"Text", "EAN", "Type"},
+		{"Product A", "5901234123457", ""},
+		{"Pallet", "15400141288763", "ITF-14"},
+	}
+
+	records, err := RecordsFromColumns(table, Columns{Text: "Text", Ean: "EAN", Symbology: "type"})
+	if err != nil {
+		t.Fatalf("RecordsFromColumns() failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0].Symbology != "" {
+		t.Errorf("Record.Symbology = %v, want empty", records[0].Symbology)
+	}
+	if records[1].Symbology != SymbologyITF14 {
+		t.Errorf("Record.Symbology = %v, want %v", records[1].Symbology, SymbologyITF14)
+	}
+
+	table[2][2] = "unknown"
+	if _, err := RecordsFromColumns(table, Columns{Text: "Text", Ean: "EAN", Symbology: "Type"}); err == nil {
+		t.Error("RecordsFromColumns() should fail for unknown symbology")
+	}
+	if _, err := RecordsFromColumns(table, Columns{Text: "Text", Ean: "EAN", Symbology: "Kind"}); err == nil {
+		t.Error("RecordsFromColumns() should fail for missing symbology header")
+	}
+}
+
+func TestRecord_GenerateBarcode_Symbologies(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "barcode-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	records := []Record{
+		{Ean: "A-VERY-LONG-INTERNAL-SKU-NUMBER-0001", Symbology: SymbologyCode128},
+		{Ean: "15400141288763", Symbology: SymbologyITF14},
+		{Ean: "https://example.com", Symbology: SymbologyQR},
+	}
+	for i, record := range records {
+		barcodePath := filepath.Join(tmpDir, string(record.Symbology)+".png")
+		if err := record.GenerateBarcode(barcodePath); err != nil {
+			t.Errorf("GenerateBarcode() failed for record %d: %v", i, err)
+		}
+	}
+}
+
+func TestRecordsFromTable_RowNumbers(t *testing.T) {
+	table := Table{
+		{"Text", "EAN"},
+		{"Product A", "5901234123457"},
+		{"Product B", ""},
+		{"Product C"}, // short excel row
+		{"Product D", "96385074"},
+	}
+
+	records, err := RecordsFromTable(table, "Text", "EAN", "")
+	if err != nil {
+		t.Fatalf("RecordsFromTable() failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0].Row != 2 || records[1].Row != 5 {
+		t.Errorf("Record rows = %d, %d, want 2, 5", records[0].Row, records[1].Row)
+	}
+}
+
+func TestRecordsFromColumns_HeaderDetection(t *testing.T) {
+	table := Table{
+		{"Price list"},
+		{},
+		{"Exported", "2024-05-01"},
+		{"Název", " EAN  code ", "Počet kusů"},
+		{"Chléb", "4006381333931", "2"},
+		{"Mléko", "96385074", "1"},
+	}
+	tests := []struct {
+		name    string
+		columns Columns
+		wantErr string
+	}{
+		{name: "Headers", columns: Columns{Text: "nazev", Ean: "EAN code", Times: "pocet kusu"}},
+		{name: "Letters", columns: Columns{Text: "Název", Ean: "B", Times: "C"}},
+		{name: "Positions", columns: Columns{Text: "nazev", Ean: "#2", Times: "#3"}},
+		{
+			name:    "Header below search rows",
+			columns: Columns{Text: "nazev", Ean: "EAN code", HeaderSearchRows: 3},
+			wantErr: "Cannot find text header 'nazev', available headers: 'Price list'",
+		},
+		{
+			name:    "Unknown header",
+			columns: Columns{Text: "nazev", Ean: "barcode"},
+			wantErr: "Cannot find ean header 'barcode', available headers: 'Název', ' EAN  code ', 'Počet kusů'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := RecordsFromColumns(table, tt.columns)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("RecordsFromColumns() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RecordsFromColumns() failed: %v", err)
+			}
+			if len(records) != 2 {
+				t.Fatalf("RecordsFromColumns() returned %d records, want 2", len(records))
+			}
+			if records[0].Text != "Chléb" || records[0].Ean != "4006381333931" || records[0].Times != 2 || records[0].Row != 5 {
+				t.Errorf("RecordsFromColumns() first record = %+v", records[0])
+			}
+		})
+	}
+}
+
+func TestRecordsFromColumns_OnlyPositions(t *testing.T) {
+	// Positional columns do not need any header, so the first row is the header.
+	table := Table{
+		{"Name", "Code"},
+		{"Bread", "4006381333931"},
+	}
+	records, err := RecordsFromColumns(table, Columns{Text: "A", Ean: "#2"})
+	if err != nil {
+		t.Fatalf("RecordsFromColumns() failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Text != "Bread" || records[0].Row != 2 {
+		t.Errorf("RecordsFromColumns() = %+v, want Bread from row 2", records)
+	}
+}
+
+func TestRecordsFromColumns_Fields(t *testing.T) {
+	table := Table{
+		{"Name", " Size ", "", "EAN", "Name"},
+		{"Bread", "500 g", "white", "4006381333931", "dup", "extra"},
+	}
+	records, err := RecordsFromColumns(table, Columns{Ean: "EAN"})
+	if err != nil {
+		t.Fatalf("RecordsFromColumns() failed: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("RecordsFromColumns() returned %d records, want 1", len(records))
+	}
+	want := map[string]string{
+		"Name": "Bread",
+		"Size": "500 g",
+		"C":    "white",
+		"EAN":  "4006381333931",
+		"E":    "dup",
+		"F":    "extra",
+	}
+	fields := records[0].Fields
+	if len(fields) != len(want) {
+		t.Errorf("Fields = %v, want %v", fields, want)
+	}
+	for name, value := range want {
+		if fields[name] != value {
+			t.Errorf("Fields[%q] = %q, want %q", name, fields[name], value)
+		}
+	}
+	if records[0].Text != "" {
+		t.Errorf("Text = %q, want empty without text column", records[0].Text)
+	}
+}
+
+func TestRecordsFromColumns_Price(t *testing.T) {
+	table := Table{
+		{"Name", "EAN", "Price", "Weight"},
+		{"Bread", "4006381333931", "1 234,50 Kč", "500"},
+		{"Milk", "96385074", "", "1 l"},
+	}
+	columns := Columns{Text: "Name", Ean: "EAN", Price: "price", Quantity: "weight", QuantityUnit: "g"}
+	records, err := RecordsFromColumns(table, columns)
+	if err != nil {
+		t.Fatalf("RecordsFromColumns() failed: %v", err)
+	}
+	if records[0].Price == nil || *records[0].Price != 1234.5 {
+		t.Errorf("Price = %v, want 1234.5", records[0].Price)
+	}
+	if records[0].Quantity == nil || *records[0].Quantity != (Quantity{Amount: 0.5, Unit: "kg"}) {
+		t.Errorf("Quantity = %v, want 0.5 kg", records[0].Quantity)
+	}
+	if records[1].Price != nil {
+		t.Errorf("Price = %v, want nil for empty cell", *records[1].Price)
+	}
+
+	table[2][2] = "free"
+	if _, err := RecordsFromColumns(table, columns); err == nil || !strings.Contains(err.Error(), "row 3") {
+		t.Errorf("RecordsFromColumns() error = %v, want invalid price in row 3", err)
+	}
+}
+
+func TestRecordsFromColumns_GS1(t *testing.T) {
+	table := Table{
+		{"Name", "EAN", "Expiry", "Batch", "Serial"},
+		{"Pallet", "5901234123457", "2025-12-31", "L1", "7"},
+		{"Box", "", "", "L2", ""},
+		{"Empty", "", "", "", ""},
+	}
+	columns := Columns{Text: "Name", Ean: "EAN", GS1: map[string]string{"17": "Expiry", "10": "Batch", "21": "Serial"}}
+	records, err := RecordsFromColumns(table, columns)
+	if err != nil {
+		t.Fatalf("RecordsFromColumns() failed: %v", err)
+	}
+	want := []string{"(01)05901234123457(17)251231(10)L1(21)7", "(10)L2"}
+	if len(records) != len(want) {
+		t.Fatalf("RecordsFromColumns() returned %d records, want %d", len(records), len(want))
+	}
+	for i, ean := range want {
+		if records[i].Ean != ean {
+			t.Errorf("records[%d].Ean = %q, want %q", i, records[i].Ean, ean)
+		}
+	}
+
+	// GTIN column replaces the EAN column, which does not have to exist.
+	columns = Columns{Text: "Name", Ean: "missing", GS1: map[string]string{"01": "EAN"}}
+	records, err = RecordsFromColumns(table, columns)
+	if err != nil {
+		t.Fatalf("RecordsFromColumns() failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Ean != "(01)05901234123457" {
+		t.Errorf("RecordsFromColumns() = %v, want single GTIN record", records)
+	}
+
+	columns = Columns{Text: "Name", GS1: map[string]string{"17": "Best before"}}
+	if _, err := RecordsFromColumns(table, columns); err == nil || !strings.Contains(err.Error(), "AI (17)") {
+		t.Errorf("RecordsFromColumns() error = %v, want missing AI (17) header", err)
+	}
+}
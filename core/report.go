@@ -0,0 +1,157 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// What happened to a label copy.
+type ReportStatus string
+
+const (
+	// Label with barcode is in the document.
+	ReportPrinted ReportStatus = "printed"
+	// Label is left out of the document by the skip-invalid policy.
+	ReportSkipped ReportStatus = "skipped"
+	// Label is in the document, but marked as INVALID by the placeholder policy.
+	ReportInvalid ReportStatus = "invalid"
+)
+
+// Label copy of a record in the print report.
+type ReportEntry struct {
+	// One based page of the document, zero for skipped labels.
+	Page int
+	// One based position of the label on the page, always 1 without label sheet.
+	Position int
+	Record   Record
+	// Content encoded to the barcode, may differ from the record code with templates.
+	Ean string
+	// Texts printed on the label, empty for skipped labels.
+	Label LabelText
+	// One based copy of the record.
+	Copy   int
+	Serial string
+	Status ReportStatus
+	// Why the barcode could not be generated, nil for printed labels.
+	Err error
+}
+
+// Header of the print report.
+var reportHeader = []string{"page", "position", "row", "sheet", "text", "ean", "copy", "serial", "status", "error"}
+
+// Returns cells of the entry in order of the report header.
+func (e ReportEntry) cells() []string {
+	page, position := "", ""
+	if e.Page > 0 {
+		page, position = strconv.Itoa(e.Page), strconv.Itoa(e.Position)
+	}
+	message := ""
+	if e.Err != nil {
+		message = e.Err.Error()
+	}
+	return []string{
+		page,
+		position,
+		strconv.Itoa(e.Record.Row),
+		e.Record.Sheet,
+		e.Record.Text,
+		e.Ean,
+		strconv.Itoa(e.Copy),
+		e.Serial,
+		string(e.Status),
+		message,
+	}
+}
+
+// Writes the report as CSV with header to the writer.
+func WriteReport(w io.Writer, entries []ReportEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(reportHeader); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := writer.Write(entry.cells()); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Writes the report as XLSX workbook with a single sheet to the writer.
+// Page, position, row and copy are written as numbers, the rest as text,
+// so leading zeros of codes are kept.
+func WriteReportExcel(w io.Writer, entries []ReportEntry) error {
+	file := excelize.NewFile()
+	defer file.Close()
+	const sheet = "Report"
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	header := make([]any, len(reportHeader))
+	for i, name := range reportHeader {
+		header[i] = name
+	}
+	if err := stream.SetRow("A1", header); err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		row := []any{}
+		for _, value := range entry.cells() {
+			row = append(row, value)
+		}
+		for c, number := range map[int]int{0: entry.Page, 1: entry.Position, 2: entry.Record.Row, 6: entry.Copy} {
+			if number > 0 {
+				row[c] = number
+			}
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := stream.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+	return file.Write(w)
+}
+
+// Validates that the report path has .csv or .xlsx extension.
+func validateReportPath(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".xlsx":
+		return nil
+	}
+	return fmt.Errorf("Report file must have a .csv or .xlsx extension, got '%s'", path)
+}
+
+// Writes the report to the file at path, format is given by its
+// extension, .csv or .xlsx.
+func SaveReport(path string, entries []ReportEntry) error {
+	if err := validateReportPath(path); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if strings.ToLower(filepath.Ext(path)) == ".xlsx" {
+		return WriteReportExcel(file, entries)
+	}
+	return WriteReport(file, entries)
+}
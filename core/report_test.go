@@ -0,0 +1,179 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestPdf_Report(t *testing.T) {
+	records := []Record{
+		{Text: "Product A", Ean: "5901234123457", Times: 2, Row: 2},
+		{Text: "Broken", Ean: "5901234123458", Times: 2, Row: 3},
+		{Text: "Product B", Ean: "4006381333931", Times: 1, Row: 4},
+	}
+	type entry struct {
+		page   int
+		row    int
+		copy   int
+		status ReportStatus
+	}
+	tests := []struct {
+		policy ErrorPolicy
+		want   []entry
+	}{
+		{
+			policy: PolicySkipInvalid,
+			want: []entry{
+				{page: 1, row: 2, copy: 1, status: ReportPrinted},
+				{page: 2, row: 2, copy: 2, status: ReportPrinted},
+				{page: 0, row: 3, copy: 1, status: ReportSkipped},
+				{page: 0, row: 3, copy: 2, status: ReportSkipped},
+				{page: 3, row: 4, copy: 1, status: ReportPrinted},
+			},
+		},
+		{
+			policy: PolicyPlaceholder,
+			want: []entry{
+				{page: 1, row: 2, copy: 1, status: ReportPrinted},
+				{page: 2, row: 2, copy: 2, status: ReportPrinted},
+				{page: 3, row: 3, copy: 1, status: ReportInvalid},
+				{page: 4, row: 3, copy: 2, status: ReportInvalid},
+				{page: 5, row: 4, copy: 1, status: ReportPrinted},
+			},
+		},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			pdf := NewPdf()
+			if _, err := pdf.AddPagesWithPolicy(records, 1, tt.policy, log); err != nil {
+				t.Fatalf("AddPagesWithPolicy() failed: %v", err)
+			}
+			report := pdf.Report()
+			if len(report) != len(tt.want) {
+				t.Fatalf("len(Report()) = %d, want %d", len(report), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := entry{page: report[i].Page, row: report[i].Record.Row, copy: report[i].Copy, status: report[i].Status}
+				if got != want {
+					t.Errorf("Report()[%d] = %+v, want %+v", i, got, want)
+				}
+				if (report[i].Err != nil) != (want.status != ReportPrinted) {
+					t.Errorf("Report()[%d].Err = %v, want error only for not printed", i, report[i].Err)
+				}
+			}
+		})
+	}
+}
+
+func TestPdf_Report_SheetAndSerial(t *testing.T) {
+	sheet := LabelSheetPresets["avery-l7160"] // 3x7 labels
+	pdf, err := NewPdfOnSheet(NewLayout(sheet.LabelWidth, sheet.LabelHeight), sheet, 20)
+	if err != nil {
+		t.Fatalf("NewPdfOnSheet() failed: %v", err)
+	}
+	if err := pdf.SetSerialNumbers(SerialNumbers{Enabled: true, Start: 7, Padding: 3}); err != nil {
+		t.Fatalf("SetSerialNumbers() failed: %v", err)
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := pdf.AddPages([]Record{{Text: "Product", Ean: "5901234123457", Times: 3}}, 1, log); err != nil {
+		t.Fatalf("AddPages() failed: %v", err)
+	}
+	want := []struct {
+		page, position int
+		serial         string
+	}{{1, 20, "007"}, {1, 21, "008"}, {2, 1, "009"}}
+	report := pdf.Report()
+	for i, w := range want {
+		if report[i].Page != w.page || report[i].Position != w.position || report[i].Serial != w.serial {
+			t.Errorf("Report()[%d] = page %d, position %d, serial %q, want %+v",
+				i, report[i].Page, report[i].Position, report[i].Serial, w)
+		}
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	entries := []ReportEntry{
+		{Page: 1, Position: 1, Record: Record{Row: 2, Text: "Milk, 1l"}, Ean: "0012345678905", Copy: 1, Serial: "007", Status: ReportPrinted},
+		{Record: Record{Row: 3, Text: "Broken", Sheet: "Food"}, Ean: "123", Copy: 1, Status: ReportSkipped, Err: errors.New("wrong checksum")},
+	}
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, entries); err != nil {
+		t.Fatalf("WriteReport() failed: %v", err)
+	}
+	want := "page,position,row,sheet,text,ean,copy,serial,status,error\n" +
+		"1,1,2,,\"Milk, 1l\",0012345678905,1,007,printed,\n" +
+		",,3,Food,Broken,123,1,,skipped,wrong checksum\n"
+	if buf.String() != want {
+		t.Errorf("WriteReport() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteReportExcel(&buf, entries); err != nil {
+		t.Fatalf("WriteReportExcel() failed: %v", err)
+	}
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("OpenReader() failed: %v", err)
+	}
+	defer file.Close()
+	rows, err := file.GetRows("Report")
+	if err != nil {
+		t.Fatalf("GetRows() failed: %v", err)
+	}
+	if len(rows) != 3 || rows[1][5] != "0012345678905" || rows[1][7] != "007" || rows[2][8] != "skipped" {
+		t.Errorf("GetRows() = %q, want report rows with codes as text", rows)
+	}
+	// Numbers are stored without cell type.
+	isNumber := func(cell string) bool {
+		cellType, _ := file.GetCellType("Report", cell)
+		return cellType == excelize.CellTypeNumber || cellType == excelize.CellTypeUnset
+	}
+	if !isNumber("C2") || isNumber("F2") {
+		t.Error("Row should be written as number and code as text")
+	}
+}
+
+func TestGenerator_Report(t *testing.T) {
+	tmpDir := t.TempDir()
+	table := Table{
+		{"Text", "EAN"},
+		{"A", "5901234123457"},
+		{"B", "5901234123458"},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	gen := Generator{
+		PdfPath:      filepath.Join(tmpDir, "labels.pdf"),
+		TextHeader:   "Text",
+		EanHeader:    "EAN",
+		TimesEachEAN: 1,
+		ErrorPolicy:  PolicySkipInvalid,
+		ReportPath:   filepath.Join(tmpDir, "report.csv"),
+	}
+	if err := gen.GenerateFromTable(table, log); err != nil {
+		t.Fatalf("GenerateFromTable() failed: %v", err)
+	}
+	report, err := os.ReadFile(gen.ReportPath)
+	if err != nil {
+		t.Fatalf("Report was not saved: %v", err)
+	}
+	for _, want := range []string{"1,1,2,,A,5901234123457,1,,printed,", ",,3,,B,5901234123458,1,,skipped,"} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("Report = %q, want line %q", report, want)
+		}
+	}
+
+	gen.CsvPath = "data.csv"
+	gen.ReportPath = filepath.Join(tmpDir, "report.txt")
+	if err := gen.Validate(); err == nil {
+		t.Error("Validate() should fail for report without .csv or .xlsx extension")
+	}
+}
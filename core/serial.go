@@ -0,0 +1,140 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Maximal zero padding of serial numbers, longer numbers do not fit int64.
+const MaxSerialPadding = 18
+
+// Numbering of label copies. Every printed label gets its own serial number
+// made of prefix, number padded by zeros and suffix, e.g. "A-000042/24".
+// Last issued number is kept, so the next run continues the sequence.
+type SerialNumbers struct {
+	Enabled bool `json:"enabled"`
+	// Number of the first label when no number was issued yet.
+	Start int64 `json:"start"`
+	// Difference between following numbers, 1 if zero.
+	Step int64 `json:"step,omitempty"`
+	// Minimal number of digits, shorter numbers are padded by zeros.
+	Padding int    `json:"padding,omitempty"`
+	Prefix  string `json:"prefix,omitempty"`
+	Suffix  string `json:"suffix,omitempty"`
+	// Last issued number, nil if no number was issued yet.
+	Last *int64 `json:"last,omitempty"`
+}
+
+// Validate checks that start, step and padding are not negative.
+func (s SerialNumbers) Validate() error {
+	if s.Start < 0 {
+		return fmt.Errorf("Serial start cannot be negative, got %d", s.Start)
+	}
+	if s.Step < 0 {
+		return fmt.Errorf("Serial step cannot be negative, got %d", s.Step)
+	}
+	if s.Padding < 0 || s.Padding > MaxSerialPadding {
+		return fmt.Errorf("Serial padding must be between 0 and %d, got %d", MaxSerialPadding, s.Padding)
+	}
+	return nil
+}
+
+// Returns the number of the next label.
+func (s SerialNumbers) Next() int64 {
+	if s.Last == nil {
+		return s.Start
+	}
+	return *s.Last + max(1, s.Step)
+}
+
+// Formats the number with padding, prefix and suffix.
+func (s SerialNumbers) Format(number int64) string {
+	return fmt.Sprintf("%s%0*d%s", s.Prefix, s.Padding, number, s.Suffix)
+}
+
+// Returns the formatted serial of the next label, empty if serials are disabled.
+func (s SerialNumbers) peek() string {
+	if !s.Enabled {
+		return ""
+	}
+	return s.Format(s.Next())
+}
+
+// Marks the next number as issued, does nothing if serials are disabled.
+func (s *SerialNumbers) issue() {
+	if !s.Enabled {
+		return
+	}
+	next := s.Next()
+	s.Last = &next
+}
+
+// Reads the last issued serial number from the generator JSON file.
+// Returns nil if the file does not exist or no number was issued yet.
+func LoadSerialState(path string) (*int64, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Join(errors.New("Cannot load serial state"), err)
+	}
+	var state struct {
+		Serial SerialNumbers `json:"serial"`
+	}
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, errors.Join(errors.New("Cannot decode serial state"), err)
+	}
+	return state.Serial.Last, nil
+}
+
+// Writes the last issued serial number to the generator JSON file,
+// the rest of the file is kept as it is, even keys that are not known.
+// Whole generator is saved if the file does not exist yet.
+func (g *Generator) SaveSerialState(path string) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return g.Save(path)
+	}
+	if err != nil {
+		return errors.Join(errors.New("Cannot save serial state"), err)
+	}
+	if content, err = setSerialLast(content, g.Serial.Last); err != nil {
+		return errors.Join(errors.New("Cannot save serial state"), err)
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// Returns the generator JSON with the last issued serial number replaced,
+// other values are not decoded.
+func setSerialLast(content []byte, last *int64) ([]byte, error) {
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, errors.New("Configuration must be a JSON object")
+	}
+	serial := map[string]json.RawMessage{}
+	if raw, ok := config["serial"]; ok && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &serial); err != nil {
+			return nil, err
+		}
+	}
+	delete(serial, "last")
+	if last != nil {
+		serial["last"] = json.RawMessage(strconv.FormatInt(*last, 10))
+	}
+	raw, err := json.Marshal(serial)
+	if err != nil {
+		return nil, err
+	}
+	config["serial"] = raw
+	if content, err = json.MarshalIndent(config, "", "  "); err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
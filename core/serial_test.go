@@ -0,0 +1,189 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSerialNumbers_Format(t *testing.T) {
+	last := int64(41)
+	tests := []struct {
+		name   string
+		serial SerialNumbers
+		want   string
+	}{
+		{name: "Start", serial: SerialNumbers{Enabled: true, Start: 7}, want: "7"},
+		{name: "Padding, prefix and suffix", serial: SerialNumbers{Enabled: true, Start: 7, Padding: 6, Prefix: "A-", Suffix: "/24"}, want: "A-000007/24"},
+		{name: "Continues after last", serial: SerialNumbers{Enabled: true, Start: 7, Last: &last}, want: "42"},
+		{name: "Step", serial: SerialNumbers{Enabled: true, Step: 10, Last: &last}, want: "51"},
+		{name: "Disabled", serial: SerialNumbers{Start: 7}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.serial.peek(); got != tt.want {
+				t.Errorf("peek() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSerialNumbers_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		serial  SerialNumbers
+		wantErr bool
+	}{
+		{name: "Zero value", serial: SerialNumbers{}},
+		{name: "Negative start", serial: SerialNumbers{Start: -1}, wantErr: true},
+		{name: "Negative step", serial: SerialNumbers{Step: -1}, wantErr: true},
+		{name: "Too much padding", serial: SerialNumbers{Padding: 19}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.serial.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPdf_AddPages_Serial(t *testing.T) {
+	pdf := NewPdf()
+	pdf.pdf.SetCompression(false)
+	if err := pdf.SetTemplates(LabelTemplates{Top: "{{.Text}} #{{.Serial}}", Barcode: "{{.Ean}}-{{.Serial}}"}); err != nil {
+		t.Fatalf("SetTemplates() failed: %v", err)
+	}
+	if err := pdf.SetSerialNumbers(SerialNumbers{Enabled: true, Start: 8, Step: 2, Padding: 3}); err != nil {
+		t.Fatalf("SetSerialNumbers() failed: %v", err)
+	}
+	records := []Record{
+		{Text: "Laptop", Ean: "ASSET", Times: 2, Symbology: SymbologyCode128},
+		{Text: "Invalid", Ean: "ASSET", Times: 1},
+		{Text: "Monitor", Ean: "ASSET", Times: 1, Symbology: SymbologyQR},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	skipped, err := pdf.AddPagesWithPolicy(records, 1, PolicySkipInvalid, log)
+	if err != nil {
+		t.Fatalf("AddPagesWithPolicy() failed: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0].Record.Text != "Invalid" {
+		t.Errorf("skipped = %+v, want the EAN record", skipped)
+	}
+	var buf bytes.Buffer
+	if err := pdf.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() failed: %v", err)
+	}
+	// Skipped label does not use a number.
+	for _, want := range []string{"Laptop #008", "ASSET-008", "Laptop #010", "ASSET-010", "Monitor #012", "ASSET-012"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("PDF does not contain %q", want)
+		}
+	}
+	if last := pdf.SerialNumbers().Last; last == nil || *last != 12 {
+		t.Errorf("SerialNumbers().Last = %v, want 12", last)
+	}
+}
+
+func TestGenerator_GenerateFromTable_Serial(t *testing.T) {
+	dir := t.TempDir()
+	table := Table{
+		{"Text", "Code", "Times"},
+		{"Laptop", "ASSET", "2"},
+		{"Monitor", "ASSET", "1"},
+	}
+	gen := Generator{
+		PdfPath:      filepath.Join(dir, "assets.pdf"),
+		TextHeader:   "Text",
+		EanHeader:    "Code",
+		TimesHeader:  "Times",
+		TimesEachEAN: 1,
+		Symbology:    SymbologyCode128,
+		Templates:    LabelTemplates{Barcode: "{{.Ean}}-{{.Serial}}"},
+		Serial:       SerialNumbers{Enabled: true, Start: 1, Padding: 3},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := gen.GenerateFromTable(table, log); err != nil {
+		t.Fatalf("GenerateFromTable() failed: %v", err)
+	}
+	if gen.Serial.Last == nil || *gen.Serial.Last != 3 {
+		t.Fatalf("Serial.Last = %v, want 3", gen.Serial.Last)
+	}
+
+	// Next run continues the sequence.
+	if err := gen.GenerateFromTable(table, log); err != nil {
+		t.Fatalf("GenerateFromTable() failed: %v", err)
+	}
+	if *gen.Serial.Last != 6 {
+		t.Errorf("Serial.Last = %d, want 6", *gen.Serial.Last)
+	}
+}
+
+func TestGenerator_SaveSerialState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".EANBaker.json")
+	if last, err := LoadSerialState(path); err != nil || last != nil {
+		t.Fatalf("LoadSerialState() = %v, %v, want nil for missing file", last, err)
+	}
+
+	last := int64(42)
+	gen := Generator{TextHeader: "Name", TimesEachEAN: 1, Serial: SerialNumbers{Enabled: true, Last: &last}}
+	if err := gen.SaveSerialState(path); err != nil {
+		t.Fatalf("SaveSerialState() failed: %v", err)
+	}
+
+	// Only the serial state is updated in existing file.
+	other := Generator{TextHeader: "Other", Serial: SerialNumbers{Last: &last}}
+	*other.Serial.Last = 50
+	if err := other.SaveSerialState(path); err != nil {
+		t.Fatalf("SaveSerialState() failed: %v", err)
+	}
+	loaded, err := LoadSerialState(path)
+	if err != nil || loaded == nil || *loaded != 50 {
+		t.Fatalf("LoadSerialState() = %v, %v, want 50", loaded, err)
+	}
+	saved, err := LoadGenerator(path, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("LoadGenerator() failed: %v", err)
+	}
+	if saved.TextHeader != "Name" || !saved.Serial.Enabled {
+		t.Errorf("SaveSerialState() changed other settings: %+v", saved)
+	}
+}
+
+func TestGenerator_SaveSerialState_UnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".EANBaker.json")
+	// Keys unknown to this version must not stop saving the state of a generated PDF.
+	content := `{"text_header": "Name", "legacy_option": {"kept": true}, "serial": {"enabled": true, "prefix": "A-", "last": 3}}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	last := int64(7)
+	gen := Generator{Serial: SerialNumbers{Enabled: true, Last: &last}}
+	if err := gen.SaveSerialState(path); err != nil {
+		t.Fatalf("SaveSerialState() failed: %v", err)
+	}
+	loaded, err := LoadSerialState(path)
+	if err != nil || loaded == nil || *loaded != 7 {
+		t.Fatalf("LoadSerialState() = %v, %v, want 7", loaded, err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"legacy_option"`, `"kept": true`, `"prefix": "A-"`, `"text_header": "Name"`} {
+		if !strings.Contains(string(saved), want) {
+			t.Errorf("SaveSerialState() = %s, want it to keep %s", saved, want)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(`[1]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := gen.SaveSerialState(path); err == nil {
+		t.Error("SaveSerialState() should fail for file that is not a JSON object")
+	}
+}
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/code39"
+	"github.com/boombuler/barcode/datamatrix"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+	"github.com/boombuler/barcode/twooffive"
+)
+
+type Symbology string
+
+const (
+	SymbologyEAN        Symbology = "ean"
+	SymbologyUPCA       Symbology = "upca"
+	SymbologyISBN       Symbology = "isbn"
+	SymbologyCode128    Symbology = "code128"
+	SymbologyCode39     Symbology = "code39"
+	SymbologyITF14      Symbology = "itf14"
+	SymbologyDataMatrix Symbology = "datamatrix"
+	SymbologyQR         Symbology = "qr"
+	// GS1 element strings given in human readable form "(01)...(17)...".
+	SymbologyGS1128        Symbology = "gs1-128"
+	SymbologyGS1DataMatrix Symbology = "gs1-datamatrix"
+)
+
+// List of all supported symbologies in the order they are offered to the user.
+var Symbologies = []Symbology{
+	SymbologyEAN,
+	SymbologyUPCA,
+	SymbologyISBN,
+	SymbologyCode128,
+	SymbologyCode39,
+	SymbologyITF14,
+	SymbologyDataMatrix,
+	SymbologyQR,
+	SymbologyGS1128,
+	SymbologyGS1DataMatrix,
+}
+
+// Converts a string to a Symbology.
+// Matching is case insensitive and ignores '-', '_' and spaces, so "Code-128"
+// and "ITF 14" are accepted. Empty string is converted to the default EAN symbology.
+func SymbologyFromString(s string) (Symbology, error) {
+	normalized := strings.ToLower(strings.TrimSpace(s))
+	normalized = strings.NewReplacer("-", "", "_", "", " ", "").Replace(normalized)
+	switch normalized {
+	case "", "ean", "ean8", "ean13":
+		return SymbologyEAN, nil
+	case "upc", "upca":
+		return SymbologyUPCA, nil
+	case "isbn", "isbn13", "bookland":
+		return SymbologyISBN, nil
+	case "code128":
+		return SymbologyCode128, nil
+	case "code39":
+		return SymbologyCode39, nil
+	case "itf", "itf14":
+		return SymbologyITF14, nil
+	case "datamatrix", "dm":
+		return SymbologyDataMatrix, nil
+	case "qr", "qrcode":
+		return SymbologyQR, nil
+	case "gs1128", "ucc128", "ean128":
+		return SymbologyGS1128, nil
+	case "gs1datamatrix", "gs1dm":
+		return SymbologyGS1DataMatrix, nil
+	default:
+		return "", fmt.Errorf("Unknown symbology %q", s)
+	}
+}
+
+// Implements the json.Unmarshaler interface for Symbology.
+// Accepts the same values as SymbologyFromString.
+func (s *Symbology) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	symbology, err := SymbologyFromString(str)
+	if err != nil {
+		return err
+	}
+	*s = symbology
+	return nil
+}
+
+// Reports whether the symbology is a two dimensional matrix code.
+func (s Symbology) Is2D() bool {
+	return s == SymbologyDataMatrix || s == SymbologyQR || s == SymbologyGS1DataMatrix
+}
+
+// Reports whether the symbology encodes GS1 element strings.
+func (s Symbology) IsGS1() bool {
+	return s == SymbologyGS1128 || s == SymbologyGS1DataMatrix
+}
+
+// Encodes content using the symbology.
+// Empty symbology is treated as EAN. UPC-A accepts 11 or 12 digits and ITF-14
+// accepts 13 or 14 digits, the missing check digit is computed. ISBN is
+// an EAN-13 with 978 or 979 prefix, hyphens between its groups are ignored.
+// GS1-128 and GS1 DataMatrix take human readable GS1 element string, which
+// is validated and encoded with FNC1.
+func (s Symbology) Encode(content string) (barcode.Barcode, error) {
+	switch s {
+	case SymbologyEAN, "":
+		return ean.Encode(content)
+	case SymbologyUPCA:
+		if len(content) != 11 && len(content) != 12 {
+			return nil, fmt.Errorf("UPC-A code must have 11 or 12 digits, got %q", content)
+		}
+		// UPC-A is an EAN-13 with leading zero, bars are identical.
+		return ean.Encode("0" + content)
+	case SymbologyISBN:
+		content = cleanISBN(content)
+		if len(content) != 12 && len(content) != 13 {
+			return nil, fmt.Errorf("ISBN must have 12 or 13 digits, got %q", content)
+		}
+		if !hasISBNPrefix(content) {
+			return nil, fmt.Errorf("ISBN must start with 978 or 979, got %q", content)
+		}
+		return ean.Encode(content)
+	case SymbologyCode128:
+		return code128.Encode(content)
+	case SymbologyCode39:
+		return code39.Encode(strings.ToUpper(content), false, false)
+	case SymbologyITF14:
+		if len(content) != 13 && len(content) != 14 {
+			return nil, fmt.Errorf("ITF-14 code must have 13 or 14 digits, got %q", content)
+		}
+		check, err := GS1CheckDigit(content[:13])
+		if err != nil {
+			return nil, err
+		}
+		withCheck := content[:13] + string(check)
+		if len(content) == 14 && withCheck != content {
+			return nil, errors.New("ITF-14 checksum mismatch")
+		}
+		return twooffive.Encode(withCheck, true)
+	case SymbologyDataMatrix:
+		return datamatrix.Encode(content)
+	case SymbologyQR:
+		return qr.Encode(content, qr.M, qr.Auto)
+	case SymbologyGS1128:
+		data, err := encodeGS1(content, code128.FNC1)
+		if err != nil {
+			return nil, err
+		}
+		return code128.Encode(data)
+	case SymbologyGS1DataMatrix:
+		// FNC1 rune of Code 128 is not in GS1 character set, so it cannot clash with data.
+		data, err := encodeGS1(content, code128.FNC1)
+		if err != nil {
+			return nil, err
+		}
+		return encodeDataMatrix(data, code128.FNC1, "GS1 DataMatrix")
+	default:
+		return nil, fmt.Errorf("Unknown symbology %q", string(s))
+	}
+}
+
+// Computes the GS1 modulo 10 check digit for the given digits
+// (EAN-8, EAN-13, UPC-A, ITF-14, SSCC, ... without their check digit).
+func GS1CheckDigit(digits string) (rune, error) {
+	sum := 0
+	weight := 3
+	for i := len(digits) - 1; i >= 0; i-- {
+		c := digits[i]
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("Cannot compute check digit of non numeric %q", digits)
+		}
+		sum += int(c-'0') * weight
+		weight = 4 - weight
+	}
+	return rune('0' + (10-sum%10)%10), nil
+}
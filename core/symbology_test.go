@@ -0,0 +1,134 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSymbologyFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Symbology
+		wantErr bool
+	}{
+		{name: "Empty is EAN", value: "", want: SymbologyEAN},
+		{name: "EAN-13 alias", value: "EAN-13", want: SymbologyEAN},
+		{name: "UPC-A", value: "UPC-A", want: SymbologyUPCA},
+		{name: "ISBN-13", value: "ISBN-13", want: SymbologyISBN},
+		{name: "Code 128 with space", value: "Code 128", want: SymbologyCode128},
+		{name: "Code39 upper case", value: "CODE39", want: SymbologyCode39},
+		{name: "ITF-14", value: "itf-14", want: SymbologyITF14},
+		{name: "DataMatrix", value: "DataMatrix", want: SymbologyDataMatrix},
+		{name: "QR code", value: "qr_code", want: SymbologyQR},
+		{name: "GS1-128", value: "GS1-128", want: SymbologyGS1128},
+		{name: "EAN-128 alias", value: "EAN 128", want: SymbologyGS1128},
+		{name: "GS1 DataMatrix", value: "GS1 DataMatrix", want: SymbologyGS1DataMatrix},
+		{name: "Unknown", value: "pdf417", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SymbologyFromString(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SymbologyFromString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("SymbologyFromString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSymbology_UnmarshalJSON(t *testing.T) {
+	var s Symbology
+	if err := json.Unmarshal([]byte(`"Code-128"`), &s); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	if s != SymbologyCode128 {
+		t.Errorf("Unmarshal() = %v, want %v", s, SymbologyCode128)
+	}
+	if err := json.Unmarshal([]byte(`"unknown"`), &s); err == nil {
+		t.Error("Unmarshal() should fail for unknown symbology")
+	}
+}
+
+func TestSymbology_Is2D(t *testing.T) {
+	for _, s := range Symbologies {
+		want := s == SymbologyDataMatrix || s == SymbologyQR || s == SymbologyGS1DataMatrix
+		if s.Is2D() != want {
+			t.Errorf("%v.Is2D() = %v, want %v", s, s.Is2D(), want)
+		}
+	}
+}
+
+func TestSymbology_Encode(t *testing.T) {
+	tests := []struct {
+		name      string
+		symbology Symbology
+		content   string
+		wantErr   bool
+	}{
+		{name: "Default is EAN", symbology: "", content: "5901234123457"},
+		{name: "EAN-8", symbology: SymbologyEAN, content: "96385074"},
+		{name: "EAN wrong checksum", symbology: SymbologyEAN, content: "5901234123458", wantErr: true},
+		{name: "UPC-A", symbology: SymbologyUPCA, content: "036000291452"},
+		{name: "UPC-A without check digit", symbology: SymbologyUPCA, content: "03600029145"},
+		{name: "UPC-A wrong length", symbology: SymbologyUPCA, content: "5901234123457", wantErr: true},
+		{name: "Code128", symbology: SymbologyCode128, content: "SKU-42/a"},
+		{name: "Code39", symbology: SymbologyCode39, content: "abc-123"},
+		{name: "ITF-14", symbology: SymbologyITF14, content: "15400141288763"},
+		{name: "ITF-14 without check digit", symbology: SymbologyITF14, content: "1540014128876"},
+		{name: "ITF-14 wrong checksum", symbology: SymbologyITF14, content: "15400141288760", wantErr: true},
+		{name: "ITF-14 wrong length", symbology: SymbologyITF14, content: "123", wantErr: true},
+		{name: "DataMatrix", symbology: SymbologyDataMatrix, content: "Any text 123"},
+		{name: "QR", symbology: SymbologyQR, content: "https://example.com/item/42"},
+		{name: "GS1-128", symbology: SymbologyGS1128, content: "(01)09501101530003(17)251231(10)ABC123"},
+		{name: "GS1-128 invalid date", symbology: SymbologyGS1128, content: "(01)09501101530003(17)251331", wantErr: true},
+		{name: "GS1-128 plain text", symbology: SymbologyGS1128, content: "09501101530003", wantErr: true},
+		{name: "GS1 DataMatrix", symbology: SymbologyGS1DataMatrix, content: "(01)09501101530003(21)12345"},
+		{name: "Unknown", symbology: Symbology("pdf417"), content: "123", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := tt.symbology.Encode(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Encode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			wantDimensions := byte(1)
+			if tt.symbology.Is2D() {
+				wantDimensions = 2
+			}
+			if code.Metadata().Dimensions != wantDimensions {
+				t.Errorf("Encode() dimensions = %d, want %d", code.Metadata().Dimensions, wantDimensions)
+			}
+		})
+	}
+}
+
+func TestGS1CheckDigit(t *testing.T) {
+	tests := []struct {
+		digits  string
+		want    rune
+		wantErr bool
+	}{
+		{digits: "590123412345", want: '7'},
+		{digits: "9638507", want: '4'},
+		{digits: "1540014128876", want: '3'},
+		{digits: "03600029145", want: '2'},
+		{digits: "12a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.digits, func(t *testing.T) {
+			got, err := GS1CheckDigit(tt.digits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GS1CheckDigit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("GS1CheckDigit() = %c, want %c", got, tt.want)
+			}
+		})
+	}
+}
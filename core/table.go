@@ -3,8 +3,11 @@ package core
 import (
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
 	"log"
+	"strconv"
+	"strings"
 
 	"github.com/xuri/excelize/v2"
 )
@@ -34,18 +37,88 @@ func TableFromCsv(r io.Reader, comma rune) (Table, error) {
 	return Table(csv_data), nil
 }
 
-// Reads Excel data from an io.Reader and returns the first sheet as a 2D string table.
-// Opens the Excel file and extracts all rows from the first available sheet.
+// Table read from a single sheet of a workbook. Sheet is empty for CSV input.
+type SheetTable struct {
+	Sheet string
+	Table Table
+}
+
+// Reads Excel data from an io.Reader and returns the sheet with
+// the zero based index as a 2D string table.
 func TableFromExcel(r io.Reader, sheet int) (Table, error) {
 	exel, err := excelize.OpenReader(r)
 	if err != nil {
 		return nil, err
 	}
+	defer exel.Close()
 
 	sheets := exel.GetSheetList()
 	log.Println("Sheets:", sheets)
 	if len(sheets) == 0 {
 		return nil, errors.New("Excel containing 0 sheets.")
 	}
-	return exel.GetRows(sheets[0])
+	if sheet < 0 || sheet >= len(sheets) {
+		return nil, fmt.Errorf("Sheet index %d out of range, excel contains %d sheets", sheet, len(sheets))
+	}
+	return exel.GetRows(sheets[sheet])
+}
+
+// Returns names of all sheets of the Excel workbook in their order.
+func ExcelSheetNames(r io.Reader) ([]string, error) {
+	exel, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer exel.Close()
+	return exel.GetSheetList(), nil
+}
+
+// Returns the zero based index of the sheet given by its name
+// (case insensitive) or by its one based position. Empty sheet
+// selects the first one.
+func FindSheet(sheets []string, sheet string) (int, error) {
+	if len(sheets) == 0 {
+		return 0, errors.New("Excel containing 0 sheets.")
+	}
+	sheet = strings.TrimSpace(sheet)
+	if sheet == "" {
+		return 0, nil
+	}
+	for i, name := range sheets {
+		if strings.EqualFold(name, sheet) {
+			return i, nil
+		}
+	}
+	if position, err := strconv.Atoi(sheet); err == nil && position >= 1 && position <= len(sheets) {
+		return position - 1, nil
+	}
+	return 0, fmt.Errorf("Cannot find sheet '%s', available sheets: %s", sheet, strings.Join(sheets, ", "))
+}
+
+// Reads Excel data from an io.Reader and returns the selected sheet,
+// or all sheets in workbook order if merge is set. Sheet is selected
+// by name or one based position as in FindSheet.
+func SheetTablesFromExcel(r io.Reader, sheet string, merge bool) ([]SheetTable, error) {
+	exel, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer exel.Close()
+
+	sheets := exel.GetSheetList()
+	log.Println("Sheets:", sheets)
+	selected, err := selectSheets(sheets, sheet, merge)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []SheetTable{}
+	for _, name := range selected {
+		rows, err := exel.GetRows(name)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, SheetTable{Sheet: name, Table: rows})
+	}
+	return ret, nil
 }
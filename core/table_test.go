@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+
+	"github.com/xuri/excelize/v2"
 )
 
 func TestTableFromCsv(t *testing.T) {
@@ -377,3 +379,130 @@ func TestTableFromCsv_SemicolonSeparatedRealWorld(t *testing.T) {
 		t.Errorf("Price = %v, want %v", table[1][2], "9,99")
 	}
 }
+
+// Creates an in memory workbook with the given sheets, each sheet is
+// filled by its rows. Returns the serialized workbook.
+func excelWorkbook(t *testing.T, names []string, sheets map[string]Table) *bytes.Reader {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	for i, name := range names {
+		if i == 0 {
+			f.SetSheetName("Sheet1", name)
+		} else if _, err := f.NewSheet(name); err != nil {
+			t.Fatalf("NewSheet() failed: %v", err)
+		}
+		for r, row := range sheets[name] {
+			for c, value := range row {
+				cell, _ := excelize.CoordinatesToCellName(c+1, r+1)
+				f.SetCellValue(name, cell, value)
+			}
+		}
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("WriteToBuffer() failed: %v", err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestTableFromExcel_SheetIndex(t *testing.T) {
+	names := []string{"First", "Second"}
+	sheets := map[string]Table{
+		"First":  {{"a"}, {"1"}},
+		"Second": {{"b"}, {"2"}},
+	}
+	table, err := TableFromExcel(excelWorkbook(t, names, sheets), 1)
+	if err != nil {
+		t.Fatalf("TableFromExcel() failed: %v", err)
+	}
+	if table[0][0] != "b" {
+		t.Errorf("TableFromExcel() header = %q, want b", table[0][0])
+	}
+	if _, err := TableFromExcel(excelWorkbook(t, names, sheets), 2); err == nil {
+		t.Error("TableFromExcel() should fail for index out of range")
+	}
+}
+
+func TestFindSheet(t *testing.T) {
+	sheets := []string{"Products", "2", "Prices"}
+	tests := []struct {
+		name    string
+		sheet   string
+		want    int
+		wantErr bool
+	}{
+		{name: "Empty selects first", sheet: "", want: 0},
+		{name: "Name", sheet: "Prices", want: 2},
+		{name: "Name case insensitive", sheet: " prices ", want: 2},
+		{name: "Position", sheet: "3", want: 2},
+		{name: "Name wins over position", sheet: "2", want: 1},
+		{name: "Position out of range", sheet: "4", wantErr: true},
+		{name: "Zero position", sheet: "0", wantErr: true},
+		{name: "Unknown name", sheet: "Stock", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindSheet(sheets, tt.sheet)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindSheet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("FindSheet() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	_, err := FindSheet(sheets, "Stock")
+	if err == nil || !strings.Contains(err.Error(), "Products, 2, Prices") {
+		t.Errorf("FindSheet() error should list available sheets, got %v", err)
+	}
+}
+
+func TestSheetTablesFromExcel(t *testing.T) {
+	names := []string{"First", "Second", "Third"}
+	sheets := map[string]Table{
+		"First":  {{"a"}, {"1"}},
+		"Second": {{"b"}, {"2"}},
+		"Third":  {{"c"}, {"3"}},
+	}
+
+	tables, err := SheetTablesFromExcel(excelWorkbook(t, names, sheets), "third", false)
+	if err != nil {
+		t.Fatalf("SheetTablesFromExcel() failed: %v", err)
+	}
+	if len(tables) != 1 || tables[0].Sheet != "Third" || tables[0].Table[1][0] != "3" {
+		t.Errorf("SheetTablesFromExcel() = %v, want sheet Third", tables)
+	}
+
+	tables, err = SheetTablesFromExcel(excelWorkbook(t, names, sheets), "", true)
+	if err != nil {
+		t.Fatalf("SheetTablesFromExcel() merge failed: %v", err)
+	}
+	if len(tables) != 3 {
+		t.Fatalf("SheetTablesFromExcel() merge returned %d tables, want 3", len(tables))
+	}
+	for i, name := range names {
+		if tables[i].Sheet != name {
+			t.Errorf("SheetTablesFromExcel() table %d sheet = %q, want %q", i, tables[i].Sheet, name)
+		}
+	}
+
+	if _, err := SheetTablesFromExcel(excelWorkbook(t, names, sheets), "Missing", false); err == nil {
+		t.Error("SheetTablesFromExcel() should fail for unknown sheet")
+	}
+}
+
+func TestExcelSheetNames(t *testing.T) {
+	names := []string{"Products", "Prices"}
+	got, err := ExcelSheetNames(excelWorkbook(t, names, nil))
+	if err != nil {
+		t.Fatalf("ExcelSheetNames() failed: %v", err)
+	}
+	if strings.Join(got, ",") != "Products,Prices" {
+		t.Errorf("ExcelSheetNames() = %v, want %v", got, names)
+	}
+	if _, err := ExcelSheetNames(strings.NewReader("a,b\n1,2")); err == nil {
+		t.Error("ExcelSheetNames() should fail for CSV content")
+	}
+}
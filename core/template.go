@@ -0,0 +1,187 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Go text/template templates of the label slots. Templates get the record
+// fields, so every column is available by its header, e.g. `{{.Name}} / {{.Size}}`,
+// or `{{col . "EAN code"}}` for headers that are not valid identifiers.
+// Built-in values are never hidden by columns, `{{.Columns.Text}}` gives
+// the column of the same name.
+// Empty top template prints the text column, empty bottom template
+// the barcode content and empty side template nothing. Barcode template
+// gives the encoded content, e.g. `{{.Ean}}-{{.Serial}}`, the code
+// column is encoded when it is empty.
+type LabelTemplates struct {
+	Top     string `json:"top,omitempty"`
+	Bottom  string `json:"bottom,omitempty"`
+	Side    string `json:"side,omitempty"`
+	Barcode string `json:"barcode,omitempty"`
+}
+
+// Texts of the label slots.
+type LabelText struct {
+	Top    string
+	Bottom string
+	Side   string
+	// Formatted price and unit price, empty if the record has no price.
+	Price     string
+	UnitPrice string
+	// Content of the barcode.
+	Barcode string
+}
+
+// Parsed label templates, nil template uses the default text of the slot.
+type labelTemplates struct {
+	top     *template.Template
+	bottom  *template.Template
+	side    *template.Template
+	barcode *template.Template
+}
+
+// Returns data passed to the templates. Text, Ean, AddOn, Row and Sheet
+// of the record, all record fields in Columns and every field that does
+// not clash with a built-in value by its name as well.
+func (r Record) TemplateData() map[string]any {
+	return r.templateData(nil)
+}
+
+// Returns data passed to the templates as TemplateData does, with extra
+// built-in values, so columns of the same name are only in Columns.
+func (r Record) templateData(extra map[string]string) map[string]any {
+	data := make(map[string]any, len(r.Fields)+len(extra)+6)
+	for name, value := range r.Fields {
+		data[name] = value
+	}
+	data["Text"] = r.Text
+	data["Ean"] = r.Ean
+	data["AddOn"] = r.AddOn
+	data["Row"] = fmt.Sprint(r.Row)
+	data["Sheet"] = r.Sheet
+	for name, value := range extra {
+		data[name] = value
+	}
+	columns := make(map[string]string, len(r.Fields))
+	for name, value := range r.Fields {
+		columns[name] = value
+	}
+	data["Columns"] = columns
+	return data
+}
+
+// Returns the column of the template data matched regardless of case,
+// spaces and diacritics, e.g. `{{col . "ean code"}}`.
+func templateColumn(data map[string]any, column string) (string, error) {
+	columns, _ := data["Columns"].(map[string]string)
+	if value, ok := columns[column]; ok {
+		return value, nil
+	}
+	normalized := normalizeHeader(column)
+	for name, value := range columns {
+		if normalizeHeader(name) == normalized {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("Cannot find column '%s'", column)
+}
+
+// Functions available in label templates.
+var templateFuncs = template.FuncMap{
+	"col":   templateColumn,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// Parses the template of a single slot, empty template returns nil.
+// Missing fields are reported as errors instead of printing "<no value>".
+func parseTemplate(name string, text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	t, err := template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("Invalid %s template", name), err)
+	}
+	return t, nil
+}
+
+// Validate checks that all templates can be parsed.
+func (t LabelTemplates) Validate() error {
+	_, err := t.parse()
+	return err
+}
+
+// Reports whether no template is set.
+func (t LabelTemplates) Empty() bool {
+	return strings.TrimSpace(t.Top) == "" &&
+		strings.TrimSpace(t.Bottom) == "" &&
+		strings.TrimSpace(t.Side) == "" &&
+		strings.TrimSpace(t.Barcode) == ""
+}
+
+// Parses all templates of the slots.
+func (t LabelTemplates) parse() (labelTemplates, error) {
+	top, err := parseTemplate("top", t.Top)
+	if err != nil {
+		return labelTemplates{}, err
+	}
+	bottom, err := parseTemplate("bottom", t.Bottom)
+	if err != nil {
+		return labelTemplates{}, err
+	}
+	side, err := parseTemplate("side", t.Side)
+	if err != nil {
+		return labelTemplates{}, err
+	}
+	barcode, err := parseTemplate("barcode", t.Barcode)
+	if err != nil {
+		return labelTemplates{}, err
+	}
+	return labelTemplates{top: top, bottom: bottom, side: side, barcode: barcode}, nil
+}
+
+// Executes the template with the data, nil template returns fallback.
+func executeTemplate(t *template.Template, data map[string]any, fallback string) (string, error) {
+	if t == nil {
+		return fallback, nil
+	}
+	var text strings.Builder
+	if err := t.Execute(&text, data); err != nil {
+		return "", err
+	}
+	return text.String(), nil
+}
+
+// Renders texts of all label slots and the barcode content for the record.
+// Formatted price, unit price and serial number of the label are available
+// to templates as FormattedPrice, UnitPrice and Serial. Bottom slot prints
+// the barcode content by default.
+func (t labelTemplates) render(record Record, format PriceFormat, serial string) (LabelText, error) {
+	price, unitPrice, err := format.Record(record)
+	if err != nil {
+		return LabelText{}, err
+	}
+	data := record.templateData(map[string]string{"FormattedPrice": price, "UnitPrice": unitPrice, "Serial": serial})
+	content, err := executeTemplate(t.barcode, data, record.Ean)
+	if err != nil {
+		return LabelText{}, err
+	}
+	top, err := executeTemplate(t.top, data, record.Text)
+	if err != nil {
+		return LabelText{}, err
+	}
+	bottom, err := executeTemplate(t.bottom, data, content)
+	if err != nil {
+		return LabelText{}, err
+	}
+	side, err := executeTemplate(t.side, data, "")
+	if err != nil {
+		return LabelText{}, err
+	}
+	return LabelText{Top: top, Bottom: bottom, Side: side, Price: price, UnitPrice: unitPrice, Barcode: content}, nil
+}
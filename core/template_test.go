@@ -0,0 +1,133 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLabelTemplates_Render(t *testing.T) {
+	record := Record{
+		Text: "Bread",
+		Ean:  "4006381333931",
+		Row:  5,
+		Fields: map[string]string{
+			"Name":     "Bread",
+			"Size":     "500 g",
+			"Colour":   "white",
+			"EAN code": "4006381333931",
+			"Serial":   "column",
+			"Text":     "Bread 500 g",
+		},
+	}
+	tests := []struct {
+		name      string
+		templates LabelTemplates
+		want      LabelText
+		wantErr   bool
+	}{
+		{
+			name: "Defaults",
+			want: LabelText{Top: "Bread", Bottom: "4006381333931", Barcode: "4006381333931"},
+		},
+		{
+			name:      "Columns",
+			templates: LabelTemplates{Top: "{{.Name}} / {{.Size}}", Side: "{{upper .Colour}}"},
+			want:      LabelText{Top: "Bread / 500 g", Bottom: "4006381333931", Side: "WHITE", Barcode: "4006381333931"},
+		},
+		{
+			name:      "Column with space",
+			templates: LabelTemplates{Bottom: `{{index . "EAN code"}} {{col . "ean  CODE"}}`},
+			want:      LabelText{Top: "Bread", Bottom: "4006381333931 4006381333931", Barcode: "4006381333931"},
+		},
+		{
+			name:      "Record values",
+			templates: LabelTemplates{Top: "{{.Text}} (row {{.Row}})"},
+			want:      LabelText{Top: "Bread (row 5)", Bottom: "4006381333931", Barcode: "4006381333931"},
+		},
+		{
+			name:      "Barcode with serial",
+			templates: LabelTemplates{Top: "{{.Text}} #{{.Serial}}", Barcode: "{{.Ean}}/{{.Serial}}"},
+			want:      LabelText{Top: "Bread #S-007", Bottom: "4006381333931/S-007", Barcode: "4006381333931/S-007"},
+		},
+		{
+			name:      "Columns named as built-in values",
+			templates: LabelTemplates{Top: "{{.Text}} #{{.Serial}}", Side: `{{.Columns.Text}} {{.Columns.Serial}} {{col . "text"}}`},
+			want:      LabelText{Top: "Bread #S-007", Bottom: "4006381333931", Side: "Bread 500 g column Bread 500 g", Barcode: "4006381333931"},
+		},
+		{
+			name:      "Unknown column",
+			templates: LabelTemplates{Top: "{{.Price}}"},
+			wantErr:   true,
+		},
+		{
+			name:      "Unknown column by col",
+			templates: LabelTemplates{Top: `{{col . "Price"}}`},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := tt.templates.parse()
+			if err != nil {
+				t.Fatalf("parse() failed: %v", err)
+			}
+			got, err := parsed.render(record, PriceFormat{}, "S-007")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("render() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelTemplates_Validate(t *testing.T) {
+	if err := (LabelTemplates{Top: "{{.Name}"}).Validate(); err == nil {
+		t.Error("Validate() should fail for unclosed action")
+	}
+	if err := (LabelTemplates{Side: "{{unknown .Name}}"}).Validate(); err == nil {
+		t.Error("Validate() should fail for unknown function")
+	}
+	if err := (LabelTemplates{Top: "{{.Name}}", Bottom: "{{lower .Ean}}"}).Validate(); err != nil {
+		t.Errorf("Validate() failed: %v", err)
+	}
+}
+
+func TestPdf_Templates(t *testing.T) {
+	layout := DefaultLayout()
+	layout.Text.Width = 17
+	layout.Side = Box{X: 19, Y: 1, Width: 9, Height: 4}
+	pdf, err := NewPdfWithLayout(layout)
+	if err != nil {
+		t.Fatalf("NewPdfWithLayout() failed: %v", err)
+	}
+	if err := pdf.SetTemplates(LabelTemplates{Top: "{{.Name}} / {{.Size}}", Side: "{{.Colour}}"}); err != nil {
+		t.Fatalf("SetTemplates() failed: %v", err)
+	}
+	pdf.pdf.SetCompression(false)
+	records := []Record{
+		{Ean: "4006381333931", Times: 1, Row: 2, Fields: map[string]string{"Name": "Bread", "Size": "500g", "Colour": "white"}},
+		{Ean: "96385074", Times: 1, Row: 3, Fields: map[string]string{"Name": "Milk"}},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	skipped, err := pdf.AddPagesWithPolicy(records, 1, PolicySkipInvalid, log)
+	if err != nil {
+		t.Fatalf("AddPagesWithPolicy() failed: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0].Record.Row != 3 || !strings.Contains(skipped[0].Err.Error(), "template") {
+		t.Fatalf("skipped = %+v, want row 3 with template error", skipped)
+	}
+	var buf bytes.Buffer
+	if err := pdf.pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() failed: %v", err)
+	}
+	for _, want := range []string{"Bread / 500g", "white", "4006381333931"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("PDF does not contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+type RowStatus string
+
+const (
+	RowValid         RowStatus = "valid"
+	RowCompleted     RowStatus = "check digit completed"
+	RowConverted     RowStatus = "converted from ISBN-10"
+	RowDuplicate     RowStatus = "duplicate"
+	RowWrongChecksum RowStatus = "wrong checksum"
+	RowWrongLength   RowStatus = "wrong length"
+	RowNonNumeric    RowStatus = "non numeric"
+	RowCannotEncode  RowStatus = "cannot encode"
+	RowWrongPrefix   RowStatus = "wrong ISBN prefix"
+	RowInvalidAddOn  RowStatus = "invalid add-on"
+	RowInvalidGS1    RowStatus = "invalid GS1 element string"
+)
+
+// Order of statuses in summaries.
+var rowStatuses = []RowStatus{
+	RowValid,
+	RowCompleted,
+	RowConverted,
+	RowDuplicate,
+	RowWrongChecksum,
+	RowWrongLength,
+	RowNonNumeric,
+	RowCannotEncode,
+	RowWrongPrefix,
+	RowInvalidAddOn,
+	RowInvalidGS1,
+}
+
+// Reports whether a barcode can be generated for the row with the status.
+// Duplicates are printable, they are only reported.
+func (s RowStatus) Printable() bool {
+	return s == RowValid || s == RowCompleted || s == RowConverted || s == RowDuplicate
+}
+
+// Result of validation of a single record.
+type RowValidation struct {
+	// One based number of the source table row, zero if unknown.
+	Row int
+	// Name of the source Excel sheet, empty for CSV.
+	Sheet string
+	Text  string
+	// Value as it is in the table.
+	Ean string
+	// Value that will be encoded, differs from Ean when check digit is completed
+	// or ISBN-10 is converted.
+	Code   string
+	Status RowStatus
+}
+
+type ValidationReport struct {
+	Rows []RowValidation
+}
+
+// Returns rows that are not valid. Completed and duplicate rows are included.
+func (r ValidationReport) Problems() []RowValidation {
+	ret := []RowValidation{}
+	for _, row := range r.Rows {
+		if row.Status != RowValid {
+			ret = append(ret, row)
+		}
+	}
+	return ret
+}
+
+// Reports whether any row cannot be printed.
+func (r ValidationReport) HasErrors() bool {
+	for _, row := range r.Rows {
+		if !row.Status.Printable() {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns number of rows for each status.
+func (r ValidationReport) Counts() map[RowStatus]int {
+	ret := map[RowStatus]int{}
+	for _, row := range r.Rows {
+		ret[row.Status]++
+	}
+	return ret
+}
+
+// Returns human readable one line summary, e.g. "10 rows: 8 valid, 2 wrong checksum".
+func (r ValidationReport) Summary() string {
+	counts := r.Counts()
+	parts := []string{}
+	for _, status := range rowStatuses {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	if len(parts) == 0 {
+		return "0 rows"
+	}
+	return fmt.Sprintf("%d rows: %s", len(r.Rows), strings.Join(parts, ", "))
+}
+
+// Returns human readable description of the row problem.
+func (r RowValidation) String() string {
+	row := "Row ?"
+	if r.Row > 0 {
+		row = fmt.Sprintf("Row %d", r.Row)
+	}
+	if r.Sheet != "" {
+		row = fmt.Sprintf("%s of sheet %q", row, r.Sheet)
+	}
+	if r.Status == RowCompleted || r.Status == RowConverted {
+		return fmt.Sprintf("%s: %q %s to %s", row, r.Ean, r.Status, r.Code)
+	}
+	return fmt.Sprintf("%s: %q %s", row, r.Ean, r.Status)
+}
+
+// Validates a GS1 numeric code (EAN, UPC-A, ITF-14) and classifies it.
+// Lengths are the allowed full lengths including check digit. If complete
+// is set, codes one digit shorter get the computed check digit appended.
+// Returns the code to encode and its status.
+func validateGS1(code string, lengths []int, complete bool) (string, RowStatus) {
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return code, RowNonNumeric
+		}
+	}
+	for _, length := range lengths {
+		switch len(code) {
+		case length:
+			check, _ := GS1CheckDigit(code[:length-1])
+			if rune(code[length-1]) != check {
+				return code, RowWrongChecksum
+			}
+			return code, RowValid
+		case length - 1:
+			if complete {
+				check, _ := GS1CheckDigit(code)
+				return code + string(check), RowCompleted
+			}
+		}
+	}
+	return code, RowWrongLength
+}
+
+// Options of code validation.
+type ValidationOptions struct {
+	// Compute missing check digits of GS1 numeric codes.
+	CompleteCheckDigits bool
+	// Convert ISBN-10 codes of the ISBN symbology to ISBN-13.
+	ConvertISBN10 bool
+}
+
+// Validates content of the code for the symbology and classifies it.
+// EAN (8 or 13 digits), UPC-A (12 digits) and ITF-14 (14 digits) are checked
+// for digits, length and check digit, other symbologies are test encoded.
+// Returns the code to encode and its status.
+func ValidateCode(symbology Symbology, code string, complete bool) (string, RowStatus) {
+	return ValidateCodeWithOptions(symbology, code, ValidationOptions{CompleteCheckDigits: complete})
+}
+
+// Validates content of the code as ValidateCode does. ISBN is checked
+// as EAN-13 with 978 or 979 prefix, ISBN-10 is converted to it if the
+// options allow it. GS1 element strings are checked against formats of
+// their Application Identifiers. Returns the code to encode and its status.
+func ValidateCodeWithOptions(symbology Symbology, code string, options ValidationOptions) (string, RowStatus) {
+	code = strings.TrimSpace(code)
+	complete := options.CompleteCheckDigits
+	switch symbology {
+	case SymbologyEAN, "":
+		return validateGS1(code, []int{8, 13}, complete)
+	case SymbologyISBN:
+		isbn := cleanISBN(code)
+		if len(isbn) == 10 && options.ConvertISBN10 {
+			converted, err := ISBN10ToISBN13(isbn)
+			if err != nil {
+				if isDigits(strings.TrimSuffix(strings.ToUpper(isbn), "X")) {
+					return code, RowWrongChecksum
+				}
+				return code, RowNonNumeric
+			}
+			return converted, RowConverted
+		}
+		isbn, status := validateGS1(isbn, []int{13}, complete)
+		if status.Printable() && !hasISBNPrefix(isbn) {
+			return code, RowWrongPrefix
+		}
+		if !status.Printable() {
+			return code, status
+		}
+		return isbn, status
+	case SymbologyUPCA:
+		return validateGS1(code, []int{12}, complete)
+	case SymbologyITF14:
+		return validateGS1(code, []int{14}, complete)
+	case SymbologyGS1128, SymbologyGS1DataMatrix:
+		if _, err := symbology.Encode(code); err != nil {
+			return code, RowInvalidGS1
+		}
+		return code, RowValid
+	default:
+		if _, err := symbology.Encode(code); err != nil {
+			return code, RowCannotEncode
+		}
+		return code, RowValid
+	}
+}
+
+// Validates every record and classifies it. Records with the same code
+// as a previous record are reported as duplicates. If complete is set,
+// missing check digits of GS1 numeric codes are computed.
+func ValidateRecords(records []Record, complete bool) ValidationReport {
+	return ValidateRecordsWithOptions(records, ValidationOptions{CompleteCheckDigits: complete})
+}
+
+// Validates every record as ValidateRecords does with the options.
+// Add-ons must have 2 or 5 digits and follow EAN, UPC-A or ISBN code,
+// codes with different add-ons are not duplicates.
+func ValidateRecordsWithOptions(records []Record, options ValidationOptions) ValidationReport {
+	report := ValidationReport{Rows: make([]RowValidation, 0, len(records))}
+	seen := map[string]bool{}
+	for _, record := range records {
+		code, status := ValidateCodeWithOptions(record.Symbology, record.Ean, options)
+		if status.Printable() && record.AddOn != "" && (!validAddOn(record.AddOn) || !record.Symbology.isEanUpc()) {
+			status = RowInvalidAddOn
+		}
+		if status.Printable() {
+			key := string(record.Symbology) + "\x00" + code + "\x00" + record.AddOn
+			if seen[key] {
+				status = RowDuplicate
+			}
+			seen[key] = true
+		}
+		report.Rows = append(report.Rows, RowValidation{
+			Row:    record.Row,
+			Sheet:  record.Sheet,
+			Text:   record.Text,
+			Ean:    record.Ean,
+			Code:   code,
+			Status: status,
+		})
+	}
+	return report
+}
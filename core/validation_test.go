@@ -0,0 +1,169 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		symbology  Symbology
+		code       string
+		complete   bool
+		wantCode   string
+		wantStatus RowStatus
+	}{
+		{name: "Valid EAN-13", symbology: SymbologyEAN, code: "5901234123457", wantCode: "5901234123457", wantStatus: RowValid},
+		{name: "Valid EAN-8", symbology: "", code: "96385074", wantCode: "96385074", wantStatus: RowValid},
+		{name: "Surrounding spaces", symbology: SymbologyEAN, code: " 5901234123457 ", wantCode: "5901234123457", wantStatus: RowValid},
+		{name: "Wrong checksum", symbology: SymbologyEAN, code: "5901234123458", wantCode: "5901234123458", wantStatus: RowWrongChecksum},
+		{name: "Wrong length", symbology: SymbologyEAN, code: "59012341234", wantCode: "59012341234", wantStatus: RowWrongLength},
+		{name: "Missing check digit", symbology: SymbologyEAN, code: "590123412345", wantCode: "590123412345", wantStatus: RowWrongLength},
+		{name: "Complete EAN-13", symbology: SymbologyEAN, code: "590123412345", complete: true, wantCode: "5901234123457", wantStatus: RowCompleted},
+		{name: "Complete EAN-8", symbology: SymbologyEAN, code: "9638507", complete: true, wantCode: "96385074", wantStatus: RowCompleted},
+		{name: "Non numeric", symbology: SymbologyEAN, code: "59012341234A", wantCode: "59012341234A", wantStatus: RowNonNumeric},
+		{name: "Complete UPC-A", symbology: SymbologyUPCA, code: "03600029145", complete: true, wantCode: "036000291452", wantStatus: RowCompleted},
+		{name: "ITF-14 wrong checksum", symbology: SymbologyITF14, code: "15400141288760", wantCode: "15400141288760", wantStatus: RowWrongChecksum},
+		{name: "Code128", symbology: SymbologyCode128, code: "SKU-1", wantCode: "SKU-1", wantStatus: RowValid},
+		{name: "Code39 cannot encode", symbology: SymbologyCode39, code: "a~b", wantCode: "a~b", wantStatus: RowCannotEncode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, status := ValidateCode(tt.symbology, tt.code, tt.complete)
+			if code != tt.wantCode || status != tt.wantStatus {
+				t.Errorf("ValidateCode() = %q, %q, want %q, %q", code, status, tt.wantCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestValidateCodeWithOptions(t *testing.T) {
+	convert := ValidationOptions{ConvertISBN10: true}
+	tests := []struct {
+		name       string
+		code       string
+		options    ValidationOptions
+		wantCode   string
+		wantStatus RowStatus
+	}{
+		{name: "ISBN-13", code: "9780306406157", wantCode: "9780306406157", wantStatus: RowValid},
+		{name: "ISBN-13 with hyphens", code: "978-0-306-40615-7", wantCode: "9780306406157", wantStatus: RowValid},
+		{name: "Wrong prefix", code: "5901234123457", wantCode: "5901234123457", wantStatus: RowWrongPrefix},
+		{name: "ISBN-10 not converted", code: "0-306-40615-2", wantCode: "0-306-40615-2", wantStatus: RowWrongLength},
+		{name: "ISBN-10 converted", code: "0-306-40615-2", options: convert, wantCode: "9780306406157", wantStatus: RowConverted},
+		{name: "ISBN-10 wrong checksum", code: "0306406153", options: convert, wantCode: "0306406153", wantStatus: RowWrongChecksum},
+		{name: "ISBN-10 non numeric", code: "03064A6152", options: convert, wantCode: "03064A6152", wantStatus: RowNonNumeric},
+		{name: "Complete ISBN-13", code: "978030640615", options: ValidationOptions{CompleteCheckDigits: true}, wantCode: "9780306406157", wantStatus: RowCompleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, status := ValidateCodeWithOptions(SymbologyISBN, tt.code, tt.options)
+			if code != tt.wantCode || status != tt.wantStatus {
+				t.Errorf("ValidateCodeWithOptions() = %q, %q, want %q, %q", code, status, tt.wantCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestValidateRecordsWithOptions_AddOn(t *testing.T) {
+	records := []Record{
+		{Ean: "9771234567003", AddOn: "05", Row: 2},
+		{Ean: "9771234567003", AddOn: "06", Row: 3},
+		{Ean: "9771234567003", AddOn: "006", Row: 4},
+		{Ean: "SKU-1", AddOn: "05", Symbology: SymbologyCode128, Row: 5},
+		{Ean: "9771234567003", AddOn: "05", Row: 6},
+	}
+	report := ValidateRecordsWithOptions(records, ValidationOptions{})
+	want := []RowStatus{RowValid, RowValid, RowInvalidAddOn, RowInvalidAddOn, RowDuplicate}
+	for i, status := range want {
+		if report.Rows[i].Status != status {
+			t.Errorf("Rows[%d].Status = %q, want %q", i, report.Rows[i].Status, status)
+		}
+	}
+}
+
+func TestValidateCode_GS1(t *testing.T) {
+	tests := []struct {
+		name       string
+		symbology  Symbology
+		code       string
+		wantStatus RowStatus
+	}{
+		{name: "GS1-128", symbology: SymbologyGS1128, code: "(01)09501101530003(17)251231", wantStatus: RowValid},
+		{name: "GS1 DataMatrix", symbology: SymbologyGS1DataMatrix, code: "(01)09501101530003(10)ABC", wantStatus: RowValid},
+		{name: "Invalid date", symbology: SymbologyGS1128, code: "(01)09501101530003(17)253112", wantStatus: RowInvalidGS1},
+		{name: "Wrong GTIN", symbology: SymbologyGS1DataMatrix, code: "(01)09501101530004", wantStatus: RowInvalidGS1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, status := ValidateCode(tt.symbology, tt.code, false); status != tt.wantStatus {
+				t.Errorf("ValidateCode() status = %q, want %q", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestValidateRecords(t *testing.T) {
+	records := []Record{
+		{Text: "A", Ean: "5901234123457", Row: 2},
+		{Text: "B", Ean: "5901234123458", Row: 3},
+		{Text: "C", Ean: "5901234123457", Row: 4},
+		{Text: "D", Ean: "5901234123457", Row: 5, Symbology: SymbologyCode128},
+		{Text: "E", Ean: "59012341234", Row: 6},
+	}
+	report := ValidateRecords(records, false)
+	want := []RowStatus{RowValid, RowWrongChecksum, RowDuplicate, RowValid, RowWrongLength}
+	if len(report.Rows) != len(want) {
+		t.Fatalf("len(Rows) = %d, want %d", len(report.Rows), len(want))
+	}
+	for i, status := range want {
+		if report.Rows[i].Status != status {
+			t.Errorf("Rows[%d].Status = %q, want %q", i, report.Rows[i].Status, status)
+		}
+		if report.Rows[i].Row != records[i].Row {
+			t.Errorf("Rows[%d].Row = %d, want %d", i, report.Rows[i].Row, records[i].Row)
+		}
+	}
+	if !report.HasErrors() {
+		t.Error("HasErrors() = false, want true")
+	}
+	if len(report.Problems()) != 3 {
+		t.Errorf("len(Problems()) = %d, want 3", len(report.Problems()))
+	}
+	summary := report.Summary()
+	for _, part := range []string{"5 rows", "2 valid", "1 duplicate", "1 wrong checksum", "1 wrong length"} {
+		if !strings.Contains(summary, part) {
+			t.Errorf("Summary() = %q does not contain %q", summary, part)
+		}
+	}
+}
+
+func TestValidationReport_NoErrors(t *testing.T) {
+	report := ValidateRecords([]Record{{Ean: "5901234123457"}, {Ean: "5901234123457"}}, false)
+	if report.HasErrors() {
+		t.Error("HasErrors() = true, duplicates should be printable")
+	}
+	if ValidateRecords(nil, false).Summary() != "0 rows" {
+		t.Error("Summary() of empty report should be '0 rows'")
+	}
+}
+
+func TestRowValidation_String(t *testing.T) {
+	tests := []struct {
+		row  RowValidation
+		want string
+	}{
+		{row: RowValidation{Row: 3, Ean: "123", Status: RowWrongLength}, want: `Row 3: "123" wrong length`},
+		{row: RowValidation{Ean: "123", Status: RowWrongLength}, want: `Row ?: "123" wrong length`},
+		{
+			row:  RowValidation{Row: 2, Ean: "590123412345", Code: "5901234123457", Status: RowCompleted},
+			want: `Row 2: "590123412345" check digit completed to 5901234123457`,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.row.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,475 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// Types of BIFF8 records read from legacy Excel workbooks.
+const (
+	xlsBOF        = 0x0809
+	xlsEOF        = 0x000A
+	xlsFilePass   = 0x002F
+	xlsBoundSheet = 0x0085
+	xlsSST        = 0x00FC
+	xlsContinue   = 0x003C
+	xlsLabelSST   = 0x00FD
+	xlsLabel      = 0x0204
+	xlsNumber     = 0x0203
+	xlsRK         = 0x027E
+	xlsMulRK      = 0x00BD
+	xlsFormula    = 0x0006
+	xlsString     = 0x0207
+	xlsBoolErr    = 0x0205
+)
+
+// BIFF version of Excel 97-2003 workbooks.
+const xlsBiff8 = 0x0600
+
+var errXlsTruncated = errors.New("Cannot read xls file, record is truncated")
+
+// Worksheet of the legacy Excel workbook.
+type xlsSheet struct {
+	name string
+	// Offset of the BOF record of the sheet in the workbook stream.
+	offset int
+}
+
+// Workbook stream of the legacy Excel workbook with its sheets and shared strings.
+type xlsWorkbook struct {
+	stream  []byte
+	sheets  []xlsSheet
+	strings []string
+}
+
+// Returns names of all worksheets of the legacy Excel workbook in their order.
+func XlsSheetNames(r io.Reader) ([]string, error) {
+	workbook, err := readXls(r)
+	if err != nil {
+		return nil, err
+	}
+	return workbook.names(), nil
+}
+
+// Reads legacy Excel 97-2003 (.xls) data from an io.Reader and returns
+// the selected sheet, or all sheets in workbook order if merge is set.
+// Sheet is selected by name or one based position as in FindSheet.
+// Cells are read without number formats, numbers use their raw value
+// so long codes are not shown in scientific notation.
+func SheetTablesFromXls(r io.Reader, sheet string, merge bool) ([]SheetTable, error) {
+	workbook, err := readXls(r)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := selectSheets(workbook.names(), sheet, merge)
+	if err != nil {
+		return nil, err
+	}
+	ret := []SheetTable{}
+	for _, s := range workbook.sheets {
+		for _, name := range selected {
+			if s.name != name {
+				continue
+			}
+			table, err := workbook.table(s)
+			if err != nil {
+				return nil, fmt.Errorf("Sheet '%s': %w", s.name, err)
+			}
+			ret = append(ret, SheetTable{Sheet: s.name, Table: table})
+		}
+	}
+	return ret, nil
+}
+
+// Reads the workbook stream of the compound file and its global records.
+func readXls(r io.Reader) (*xlsWorkbook, error) {
+	if r == nil {
+		return nil, errors.New("Reader is <nil>")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := mscfb.New(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Join(errors.New("Cannot open xls file"), err)
+	}
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		switch entry.Name {
+		case "Workbook":
+			stream, err := io.ReadAll(entry)
+			if err != nil {
+				return nil, errors.Join(errors.New("Cannot read xls workbook"), err)
+			}
+			return parseXlsGlobals(stream)
+		case "Book":
+			return nil, errors.New("Excel 95 and older .xls files are not supported, save the file as .xlsx or .ods")
+		}
+	}
+	return nil, errors.New("Cannot open xls file, workbook stream is missing")
+}
+
+// Returns names of the worksheets.
+func (w *xlsWorkbook) names() []string {
+	names := []string{}
+	for _, s := range w.sheets {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// Returns the record at the offset of the stream and the offset of the next record.
+func xlsRecord(stream []byte, offset int) (uint16, []byte, int, error) {
+	if offset+4 > len(stream) {
+		return 0, nil, 0, errXlsTruncated
+	}
+	kind := binary.LittleEndian.Uint16(stream[offset:])
+	size := int(binary.LittleEndian.Uint16(stream[offset+2:]))
+	end := offset + 4 + size
+	if end > len(stream) {
+		return 0, nil, 0, errXlsTruncated
+	}
+	return kind, stream[offset+4 : end], end, nil
+}
+
+// Checks that the record is BOF of BIFF8 substream.
+func checkXlsBOF(kind uint16, data []byte) error {
+	if kind != xlsBOF || len(data) < 4 {
+		return errors.New("Cannot read xls file, substream does not start by BOF record")
+	}
+	if binary.LittleEndian.Uint16(data) != xlsBiff8 {
+		return errors.New("Only Excel 97-2003 .xls files are supported, save the file as .xlsx or .ods")
+	}
+	return nil
+}
+
+// Parses the workbook globals substream: worksheets and shared strings.
+func parseXlsGlobals(stream []byte) (*xlsWorkbook, error) {
+	workbook := &xlsWorkbook{stream: stream}
+	kind, data, offset, err := xlsRecord(stream, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkXlsBOF(kind, data); err != nil {
+		return nil, err
+	}
+	for kind != xlsEOF {
+		kind, data, offset, err = xlsRecord(stream, offset)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case xlsFilePass:
+			return nil, errors.New("Encrypted .xls files are not supported")
+		case xlsBoundSheet:
+			if len(data) < 8 {
+				return nil, errXlsTruncated
+			}
+			// Other sheet types are charts and macros.
+			if data[5] != 0 {
+				continue
+			}
+			name, _, err := xlsChars(data[8:], int(data[6]), data[7]&1 != 0)
+			if err != nil {
+				return nil, err
+			}
+			workbook.sheets = append(workbook.sheets, xlsSheet{name: name, offset: int(binary.LittleEndian.Uint32(data))})
+		case xlsSST:
+			// Strings continue in the following CONTINUE records.
+			segments := [][]byte{data}
+			for {
+				next, continued, end, err := xlsRecord(stream, offset)
+				if err != nil || next != xlsContinue {
+					break
+				}
+				segments = append(segments, continued)
+				offset = end
+			}
+			workbook.strings, err = parseXlsSST(segments)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return workbook, nil
+}
+
+// Returns n characters of the string data, one byte per character unless
+// high is set, and the number of bytes read.
+func xlsChars(data []byte, n int, high bool) (string, int, error) {
+	if !high {
+		if len(data) < n {
+			return "", 0, errXlsTruncated
+		}
+		// Compressed characters are the low bytes of UTF-16, i.e. Latin-1.
+		runes := make([]rune, n)
+		for i := range n {
+			runes[i] = rune(data[i])
+		}
+		return string(runes), n, nil
+	}
+	if len(data) < 2*n {
+		return "", 0, errXlsTruncated
+	}
+	units := make([]uint16, n)
+	for i := range n {
+		units[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units)), 2 * n, nil
+}
+
+// Data of the record split to CONTINUE records.
+type xlsContinued struct {
+	segments [][]byte
+	segment  int
+	offset   int
+}
+
+// Returns next n bytes, reading over ends of segments.
+func (c *xlsContinued) bytes(n int) ([]byte, error) {
+	ret := make([]byte, 0, n)
+	for len(ret) < n {
+		if c.segment >= len(c.segments) {
+			return nil, errXlsTruncated
+		}
+		data := c.segments[c.segment][c.offset:]
+		take := min(n-len(ret), len(data))
+		ret = append(ret, data[:take]...)
+		c.offset += take
+		if c.offset == len(c.segments[c.segment]) {
+			c.segment++
+			c.offset = 0
+		}
+	}
+	return ret, nil
+}
+
+// Reads the unicode string with optional rich text and phonetic data.
+// Characters split to the next segment are preceded by new option flags.
+func (c *xlsContinued) string() (string, error) {
+	header, err := c.bytes(3)
+	if err != nil {
+		return "", err
+	}
+	count := int(binary.LittleEndian.Uint16(header))
+	flags := header[2]
+	skip := 0
+	if flags&0x08 != 0 {
+		runs, err := c.bytes(2)
+		if err != nil {
+			return "", err
+		}
+		skip += 4 * int(binary.LittleEndian.Uint16(runs))
+	}
+	if flags&0x04 != 0 {
+		size, err := c.bytes(4)
+		if err != nil {
+			return "", err
+		}
+		skip += int(binary.LittleEndian.Uint32(size))
+	}
+	high := flags&1 != 0
+	var b strings.Builder
+	for count > 0 {
+		if c.segment >= len(c.segments) {
+			return "", errXlsTruncated
+		}
+		if c.offset == 0 && c.segment > 0 {
+			// Characters continue in the next record after its option flags.
+			high = c.segments[c.segment][0]&1 != 0
+			c.offset = 1
+		}
+		data := c.segments[c.segment][c.offset:]
+		size := 1
+		if high {
+			size = 2
+		}
+		n := min(count, len(data)/size)
+		if n == 0 {
+			return "", errXlsTruncated
+		}
+		text, read, err := xlsChars(data, n, high)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(text)
+		count -= n
+		if _, err := c.bytes(read); err != nil {
+			return "", err
+		}
+	}
+	if _, err := c.bytes(skip); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Parses the shared string table from the SST record and its CONTINUE records.
+func parseXlsSST(segments [][]byte) ([]string, error) {
+	c := &xlsContinued{segments: segments}
+	header, err := c.bytes(8)
+	if err != nil {
+		return nil, err
+	}
+	unique := int(binary.LittleEndian.Uint32(header[4:]))
+	ret := make([]string, 0, min(unique, len(segments[0])))
+	for range unique {
+		s, err := c.string()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, s)
+	}
+	return ret, nil
+}
+
+// Returns the cell values of the worksheet as table. Rows end by their
+// last value, empty rows between values are kept.
+func (w *xlsWorkbook) table(sheet xlsSheet) (Table, error) {
+	kind, data, offset, err := xlsRecord(w.stream, sheet.offset)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkXlsBOF(kind, data); err != nil {
+		return nil, err
+	}
+	table := Table{}
+	set := func(row int, col int, value string) {
+		if value == "" {
+			return
+		}
+		for len(table) <= row {
+			table = append(table, []string{})
+		}
+		for len(table[row]) <= col {
+			table[row] = append(table[row], "")
+		}
+		table[row][col] = value
+	}
+	// Cell of the formula whose string result is in the following STRING record.
+	formula := [2]int{-1, -1}
+	for kind != xlsEOF {
+		kind, data, offset, err = xlsRecord(w.stream, offset)
+		if err != nil {
+			return nil, err
+		}
+		if kind == xlsString && formula[0] >= 0 {
+			if len(data) < 3 {
+				return nil, errXlsTruncated
+			}
+			value, _, err := xlsChars(data[3:], int(binary.LittleEndian.Uint16(data)), data[2]&1 != 0)
+			if err != nil {
+				return nil, err
+			}
+			set(formula[0], formula[1], value)
+			formula = [2]int{-1, -1}
+			continue
+		}
+		if !isXlsCell(kind) {
+			continue
+		}
+		if len(data) < 6 {
+			return nil, errXlsTruncated
+		}
+		row := int(binary.LittleEndian.Uint16(data))
+		col := int(binary.LittleEndian.Uint16(data[2:]))
+		switch kind {
+		case xlsLabelSST:
+			if len(data) < 10 {
+				return nil, errXlsTruncated
+			}
+			index := int(binary.LittleEndian.Uint32(data[6:]))
+			if index >= len(w.strings) {
+				return nil, fmt.Errorf("Cannot read xls file, shared string %d does not exist", index)
+			}
+			set(row, col, w.strings[index])
+		case xlsLabel:
+			if len(data) < 9 {
+				return nil, errXlsTruncated
+			}
+			value, _, err := xlsChars(data[9:], int(binary.LittleEndian.Uint16(data[6:])), data[8]&1 != 0)
+			if err != nil {
+				return nil, err
+			}
+			set(row, col, value)
+		case xlsNumber:
+			if len(data) < 14 {
+				return nil, errXlsTruncated
+			}
+			set(row, col, formatXlsNumber(math.Float64frombits(binary.LittleEndian.Uint64(data[6:]))))
+		case xlsRK:
+			if len(data) < 10 {
+				return nil, errXlsTruncated
+			}
+			set(row, col, formatXlsNumber(xlsRKValue(binary.LittleEndian.Uint32(data[6:]))))
+		case xlsMulRK:
+			// Values of following columns are 6 bytes each, the last column ends the record.
+			for i := 4; i+6 <= len(data)-2; i += 6 {
+				set(row, col, formatXlsNumber(xlsRKValue(binary.LittleEndian.Uint32(data[i+2:]))))
+				col++
+			}
+		case xlsBoolErr:
+			if len(data) < 8 {
+				return nil, errXlsTruncated
+			}
+			// Errors are read as empty cells.
+			if data[7] == 0 {
+				set(row, col, strings.ToUpper(strconv.FormatBool(data[6] != 0)))
+			}
+		case xlsFormula:
+			if len(data) < 14 {
+				return nil, errXlsTruncated
+			}
+			result := data[6:14]
+			if result[6] != 0xFF || result[7] != 0xFF {
+				set(row, col, formatXlsNumber(math.Float64frombits(binary.LittleEndian.Uint64(result))))
+				continue
+			}
+			switch result[0] {
+			case 0:
+				formula = [2]int{row, col}
+			case 1:
+				set(row, col, strings.ToUpper(strconv.FormatBool(result[2] != 0)))
+			}
+		}
+	}
+	return table, nil
+}
+
+// Reports whether the record holds the value of a cell.
+func isXlsCell(kind uint16) bool {
+	switch kind {
+	case xlsLabelSST, xlsLabel, xlsNumber, xlsRK, xlsMulRK, xlsBoolErr, xlsFormula:
+		return true
+	}
+	return false
+}
+
+// Returns the number encoded as RK value: 30 bit integer or the high
+// bits of float, optionally multiplied by 100.
+func xlsRKValue(rk uint32) float64 {
+	var value float64
+	if rk&0x02 != 0 {
+		value = float64(int32(rk) >> 2)
+	} else {
+		value = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		value /= 100
+	}
+	return value
+}
+
+// Formats the number without exponent, e.g. EAN codes stored as numbers.
+func formatXlsNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
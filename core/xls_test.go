@@ -0,0 +1,225 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// Returns the BIFF8 record of the type with the data.
+func xlsTestRecord(kind uint16, data ...[]byte) []byte {
+	body := bytes.Join(data, nil)
+	record := binary.LittleEndian.AppendUint16(nil, kind)
+	record = binary.LittleEndian.AppendUint16(record, uint16(len(body)))
+	return append(record, body...)
+}
+
+func le16(v int) []byte {
+	return binary.LittleEndian.AppendUint16(nil, uint16(v))
+}
+
+func le32(v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(nil, v)
+}
+
+// Returns the characters of the string as UTF-16.
+func utf16le(s string) []byte {
+	ret := []byte{}
+	for _, unit := range utf16.Encode([]rune(s)) {
+		ret = binary.LittleEndian.AppendUint16(ret, unit)
+	}
+	return ret
+}
+
+// Returns the cell header of the row and column with the default format.
+func xlsCell(row int, col int) []byte {
+	return append(append(le16(row), le16(col)...), le16(15)...)
+}
+
+// Returns BIFF8 workbook stream with the sheets given by their cell records.
+// Globals have the shared string table split to a CONTINUE record in the middle
+// of the third string, which switches from UTF-16 to compressed characters.
+func xlsTestStream(names []string, sheets [][]byte) []byte {
+	bof := func(kind int) []byte {
+		return xlsTestRecord(xlsBOF, le16(xlsBiff8), le16(kind), make([]byte, 12))
+	}
+	sst := xlsTestRecord(xlsSST, le32(3), le32(3),
+		le16(4), []byte{0}, []byte("Name"),
+		le16(3), []byte{0}, []byte("EAN"),
+		le16(10), []byte{1}, utf16le("Čaj"))
+	continued := xlsTestRecord(xlsContinue, []byte{0}, []byte(" zelen\xfd"))
+
+	boundSheets := func(offsets []int) []byte {
+		ret := []byte{}
+		for i, name := range names {
+			ret = append(ret, xlsTestRecord(xlsBoundSheet, le32(uint32(offsets[i])), []byte{0, 0, byte(len(name)), 0}, []byte(name))...)
+		}
+		// Chart sheets are not worksheets.
+		return append(ret, xlsTestRecord(xlsBoundSheet, le32(0), []byte{0, 2, 5, 0}, []byte("Chart"))...)
+	}
+	globals := func(offsets []int) []byte {
+		return bytes.Join([][]byte{bof(0x0005), boundSheets(offsets), sst, continued, xlsTestRecord(xlsEOF)}, nil)
+	}
+	offsets := make([]int, len(sheets))
+	offset := len(globals(offsets))
+	body := []byte{}
+	for i, cells := range sheets {
+		offsets[i] = offset + len(body)
+		body = append(body, bof(0x0010)...)
+		body = append(body, cells...)
+		body = append(body, xlsTestRecord(xlsEOF)...)
+	}
+	return append(globals(offsets), body...)
+}
+
+// Returns compound file with the stream of the name, as legacy Office files are.
+func xlsFile(t *testing.T, name string, stream []byte) *bytes.Reader {
+	t.Helper()
+	const sector = 512
+	// Streams smaller than the cutoff would be stored in the mini stream.
+	size := max(4096, (len(stream)+sector-1)/sector*sector)
+	stream = append(stream, make([]byte, size-len(stream))...)
+	sectors := size / sector
+	if sectors > sector/4-2 {
+		t.Fatal("Test stream does not fit to one FAT sector")
+	}
+
+	const endOfChain, freeSector, noStream = 0xFFFFFFFE, 0xFFFFFFFF, 0xFFFFFFFF
+	header := make([]byte, sector)
+	copy(header, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	for offset, value := range map[int]uint16{24: 0x3E, 26: 3, 28: 0xFFFE, 30: 9, 32: 6} {
+		binary.LittleEndian.PutUint16(header[offset:], value)
+	}
+	for offset, value := range map[int]uint32{44: 1, 48: 1, 56: 4096, 60: endOfChain, 68: endOfChain, 76: 0} {
+		binary.LittleEndian.PutUint32(header[offset:], value)
+	}
+	for i := 80; i < sector; i += 4 {
+		binary.LittleEndian.PutUint32(header[i:], freeSector)
+	}
+
+	// FAT in sector 0, directory in sector 1 and the stream in the rest.
+	fat := make([]byte, sector)
+	for i := range sector / 4 {
+		next := uint32(freeSector)
+		switch {
+		case i == 0:
+			next = 0xFFFFFFFD
+		case i == 1 || i == sectors+1:
+			next = endOfChain
+		case i <= sectors:
+			next = uint32(i + 1)
+		}
+		binary.LittleEndian.PutUint32(fat[4*i:], next)
+	}
+
+	directory := make([]byte, sector)
+	entry := func(i int, name string, kind byte, child uint32, start uint32, size int) {
+		e := directory[128*i:]
+		copy(e, utf16le(name))
+		binary.LittleEndian.PutUint16(e[64:], uint16(2*len(name)+2))
+		e[66], e[67] = kind, 1
+		binary.LittleEndian.PutUint32(e[68:], noStream)
+		binary.LittleEndian.PutUint32(e[72:], noStream)
+		binary.LittleEndian.PutUint32(e[76:], child)
+		binary.LittleEndian.PutUint32(e[116:], start)
+		binary.LittleEndian.PutUint32(e[120:], uint32(size))
+	}
+	entry(0, "Root Entry", 5, 1, endOfChain, 0)
+	entry(1, name, 2, noStream, 2, size)
+	for i := 2; i < 4; i++ {
+		entry(i, "", 0, noStream, 0, 0)
+	}
+	return bytes.NewReader(bytes.Join([][]byte{header, fat, directory, stream}, nil))
+}
+
+// Workbook with sheets "Products" and "Empty" using all supported cell records.
+func xlsTwoSheets(t *testing.T) *bytes.Reader {
+	number := make([]byte, 8)
+	binary.LittleEndian.PutUint64(number, math.Float64bits(5901234123457))
+	formula := append(xlsCell(3, 0), 0, 0, 0, 0, 0, 0, 0xFF, 0xFF, 0, 0, 0, 0, 0, 0)
+	products := bytes.Join([][]byte{
+		xlsTestRecord(xlsLabelSST, xlsCell(0, 0), le32(0)),
+		xlsTestRecord(xlsLabelSST, xlsCell(0, 1), le32(1)),
+		xlsTestRecord(xlsLabel, xlsCell(0, 2), le16(5), []byte{0}, []byte("Times")),
+		xlsTestRecord(xlsLabelSST, xlsCell(1, 0), le32(2)),
+		xlsTestRecord(xlsNumber, xlsCell(1, 1), number),
+		// Integer 2 as RK value.
+		xlsTestRecord(xlsRK, xlsCell(1, 2), le32(2<<2|0x02)),
+		xlsTestRecord(xlsLabel, xlsCell(2, 0), le16(4), []byte{1}, utf16le("Mléko")[:8]),
+		// Integers 40063813 and 150 / 100 in columns B and C.
+		xlsTestRecord(xlsMulRK, le16(2), le16(1), le16(15), le32(40063813<<2|0x02), le16(15), le32(150<<2|0x03), le16(2)),
+		xlsTestRecord(xlsFormula, formula),
+		xlsTestRecord(xlsString, le16(5), []byte{0}, []byte("Bread")),
+		xlsTestRecord(xlsBoolErr, xlsCell(3, 1), []byte{1, 0}),
+		// Error value is an empty cell.
+		xlsTestRecord(xlsBoolErr, xlsCell(3, 2), []byte{0x07, 1}),
+	}, nil)
+	return xlsFile(t, "Workbook", xlsTestStream([]string{"Products", "Empty"}, [][]byte{products, nil}))
+}
+
+func TestSheetTablesFromXls(t *testing.T) {
+	tables, err := SheetTablesFromXls(xlsTwoSheets(t), "", true)
+	if err != nil {
+		t.Fatalf("SheetTablesFromXls() failed: %v", err)
+	}
+	if len(tables) != 2 || tables[0].Sheet != "Products" || tables[1].Sheet != "Empty" || len(tables[1].Table) != 0 {
+		t.Fatalf("SheetTablesFromXls() = %q, want sheets Products and Empty", tables)
+	}
+	want := Table{
+		{"Name", "EAN", "Times"},
+		{"Čaj zelený", "5901234123457", "2"},
+		{"Mlék", "40063813", "1.5"},
+		{"Bread", "TRUE"},
+	}
+	got := tables[0].Table
+	if len(got) != len(want) {
+		t.Fatalf("SheetTablesFromXls() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if strings.Join(got[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	tables, err = SheetTablesFromXls(xlsTwoSheets(t), "2", false)
+	if err != nil || len(tables) != 1 || tables[0].Sheet != "Empty" {
+		t.Errorf("SheetTablesFromXls() = %q, %v, want the second sheet", tables, err)
+	}
+	if _, err := SheetTablesFromXls(xlsTwoSheets(t), "Chart", false); err == nil {
+		t.Error("SheetTablesFromXls() should fail for chart sheet")
+	}
+}
+
+func TestSheetTablesFromXls_Errors(t *testing.T) {
+	biff5 := xlsTestRecord(xlsBOF, le16(0x0500), le16(0x0005), make([]byte, 4))
+	encrypted := append(xlsTestRecord(xlsBOF, le16(xlsBiff8), le16(0x0005), make([]byte, 12)), xlsTestRecord(xlsFilePass, make([]byte, 6))...)
+	tests := []struct {
+		name    string
+		content *bytes.Reader
+		want    string
+	}{
+		{name: "not compound file", content: bytes.NewReader([]byte("Name,EAN\n")), want: "Cannot open xls file"},
+		{name: "Excel 95 stream", content: xlsFile(t, "Book", biff5), want: "Excel 95"},
+		{name: "BIFF5 workbook", content: xlsFile(t, "Workbook", biff5), want: "Only Excel 97-2003"},
+		{name: "encrypted", content: xlsFile(t, "Workbook", encrypted), want: "Encrypted"},
+		{name: "no workbook", content: xlsFile(t, "Document", biff5), want: "workbook stream is missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SheetTablesFromXls(tt.content, "", false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("SheetTablesFromXls() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestXlsSheetNames(t *testing.T) {
+	names, err := XlsSheetNames(xlsTwoSheets(t))
+	if err != nil || strings.Join(names, ",") != "Products,Empty" {
+		t.Errorf("XlsSheetNames() = %v, %v, want [Products Empty]", names, err)
+	}
+}
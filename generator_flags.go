@@ -0,0 +1,282 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/Fanteria/EANBaker/app"
+	"github.com/Fanteria/EANBaker/core"
+)
+
+// Flags of the generator shared by generate, validate and preview commands.
+// Values that need parsing are kept as strings until Generator is called.
+type generatorFlags struct {
+	generator   core.Generator
+	comma       string
+	encoding    string
+	symbology   string
+	gs1Columns  string
+	serialState string
+	layoutPath  string
+	renderer    string
+	labelSize   string
+	labelSheet  string
+	errorPolicy string
+	inputFormat string
+	overwrite   string
+	profile     string
+	flags       *flag.FlagSet
+	// Project configuration file, see core.ConfigLayers.
+	projectPath string
+}
+
+// Defines the generator flags in the flag set, defaults are the ones of core.DefaultGenerator.
+func newGeneratorFlags(flags *flag.FlagSet) *generatorFlags {
+	return newGeneratorFlagsFrom(flags, core.DefaultGenerator())
+}
+
+// Defines the generator flags in the flag set with defaults of the generator.
+// Values without a flag, e.g. GS1 columns, are kept.
+func newGeneratorFlagsFrom(flags *flag.FlagSet, defaults core.Generator) *generatorFlags {
+	f := &generatorFlags{generator: defaults, flags: flags, projectPath: configPath}
+	generator := &f.generator
+	comma := ""
+	if defaults.CsvComma != 0 {
+		comma = string(defaults.CsvComma)
+	}
+	flags.StringVar(&f.profile, "profile", "", "Name of the configuration profile used as defaults of other flags, see \"eanbaker profile\". Values of the profile override configuration files.")
+	flags.StringVar(&generator.CsvPath, "csv", defaults.CsvPath, `Path to the input data in CSV, TSV, XLSX, XLS or ODS, "-" reads the standard input.`)
+	flags.StringVar(&f.inputFormat, "input-format", string(defaults.InputFormat), "Format of the input, one of: csv, tsv, xlsx, xls, ods. Detected from the file extension or the content if not set.")
+	flags.StringVar(&generator.PdfPath, "pdf", defaults.PdfPath, `Path to the generated pdf file, "-" writes to the standard output. If is not set, CSV file path with suffix changed to pdf is used.
+Path can contain placeholders {input}, {dir}, {profile} and {date} (e.g. "out/{input}_{date:2006-01-02}_{profile}.pdf"), PDF of a directory is named by the input.`)
+	flags.StringVar(&f.overwrite, "overwrite", string(defaults.OverwritePolicy), `What to do if the PDF already exists: "overwrite" it, "fail" or "increment" adds the first free number to the name (e.g. "data-1.pdf").`)
+	flags.StringVar(&generator.TextHeader, "text-header", defaults.TextHeader, `Header of column that will be used as text. Headers are matched regardless of case, spaces and diacritics.
+Column can be also given by letters (e.g. "C") or one based position (e.g. "#3"), the same applies to all header flags.`)
+	flags.StringVar(&generator.EanHeader, "ean-header", defaults.EanHeader, "Header of column containing ean codes that will be used to generate barcode.")
+	flags.IntVar(&generator.HeaderSearchRows, "header-search-rows", defaults.HeaderSearchRows, "Number of first rows searched for the header row, rows above the header are skipped.")
+	flags.StringVar(&generator.TimesHeader, "times-header", defaults.TimesHeader, `Name of the column that specifies how many times each EAN code should be generated. If the column is empty, each EAN code is generated only once.
+If the column contains a number, the EAN code is generated that many times. Rows are processed line by line, so identical EANs appear consecutively.`)
+	flags.UintVar(&generator.TimesEachEAN, "times-each-ean", defaults.TimesEachEAN, "Number of times each EAN code will be printed in the output PDF.")
+	flags.StringVar(&generator.SymbologyHeader, "symbology-header", defaults.SymbologyHeader, "Case insensitive header of column with per-row symbology. Rows with empty value use -symbology.")
+	flags.StringVar(&f.comma, "csv-separator", comma, "CSV file column separator. Detected from the content if not set.")
+	flags.StringVar(&f.encoding, "csv-encoding", string(defaults.CsvEncoding), "CSV file encoding, one of: utf-8, utf-16le, utf-16be, windows-1250, iso-8859-2. Detected from the content if not set.")
+	flags.StringVar(&f.symbology, "symbology", string(defaults.Symbology), "Barcode symbology, one of: ean, upca, isbn, code128, code39, itf14, datamatrix, qr, gs1-128, gs1-datamatrix.")
+	flags.StringVar(&f.gs1Columns, "gs1-ai", "", `Columns of GS1 Application Identifiers for gs1-128 and gs1-datamatrix, e.g. "01=GTIN,17=Expiry,10=Batch,21=Serial".
+Supported AIs are `+strings.Join(core.GS1AIs(), ", ")+`. EAN column is used as GTIN (01) unless it is mapped.`)
+	flags.StringVar(&generator.AddOnHeader, "add-on-header", defaults.AddOnHeader, `Header of column with EAN-2 or EAN-5 add-ons. Add-on can be also written after the code, e.g. "9771234567003 05".`)
+	flags.BoolVar(&generator.ConvertISBN10, "convert-isbn10", defaults.ConvertISBN10, "Convert ISBN-10 codes of the isbn symbology to ISBN-13.")
+	flags.StringVar(&generator.Templates.Top, "template-top", defaults.Templates.Top, `Go text/template of the top text, columns are available by their headers (e.g. "{{.Name}} / {{.Size}}"). Text column is printed if not set.`)
+	flags.StringVar(&generator.Templates.Bottom, "template-bottom", defaults.Templates.Bottom, "Go text/template of the bottom text. Barcode content is printed if not set.")
+	flags.StringVar(&generator.Templates.Side, "template-side", defaults.Templates.Side, "Go text/template of the side text, the layout must have a side box.")
+	flags.StringVar(&generator.Templates.Barcode, "template-barcode", defaults.Templates.Barcode, `Go text/template of the barcode content (e.g. "{{.Ean}}-{{.Serial}}"). Code column is encoded if not set.`)
+	flags.BoolVar(&generator.Serial.Enabled, "serial", defaults.Serial.Enabled, "Give every printed label a unique serial number, available to templates as {{.Serial}}.")
+	flags.Int64Var(&generator.Serial.Start, "serial-start", defaults.Serial.Start, "Serial number of the first label when no number was issued yet.")
+	flags.Int64Var(&generator.Serial.Step, "serial-step", defaults.Serial.Step, "Difference between following serial numbers.")
+	flags.IntVar(&generator.Serial.Padding, "serial-padding", defaults.Serial.Padding, "Minimal number of serial number digits, shorter numbers are padded by zeros.")
+	flags.StringVar(&generator.Serial.Prefix, "serial-prefix", defaults.Serial.Prefix, "Text printed before serial numbers.")
+	flags.StringVar(&generator.Serial.Suffix, "serial-suffix", defaults.Serial.Suffix, "Text printed after serial numbers.")
+	flags.StringVar(&f.serialState, "serial-state", "", "Generator JSON file (e.g. .EANBaker.json) the last issued serial number is read from and written to, so the next run continues the sequence. Profile given by -profile or the project configuration file is used if not set.")
+	flags.StringVar(&generator.PriceHeader, "price-header", defaults.PriceHeader, "Header of optional column with prices, drawn to the price box of the layout.")
+	flags.StringVar(&generator.QuantityHeader, "quantity-header", defaults.QuantityHeader, `Header of optional column with quantities (e.g. "500 g", "0,75 l") used to print unit price per kg or l.`)
+	flags.StringVar(&generator.PriceFormat.Locale, "price-locale", defaults.PriceFormat.Locale, "Locale of price formatting, one of: "+strings.Join(core.PriceLocaleNames(), ", ")+". Default is en-us.")
+	flags.StringVar(&generator.PriceFormat.Currency, "currency", defaults.PriceFormat.Currency, "Currency symbol printed with prices. Locale currency is used if not set.")
+	flags.StringVar(&generator.PriceFormat.SymbolPosition, "currency-position", defaults.PriceFormat.SymbolPosition, `Currency symbol position, "before" or "after" the amount. Locale default is used if not set.`)
+	flags.StringVar(&generator.PriceFormat.QuantityUnit, "quantity-unit", defaults.PriceFormat.QuantityUnit, `Unit of quantities given only by a number, e.g. "g" or "ml".`)
+	flags.StringVar(&f.layoutPath, "layout", "", "Path to JSON file with label layout (page size, margins, boxes and font size in mm).")
+	flags.Float64Var(&generator.ModuleWidth, "module-width", defaults.ModuleWidth, "Width of the narrowest bar in mm (e.g. 0.33 for EAN at 100 %). Barcode is stretched to the barcode box if not set.")
+	flags.StringVar(&f.renderer, "barcode-renderer", string(defaults.BarcodeRenderer), `How barcodes are drawn: "vector" draws bars as PDF rectangles, "png" embeds scaled images.`)
+	flags.BoolVar(&generator.GS1Rendering, "gs1", defaults.GS1Rendering, "Draw EAN and UPC codes as GS1 symbols with light margins, extended guard bars and digits under the bars.")
+	flags.Float64Var(&generator.Magnification, "magnification", defaults.Magnification, "Magnification of GS1 symbols between 0.8 and 2.0, 1 is 0.33 mm module. Module width is used if not set.")
+	flags.StringVar(&f.labelSize, "label-size", "", `Label size in mm in format WIDTHxHEIGHT (e.g. "50x30"), default layout is scaled to it. Ignored if -layout is set.`)
+	flags.StringVar(&f.labelSheet, "label-sheet", "", "Tile labels on sheets instead of one label per page. Name of built-in preset ("+strings.Join(core.LabelSheetPresetNames(), ", ")+") or path to JSON file.")
+	flags.IntVar(&generator.StartPosition, "start-position", defaults.StartPosition, "One based position of the first label on the first sheet, allows to reuse partially used sheets.")
+	flags.BoolVar(&generator.CompleteCheckDigits, "complete-check-digits", defaults.CompleteCheckDigits, "Compute missing check digit of EAN-8, EAN-13, UPC-A and ITF-14 codes given without it.")
+	flags.StringVar(&f.errorPolicy, "error-policy", string(defaults.ErrorPolicy), `What to do with rows whose barcode cannot be generated: "fail-fast" aborts, "skip-invalid" leaves them out, "placeholder" prints label marked as INVALID.`)
+	flags.StringVar(&generator.Sheet, "sheet", defaults.Sheet, "Excel sheet to read given by name or one based position. The first sheet is used if not set.")
+	flags.BoolVar(&generator.MergeSheets, "merge-sheets", defaults.MergeSheets, "Read records from all Excel sheets, each sheet must have the configured headers.")
+	flags.StringVar(&generator.SkippedReportPath, "skipped-report", defaults.SkippedReportPath, "Path to CSV report of rows whose barcode cannot be generated.")
+	flags.StringVar(&generator.ReportPath, "report", defaults.ReportPath, "Path to CSV or XLSX report of every label with its page, source row, code, copy number and status (printed, skipped or invalid).")
+	return f
+}
+
+// Returns the generator configured by the parsed flags, values of flags
+// that are not set are resolved from the configuration, see Resolve.
+// Returns an error if a flag value or the configuration is not valid.
+func (f *generatorFlags) Generator(log *slog.Logger) (*core.Generator, error) {
+	resolved, err := f.Resolve(log)
+	if err != nil {
+		return nil, err
+	}
+	generator := resolved.Generator
+	if generator.CsvPath == "" {
+		// Configuration files saved by the GUI may have no input.
+		generator.CsvPath = core.DefaultGenerator().CsvPath
+	}
+	generator.UpdatePdfPath()
+
+	// Check if opts are valid.
+	if err := generator.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+	return &generator, nil
+}
+
+// Returns the configuration resolved from built-in defaults, configuration
+// file of the user, project configuration file, profile, EANBAKER_*
+// environment variables and flags given on the command line, later
+// override earlier. Configuration is not validated. Unknown variables
+// of the environment are logged.
+func (f *generatorFlags) Resolve(log *slog.Logger) (*core.ResolvedConfig, error) {
+	layers, err := core.ConfigLayers(app.NAME, f.projectPath, f.profile, os.Environ(), log)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	resolved, err := core.ResolveConfig(append(layers, core.FuncLayer("flag", f.apply))...)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	return resolved, nil
+}
+
+// Sets values of the flags given on the command line to the generator.
+func (f *generatorFlags) apply(generator *core.Generator) error {
+	// Flags are set again over the generator values, so other values are kept.
+	replay := flag.NewFlagSet(f.flags.Name(), flag.ContinueOnError)
+	replay.SetOutput(io.Discard)
+	given := newGeneratorFlagsFrom(replay, *generator)
+	set := map[string]bool{}
+	var err error
+	f.flags.Visit(func(fl *flag.Flag) {
+		if replay.Lookup(fl.Name) != nil && err == nil {
+			set[fl.Name] = true
+			err = replay.Set(fl.Name, fl.Value.String())
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return given.parse(generator, set)
+}
+
+// Returns the file the serial state is saved to after generating, the
+// profile file or the project configuration file if no serial state file
+// is given, as the state was loaded from it.
+func (f *generatorFlags) serialStatePath() (string, error) {
+	if f.serialState != "" {
+		return f.serialState, nil
+	}
+	if f.profile == "" {
+		return f.projectPath, nil
+	}
+	store, err := profileStore()
+	if err != nil {
+		return "", err
+	}
+	return store.Path(f.profile), nil
+}
+
+// Sets the generator to the values of the flags, values that need parsing
+// are set only if their flag is set.
+func (f *generatorFlags) parse(target *core.Generator, set map[string]bool) error {
+	generator := f.generator
+
+	if set["csv-separator"] {
+		comma, err := core.CommaFromString(f.comma)
+		if err != nil {
+			return err
+		}
+		generator.CsvComma = comma
+	}
+
+	if set["csv-encoding"] {
+		encoding, err := core.TextEncodingFromString(f.encoding)
+		if err != nil {
+			return err
+		}
+		generator.CsvEncoding = encoding
+	}
+
+	if set["input-format"] {
+		inputFormat, err := core.InputFormatFromString(f.inputFormat)
+		if err != nil {
+			return err
+		}
+		generator.InputFormat = inputFormat
+	}
+
+	if set["overwrite"] {
+		overwrite, err := core.OverwritePolicyFromString(f.overwrite)
+		if err != nil {
+			return err
+		}
+		generator.OverwritePolicy = overwrite
+	}
+
+	if set["symbology"] {
+		symbology, err := core.SymbologyFromString(f.symbology)
+		if err != nil {
+			return err
+		}
+		generator.Symbology = symbology
+	}
+
+	if f.serialState != "" {
+		last, err := core.LoadSerialState(f.serialState)
+		if err != nil {
+			return err
+		}
+		generator.Serial.Last = last
+	}
+
+	gs1, err := core.ParseGS1Columns(f.gs1Columns)
+	if err != nil {
+		return err
+	}
+	if len(gs1) > 0 {
+		generator.GS1Columns = gs1
+	}
+
+	if set["error-policy"] {
+		policy, err := core.ErrorPolicyFromString(f.errorPolicy)
+		if err != nil {
+			return err
+		}
+		generator.ErrorPolicy = policy
+	}
+
+	if set["barcode-renderer"] {
+		barcodeRenderer, err := core.BarcodeRendererFromString(f.renderer)
+		if err != nil {
+			return err
+		}
+		generator.BarcodeRenderer = barcodeRenderer
+	}
+
+	if f.layoutPath != "" {
+		layout, err := core.LoadLayout(f.layoutPath)
+		if err != nil {
+			return err
+		}
+		generator.Layout = &layout
+	} else if f.labelSize != "" {
+		layout, err := core.LayoutFromSize(f.labelSize)
+		if err != nil {
+			return err
+		}
+		generator.Layout = &layout
+	}
+
+	if f.labelSheet != "" {
+		sheet, err := core.LabelSheetFromString(f.labelSheet)
+		if err != nil {
+			return err
+		}
+		generator.LabelSheet = &sheet
+	}
+
+	*target = generator
+	return nil
+}
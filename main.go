@@ -1,99 +1,206 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io/fs"
 	"os"
+	"strings"
 
-	"github.com/Fanteria/EANBaker/app"
 	"github.com/Fanteria/EANBaker/core"
-	"github.com/Fanteria/EANBaker/values"
 )
 
-func main() {
-	err := func() error {
-		logger, err := core.MultiLoggerFromEnv()
-		if err != nil {
-			return err
-		}
-		if len(os.Args) == 1 {
-			return app.RunGui(logger)
-		} else {
-			generator, err := GetOpts()
-			if err == nil {
-				//Open the CSV file
-				file, err := os.Open(generator.CsvPath)
-				if err != nil {
-					return err
-				}
-				defer file.Close()
-				return generator.Generate(generator.CsvPath, file, logger.Logger)
-			}
-			return nil
-		}
-	}()
-	if err != nil {
-		log.Fatal(err)
+// Exit codes of the command line.
+const (
+	ExitOK = 0
+	// Unexpected error.
+	ExitFailure = 1
+	// Unknown command, flag or wrong arguments.
+	ExitUsage = 2
+	// Invalid configuration or input data, e.g. a row that cannot be printed.
+	ExitInvalid = 3
+	// File cannot be read or written.
+	ExitIO = 4
+)
+
+// Error with the exit code of the program. Error that was already
+// reported (e.g. by the flag package) has no message.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit code %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// Marks the error as invalid configuration or input data. Errors
+// of reading or writing files are kept, so they exit as I/O errors.
+func invalid(err error) error {
+	if err == nil || isIOError(err) {
+		return err
+	}
+	return &exitError{code: ExitInvalid, err: err}
+}
+
+// Reports whether the error comes from reading or writing a file.
+func isIOError(err error) bool {
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	var syscallErr *os.SyscallError
+	return errors.As(err, &pathErr) || errors.As(err, &linkErr) || errors.As(err, &syscallErr)
+}
+
+// Returns the exit code of the error.
+func exitCode(err error) int {
+	var exitErr *exitError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &exitErr):
+		return exitErr.code
+	case isIOError(err):
+		return ExitIO
+	}
+	return ExitFailure
+}
+
+// Command of the command line, e.g. "eanbaker validate".
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, logger *core.MultiLogger) error
+}
+
+// Returns all commands in order they are listed in the help.
+func commands() []command {
+	return []command{
+		{name: "generate", summary: "Generate PDF with barcode labels (default command)", run: generateCommand},
+		{name: "batch", summary: "Generate PDFs from many inputs, a directory or a glob pattern", run: batchCommand},
+		{name: "validate", summary: "Validate rows of the input and print problems", run: validateCommand},
+		{name: "preview", summary: "Print texts and codes of the first labels without writing PDF", run: previewCommand},
+		{name: "allocate", summary: "Assign free EAN-13 codes of a GS1 company prefix to rows without code", run: allocateCommand},
+		{name: "config", summary: "Show, set or initialize the configuration file", run: configCommand},
+		{name: "profile", summary: "List, create, duplicate, delete, export or import named profiles", run: profileCommand},
+		{name: "version", summary: "Print version information", run: versionCommand},
+		{name: "serve", summary: "Start the HTTP API generating PDF labels from uploads or JSON records", run: serveCommand},
+		{name: "gui", summary: "Start the graphical interface (default without arguments)", run: guiCommand},
 	}
 }
 
 const USAGE string = `Usage:
-  eanbaker [flags]
+  eanbaker                      Start the GUI
+  eanbaker <command> [flags]
+  eanbaker [flags]              Same as "eanbaker generate [flags]"
 
 Description:
-  This application reads a CSV file, extracts text and EAN code columns by their headers, and generates a PDF file containing barcodes. Each barcode in the PDF is accompanied by the corresponding text.
+  This application reads a CSV, TSV, XLSX, XLS or ODS file, extracts text and EAN code columns by their headers, and generates a PDF file containing barcodes. Each barcode in the PDF is accompanied by the corresponding text.
+
+Commands:
+`
 
-  If no flags are provided, the application starts in GUI mode.
+const EXIT_CODES string = `
+Run "eanbaker <command> -h" for flags of the command.
 
-Flags:
+Configuration:
+  Values are resolved from these sources, later override earlier:
+    1. built-in defaults
+    2. configuration file of the user (e.g. ~/.config/EANBaker/config.json)
+    3. project configuration file .EANBaker.json in the current directory
+    4. profile given by -profile
+    5. environment variables EANBAKER_<KEY>, e.g. EANBAKER_TEXT_HEADER or EANBAKER_SERIAL_START
+    6. flags given on the command line
+  "eanbaker config show -resolved" prints every value with its source.
+
+Exit codes:
+  0  Success
+  1  Unexpected error
+  2  Unknown command, flag or wrong arguments
+  3  Invalid configuration or input data
+  4  File cannot be read or written
 `
 
-// Parses command-line flags and creates a configured Generator instance.
-// Defines and parses all CLI options including CSV path, PDF path, headers, and barcode repetition.
-// Validates the configuration before returning the generator.
-func GetOpts() (*core.Generator, error) {
-	// Define flags
-	generator := core.Generator{}
-	flag.StringVar(&generator.CsvPath, "csv", "data.csv", "Path to the input data in CSV.")
-	flag.StringVar(&generator.PdfPath, "pdf", "", "Path to the generated pdf file. If is not set, CSV file path with suffix changed to pdf is used.")
-	flag.StringVar(&generator.TextHeader, "text-header", "Material Number", "Case insensitive header of column that will be used as text.")
-	flag.StringVar(&generator.EanHeader, "ean-header", "ean", "Case insensitive header of column containing ean codes that will be used to generate barcode.")
-	flag.StringVar(&generator.TimesHeader, "times-header", "", `Name of the column that specifies how many times each EAN code should be generated. If the column is empty, each EAN code is generated only once.
-If the column contains a number, the EAN code is generated that many times. Rows are processed line by line, so identical EANs appear consecutively.`)
-	flag.UintVar(&generator.TimesEachEAN, "times-each-ean", 1, "Number of times each EAN code will be printed in the output PDF.")
-	comma_string := flag.String("csv-separator", ",", "CSV file column separator.")
-	print_version := flag.Bool("version", false, "Print version information and exit")
-
-	flag.Usage = func() {
-		fmt.Print(USAGE)
-		flag.PrintDefaults()
+// Prints the usage of the program with the list of commands.
+func usage() {
+	fmt.Fprint(os.Stderr, USAGE)
+	for _, c := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
 	}
+	fmt.Fprint(os.Stderr, EXIT_CODES)
+}
 
-	// Parse the flags
-	flag.Parse()
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-	if *print_version {
-		fmt.Println("Version:", values.Version)
-		fmt.Println("Commit:", values.Commit)
-		fmt.Println("Build date:", values.Date)
-		os.Exit(0)
+// Runs the command given by the arguments and returns the exit code.
+func run(args []string) int {
+	err := func() error {
+		logger, err := core.MultiLoggerFromEnv()
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			return guiCommand(nil, logger)
+		}
+		switch args[0] {
+		case "help", "-h", "-help", "--help":
+			if len(args) > 1 {
+				// Help of the command is printed by its flags.
+				return runCommand(args[1], []string{"-h"}, logger)
+			}
+			usage()
+			return nil
+		}
+		if strings.HasPrefix(args[0], "-") {
+			// Flags without command are the original form of generate.
+			return generateCommand(args, logger)
+		}
+		return runCommand(args[0], args[1:], logger)
+	}()
+	var exitErr *exitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.err == nil) {
+		fmt.Fprintln(os.Stderr, "Error:", err)
 	}
+	return exitCode(err)
+}
 
-	comma, err := core.CommaFromString(*comma_string)
-	if err != nil {
-		return nil, err
+// Runs the command of the name with its arguments.
+func runCommand(name string, args []string, logger *core.MultiLogger) error {
+	for _, c := range commands() {
+		if c.name == name {
+			return c.run(args, logger)
+		}
 	}
-	generator.CsvComma = comma
-
-	generator.UpdatePdfPath()
+	usage()
+	return &exitError{code: ExitUsage, err: fmt.Errorf("Unknown command %q", name)}
+}
 
-	// Check if opts are valid.
-	err = generator.Validate()
+// Parses the flags of the command. Help is not an error, other parse
+// errors are usage errors already reported by the flag set. Positional
+// arguments are allowed only when positional is set.
+func parseFlags(flags *flag.FlagSet, args []string, positional bool) error {
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return errHelp
+	}
 	if err != nil {
-		return nil, err
+		return &exitError{code: ExitUsage}
 	}
-
-	// Use the flag values
-	return &generator, nil
+	if !positional && flags.NArg() > 0 {
+		flags.Usage()
+		return &exitError{code: ExitUsage, err: fmt.Errorf("Unexpected argument %q", flags.Arg(0))}
+	}
+	return nil
 }
+
+// Returned by parseFlags when help was printed, program exits with success.
+var errHelp = &exitError{code: ExitOK}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Fanteria/EANBaker/app"
+	"github.com/Fanteria/EANBaker/core"
+)
+
+// Returns the profile store in the configuration directory of the user.
+func profileStore() (core.ProfileStore, error) {
+	return core.UserProfileStore(app.NAME)
+}
+
+const PROFILE_USAGE string = `Usage:
+  eanbaker profile list
+  eanbaker profile create [-from FILE] NAME
+  eanbaker profile duplicate NAME NEW_NAME
+  eanbaker profile delete NAME
+  eanbaker profile export NAME FILE
+  eanbaker profile import [-force] FILE [NAME]
+
+Description:
+  Manages named configuration profiles (headers, separator, symbology, layout, output path, ...) stored
+  in the configuration directory of the user, e.g. one per label printer or customer. Profile is used by
+  "eanbaker generate -profile NAME", flags given on the command line override its values. Profiles are
+  edited by "eanbaker config set -profile NAME KEY VALUE" or in the GUI.
+
+  list       Prints names of all profiles and the profile directory.
+  create     Creates the profile from the configuration file (e.g. .EANBaker.json), default configuration if -from is not set.
+  duplicate  Creates a copy of the profile.
+  delete     Deletes the profile.
+  export     Writes the profile to the file, "-" for the standard output.
+  import     Creates the profile from the file, "-" for the standard input. Name is the file name without extension if not set.
+
+Flags:
+`
+
+// Lists, creates, duplicates, deletes, exports or imports profiles.
+func profileCommand(args []string, logger *core.MultiLogger) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, PROFILE_USAGE)
+		return &exitError{code: ExitUsage, err: errors.New("Missing profile command: list, create, duplicate, delete, export or import")}
+	}
+	flags := newFlagSet("profile "+args[0], PROFILE_USAGE)
+	// Number of positional arguments of the command.
+	arguments := func(min int, max int) error {
+		if err := parseFlags(flags, args[1:], true); err != nil {
+			return err
+		}
+		if flags.NArg() < min || flags.NArg() > max {
+			flags.Usage()
+			return &exitError{code: ExitUsage, err: fmt.Errorf("Wrong number of arguments of profile %s", args[0])}
+		}
+		return nil
+	}
+	store, err := profileStore()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		if err := arguments(0, 0); err != nil {
+			return err
+		}
+		names, err := store.Names()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		fmt.Fprintf(os.Stderr, "%d profiles in %s\n", len(names), store.Dir)
+		return nil
+	case "create":
+		from := flags.String("from", "", "Generator JSON file the profile is created from.")
+		if err := arguments(1, 1); err != nil {
+			return err
+		}
+		if *from != "" {
+			file, err := os.Open(*from)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			return invalid(store.Import(flags.Arg(0), file, false))
+		}
+		generator := core.DefaultGenerator()
+		return invalid(store.Create(flags.Arg(0), &generator))
+	case "duplicate":
+		if err := arguments(2, 2); err != nil {
+			return err
+		}
+		return invalid(store.Duplicate(flags.Arg(0), flags.Arg(1)))
+	case "delete":
+		if err := arguments(1, 1); err != nil {
+			return err
+		}
+		return invalid(store.Delete(flags.Arg(0)))
+	case "export":
+		if err := arguments(2, 2); err != nil {
+			return err
+		}
+		if flags.Arg(1) == core.StdStream {
+			return invalid(store.Export(flags.Arg(0), os.Stdout))
+		}
+		file, err := os.Create(flags.Arg(1))
+		if err != nil {
+			return err
+		}
+		if err := store.Export(flags.Arg(0), file); err != nil {
+			file.Close()
+			os.Remove(flags.Arg(1))
+			return invalid(err)
+		}
+		return file.Close()
+	case "import":
+		force := flags.Bool("force", false, "Overwrite existing profile.")
+		if err := arguments(1, 2); err != nil {
+			return err
+		}
+		path := flags.Arg(0)
+		name := flags.Arg(1)
+		if name == "" {
+			if path == core.StdStream {
+				flags.Usage()
+				return &exitError{code: ExitUsage, err: errors.New("Profile imported from the standard input needs NAME")}
+			}
+			name = core.ProfileNameFromPath(path)
+		}
+		if path == core.StdStream {
+			return invalid(store.Import(name, os.Stdin, *force))
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return invalid(store.Import(name, file, *force))
+	case "-h", "-help", "--help":
+		flags.Usage()
+		return nil
+	}
+	fmt.Fprint(os.Stderr, PROFILE_USAGE)
+	return &exitError{code: ExitUsage, err: fmt.Errorf("Unknown profile command %q", args[0])}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	"github.com/Fanteria/EANBaker/core"
+	"github.com/Fanteria/EANBaker/server"
+)
+
+const SERVE_USAGE string = `Usage:
+  eanbaker serve [-addr HOST:PORT] [flags]
+
+Description:
+  Starts the HTTP API generating PDF labels, e.g. for ERP or warehouse systems. Labels are configured
+  as for "eanbaker generate" (configuration files, -profile, environment variables and flags), paths
+  of the input, PDF and reports are not used. Everything is generated in memory, no files are written.
+  Server stops on interrupt after running requests are finished.
+
+  POST /labels   Returns the PDF of the request body, which is one of:
+                   CSV, TSV, XLSX, XLS or ODS input, the format is given by ?format=, Content-Type
+                   (e.g. text/csv) or detected from the content,
+                   multipart/form-data with the input in the "file" field,
+                   application/json array of records, e.g. [{"text": "Milk", "ean": "5901234123457", "times": 2}]
+                   with optional "symbology", "add_on", "price" and "fields" of template placeholders.
+                 Errors are JSON objects {"error": "..."} with status 400 for malformed requests, 413 for
+                 bodies over -max-body, 415 for unsupported content type, 422 for invalid data
+                 and 503 when -max-concurrent requests are already running.
+  GET /health    Returns {"status": "ok", ...} with the version and number of running requests.
+
+  Serial numbers continue across requests while the server runs, they are not saved.
+
+Flags:
+`
+
+// Serves the HTTP API generating labels until interrupted.
+func serveCommand(args []string, logger *core.MultiLogger) error {
+	flags := newFlagSet("serve", SERVE_USAGE)
+	generatorFlags := newGeneratorFlags(flags)
+	addr := flags.String("addr", "localhost:8080", "Address the server listens on, e.g. \":8080\" for all interfaces.")
+	max_body := flags.Int64("max-body", server.DefaultMaxBodySize>>20, "Maximal size of a request body in MiB.")
+	max_concurrent := flags.Int("max-concurrent", runtime.NumCPU(), "Maximal number of requests generating labels at once.")
+	if err := parseFlags(flags, args, false); err != nil {
+		return err
+	}
+	if *max_body <= 0 || *max_concurrent <= 0 {
+		flags.Usage()
+		return &exitError{code: ExitUsage, err: errors.New("Flags -max-body and -max-concurrent must be positive")}
+	}
+	resolved, err := generatorFlags.Resolve(logger.Logger)
+	if err != nil {
+		return err
+	}
+	// Logs of the long running server are not kept in memory.
+	api, err := server.New(server.Options{
+		Generator:     resolved.Generator,
+		MaxBodySize:   *max_body << 20,
+		MaxConcurrent: *max_concurrent,
+	}, logger.TerminalLogger())
+	if err != nil {
+		return invalid(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return api.ListenAndServe(ctx, *addr)
+}
@@ -0,0 +1,445 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Fanteria/EANBaker/core"
+	"github.com/Fanteria/EANBaker/values"
+)
+
+// Default maximal size of a request body, 10 MiB.
+const DefaultMaxBodySize = 10 << 20
+
+// Name of the multipart form field with the uploaded input.
+const FileField = "file"
+
+// Input formats by the media type of the request body or the uploaded file.
+// Formats of other text and binary types are detected from the content.
+var mediaFormats = map[string]core.InputFormat{
+	"text/csv":                  core.FormatCsv,
+	"text/tab-separated-values": core.FormatTsv,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": core.FormatXlsx,
+	"application/vnd.ms-excel":                       core.FormatXls,
+	"application/vnd.oasis.opendocument.spreadsheet": core.FormatOds,
+	"application/octet-stream":                       "",
+	"text/plain":                                     "",
+}
+
+// Options of the HTTP API.
+type Options struct {
+	// Configuration of labels. Paths of the input, PDF and reports are not used,
+	// barcodes are always drawn as vectors, so no files are written.
+	Generator core.Generator
+	// Maximal size of a request body in bytes, DefaultMaxBodySize if zero.
+	MaxBodySize int64
+	// Maximal number of requests generating labels at once, others are
+	// refused with 503 Service Unavailable. One if zero.
+	MaxConcurrent int
+}
+
+// HTTP API generating PDF labels in memory.
+type Server struct {
+	options Options
+	log     *slog.Logger
+	mux     *http.ServeMux
+	// Slots of requests generating labels.
+	limit chan struct{}
+	// Serial numbers continue across requests, so labels with them are generated one by one.
+	serial sync.Mutex
+	// Serial numbers of the last request, guarded by serial.
+	serialNumbers core.SerialNumbers
+	requests      atomic.Uint64
+}
+
+// Label of the JSON request body, an array of them is generated.
+type jsonRecord struct {
+	Text string `json:"text"`
+	Ean  string `json:"ean"`
+	// Number of copies, one if not set.
+	Times *int `json:"times"`
+	// Symbology of the code, the configured one if not set.
+	Symbology string   `json:"symbology"`
+	AddOn     string   `json:"add_on"`
+	Price     *float64 `json:"price"`
+	// Values of template placeholders by the column name.
+	Fields map[string]string `json:"fields"`
+}
+
+// Error with the HTTP status of the response.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+// Key of the request logger in the request context.
+type loggerKey struct{}
+
+// Creates the API with the options, requests are logged to the logger.
+// Returns an error if the configuration of labels is not valid.
+func New(options Options, log *slog.Logger) (*Server, error) {
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = DefaultMaxBodySize
+	}
+	options.MaxConcurrent = max(options.MaxConcurrent, 1)
+	s := &Server{
+		options: options,
+		log:     log,
+		mux:     http.NewServeMux(),
+		limit:   make(chan struct{}, options.MaxConcurrent),
+		// Options are only read after the server is created.
+		serialNumbers: options.Generator.Serial,
+	}
+	generator := s.generator()
+	if err := generator.Validate(); err != nil {
+		return nil, err
+	}
+	s.mux.HandleFunc("GET /health", s.health)
+	s.mux.HandleFunc("POST /labels", s.labels)
+	return s, nil
+}
+
+// Serves the request and logs its method, path, status, size and duration.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := s.requests.Add(1)
+	log := s.log.With("request", id)
+	writer := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	start := time.Now()
+	s.mux.ServeHTTP(writer, r.WithContext(context.WithValue(r.Context(), loggerKey{}, log)))
+	level := slog.LevelInfo
+	if writer.status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	} else if writer.status >= http.StatusBadRequest {
+		level = slog.LevelWarn
+	}
+	log.LogAttrs(r.Context(), level, "HTTP request",
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", writer.status),
+		slog.Int64("request_bytes", r.ContentLength),
+		slog.Int("response_bytes", writer.bytes),
+		slog.Duration("duration", time.Since(start)),
+		slog.String("remote", r.RemoteAddr),
+	)
+}
+
+// Serves the API on the address until the context is done, then waits
+// for running requests to finish.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Minute,
+		IdleTimeout:       2 * time.Minute,
+		ErrorLog:          slog.NewLogLogger(s.log.Handler(), slog.LevelWarn),
+	}
+	s.log.Info("HTTP server listening", "addr", listener.Addr().String(),
+		"max_body_size", s.options.MaxBodySize, "max_concurrent", s.options.MaxConcurrent)
+
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		s.log.Info("HTTP server shutting down")
+		shutdown, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		done <- server.Shutdown(shutdown)
+	}()
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-done
+}
+
+// Responds with the status of the server.
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":         "ok",
+		"version":        values.Version,
+		"active":         len(s.limit),
+		"max_concurrent": cap(s.limit),
+	})
+}
+
+// Responds with the PDF of labels of the uploaded input or the JSON records.
+func (s *Server) labels(w http.ResponseWriter, r *http.Request) {
+	select {
+	case s.limit <- struct{}{}:
+		defer func() { <-s.limit }()
+	default:
+		w.Header().Set("Retry-After", "1")
+		writeError(w, &statusError{http.StatusServiceUnavailable, errors.New("Server is busy, try again later")})
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, s.options.MaxBodySize)
+	name, pdf, err := s.generate(r)
+	if err != nil {
+		logger(r).Warn("Failed to generate labels", "err", err)
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Length", fmt.Sprint(len(pdf)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(pdf)
+}
+
+// Returns the name and content of the PDF generated from the request body.
+// Body is read by its media type: JSON records, multipart form with
+// the input in FileField, or the input itself.
+func (s *Server) generate(r *http.Request) (string, []byte, error) {
+	body, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return "", nil, &statusError{http.StatusRequestEntityTooLarge, fmt.Errorf("Request body is larger than %d bytes", tooLarge.Limit)}
+	}
+	if err != nil {
+		return "", nil, &statusError{http.StatusBadRequest, errors.Join(errors.New("Cannot read request body"), err)}
+	}
+	mediaType, params, err := parseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return "", nil, err
+	}
+
+	generator := s.generator()
+	var pdf bytes.Buffer
+	log := logger(r)
+	switch mediaType {
+	case "application/json":
+		records, err := decodeRecords(body)
+		if err != nil {
+			return "", nil, err
+		}
+		err = s.withSerial(&generator, func() error {
+			return generator.GenerateRecordsToWriter(records, &pdf, log)
+		})
+		return "labels.pdf", pdf.Bytes(), unprocessable(err)
+	case "multipart/form-data":
+		filename, content, partType, err := formFile(body, params["boundary"])
+		if err != nil {
+			return "", nil, err
+		}
+		body, mediaType = content, partType
+		if filename != "" {
+			generator.CsvPath = filename
+		}
+	}
+
+	if err := inputFormat(&generator, r.URL.Query().Get("format"), mediaType); err != nil {
+		return "", nil, err
+	}
+	name := generator.CsvPath
+	generator.CsvPath = core.StdStream
+	err = s.withSerial(&generator, func() error {
+		return generator.GenerateToWriter(name, bytes.NewReader(body), &pdf, log)
+	})
+	if name == core.StdStream {
+		return "labels.pdf", pdf.Bytes(), unprocessable(err)
+	}
+	return strings.TrimSuffix(filepath.Base(name), filepath.Ext(name)) + ".pdf", pdf.Bytes(), unprocessable(err)
+}
+
+// Returns a copy of the configured generator writing nothing to files.
+func (s *Server) generator() core.Generator {
+	generator := s.options.Generator
+	generator.CsvPath, generator.PdfPath = core.StdStream, core.StdStream
+	generator.ReportPath, generator.SkippedReportPath = "", ""
+	// PNG renderer writes barcodes to temporary files.
+	generator.BarcodeRenderer = core.RendererVector
+	return generator
+}
+
+// Runs the generation. Serial numbers of the generator continue from the last
+// request and are kept for the next one, they are not saved to files.
+func (s *Server) withSerial(generator *core.Generator, generate func() error) error {
+	if !generator.Serial.Enabled {
+		return generate()
+	}
+	s.serial.Lock()
+	defer s.serial.Unlock()
+	generator.Serial = s.serialNumbers
+	if err := generate(); err != nil {
+		return err
+	}
+	s.serialNumbers = generator.Serial
+	return nil
+}
+
+// Sets the input format of the generator given by the format query parameter,
+// or the media type. Format is detected when neither of them gives it.
+func inputFormat(generator *core.Generator, query string, mediaType string) error {
+	if query != "" {
+		format, err := core.InputFormatFromString(query)
+		if err != nil {
+			return &statusError{http.StatusBadRequest, err}
+		}
+		generator.InputFormat = format
+		return nil
+	}
+	format, ok := mediaFormats[mediaType]
+	if !ok && !strings.HasPrefix(mediaType, "text/") {
+		return &statusError{http.StatusUnsupportedMediaType, fmt.Errorf(
+			"Unsupported content type %q, expected CSV, TSV, XLSX, XLS or ODS input, JSON records or multipart form", mediaType)}
+	}
+	if format != "" {
+		generator.InputFormat = format
+	}
+	return nil
+}
+
+// Returns the media type and its parameters, application/octet-stream if it is not set.
+func parseMediaType(contentType string) (string, map[string]string, error) {
+	if contentType == "" {
+		return "application/octet-stream", nil, nil
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", nil, &statusError{http.StatusBadRequest, fmt.Errorf("Invalid content type %q: %v", contentType, err)}
+	}
+	return mediaType, params, nil
+}
+
+// Returns the name, content and media type of the FileField of the multipart form.
+func formFile(body []byte, boundary string) (string, []byte, string, error) {
+	if boundary == "" {
+		return "", nil, "", &statusError{http.StatusBadRequest, errors.New("Multipart form has no boundary")}
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return "", nil, "", &statusError{http.StatusBadRequest, fmt.Errorf("Multipart form has no %q field", FileField)}
+		}
+		if err != nil {
+			return "", nil, "", &statusError{http.StatusBadRequest, errors.Join(errors.New("Invalid multipart form"), err)}
+		}
+		if part.FormName() != FileField {
+			continue
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return "", nil, "", &statusError{http.StatusBadRequest, errors.Join(errors.New("Invalid multipart form"), err)}
+		}
+		mediaType, _, err := parseMediaType(part.Header.Get("Content-Type"))
+		if err != nil {
+			return "", nil, "", err
+		}
+		return part.FileName(), content, mediaType, nil
+	}
+}
+
+// Decodes the JSON array of records, rows of the records are their one based positions.
+func decodeRecords(body []byte) ([]core.Record, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	var labels []jsonRecord
+	if err := decoder.Decode(&labels); err != nil {
+		return nil, &statusError{http.StatusBadRequest, fmt.Errorf("Invalid JSON records: %v", err)}
+	}
+	records := make([]core.Record, 0, len(labels))
+	for i, label := range labels {
+		times := 1
+		if label.Times != nil {
+			times = *label.Times
+		}
+		if times < 0 {
+			return nil, &statusError{http.StatusBadRequest, fmt.Errorf("Record %d: times cannot be negative, got %d", i+1, times)}
+		}
+		var symbology core.Symbology
+		if label.Symbology != "" {
+			var err error
+			if symbology, err = core.SymbologyFromString(label.Symbology); err != nil {
+				return nil, &statusError{http.StatusBadRequest, fmt.Errorf("Record %d: %w", i+1, err)}
+			}
+		}
+		records = append(records, core.Record{
+			Text:      label.Text,
+			Ean:       label.Ean,
+			Times:     times,
+			Symbology: symbology,
+			AddOn:     label.AddOn,
+			Price:     label.Price,
+			Fields:    label.Fields,
+			Row:       i + 1,
+		})
+	}
+	return records, nil
+}
+
+// Marks the error of generation as invalid input or configuration.
+func unprocessable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &statusError{http.StatusUnprocessableEntity, err}
+}
+
+// Returns the logger of the request.
+func logger(r *http.Request) *slog.Logger {
+	if log, ok := r.Context().Value(loggerKey{}).(*slog.Logger); ok {
+		return log
+	}
+	return slog.Default()
+}
+
+// Writes the error as JSON object with the status of the error,
+// 500 Internal Server Error if it has none.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		status = statusErr.status
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// Writes the value as JSON with the status.
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// Response writer remembering the status and the size of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += n
+	return n, err
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Fanteria/EANBaker/core"
+)
+
+func newTestServer(t *testing.T, options Options) *Server {
+	t.Helper()
+	if options.Generator.EanHeader == "" {
+		options.Generator = core.Generator{TextHeader: "Text", EanHeader: "EAN", TimesEachEAN: 1}
+	}
+	server, err := New(options, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	return server
+}
+
+func TestNew_InvalidGenerator(t *testing.T) {
+	generator := core.Generator{TextHeader: "Text", EanHeader: "EAN", Symbology: "foo"}
+	if _, err := New(Options{Generator: generator}, slog.New(slog.NewTextHandler(io.Discard, nil))); err == nil {
+		t.Error("New() should fail for invalid configuration")
+	}
+}
+
+// Returns the multipart form with the file and its content type.
+func multipartForm(t *testing.T, field string, filename string, content string) (string, string) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("comment", "ignored"); err != nil {
+		t.Fatal(err)
+	}
+	part, err := writer.CreateFormFile(field, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body.String(), writer.FormDataContentType()
+}
+
+func TestServer_Labels(t *testing.T) {
+	csv := "Text,EAN\nA,5901234123457\n"
+	form, formType := multipartForm(t, FileField, "prices.csv", csv)
+	noFile, noFileType := multipartForm(t, "upload", "prices.csv", csv)
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantName    string
+	}{
+		{name: "csv", target: "/labels", contentType: "text/csv", body: csv, wantStatus: http.StatusOK, wantName: "labels.pdf"},
+		{name: "detected format", target: "/labels", body: csv, wantStatus: http.StatusOK, wantName: "labels.pdf"},
+		{name: "format query", target: "/labels?format=tsv", contentType: "application/octet-stream", body: "Text\tEAN\nA\t5901234123457\n", wantStatus: http.StatusOK},
+		{name: "multipart", target: "/labels", contentType: formType, body: form, wantStatus: http.StatusOK, wantName: "prices.pdf"},
+		{name: "json records", target: "/labels", contentType: "application/json", body: `[{"text": "A", "ean": "5901234123457", "times": 2}, {"ean": "HELLO", "symbology": "code128"}]`, wantStatus: http.StatusOK, wantName: "labels.pdf"},
+		{name: "multipart without file", target: "/labels", contentType: noFileType, body: noFile, wantStatus: http.StatusBadRequest},
+		{name: "invalid json", target: "/labels", contentType: "application/json", body: `[{"text": "A", "code": "1"}]`, wantStatus: http.StatusBadRequest},
+		{name: "invalid symbology", target: "/labels", contentType: "application/json", body: `[{"ean": "1", "symbology": "foo"}]`, wantStatus: http.StatusBadRequest},
+		{name: "negative times", target: "/labels", contentType: "application/json", body: `[{"ean": "5901234123457", "times": -1}]`, wantStatus: http.StatusBadRequest},
+		{name: "no records", target: "/labels", contentType: "application/json", body: `[]`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "unknown format", target: "/labels?format=pdf", contentType: "text/csv", body: csv, wantStatus: http.StatusBadRequest},
+		{name: "unsupported content type", target: "/labels", contentType: "image/png", body: csv, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "missing column", target: "/labels", contentType: "text/csv", body: "Name,EAN\nA,5901234123457\n", wantStatus: http.StatusUnprocessableEntity},
+		{name: "too large", target: "/labels", contentType: "text/csv", body: csv + strings.Repeat("B,5901234123457\n", 100), wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	server := newTestServer(t, Options{MaxBodySize: 1024})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				request.Header.Set("Content-Type", tt.contentType)
+			}
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+			if response.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", response.Code, tt.wantStatus, response.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				var body map[string]string
+				if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil || body["error"] == "" {
+					t.Errorf("body = %s, want JSON error", response.Body)
+				}
+				return
+			}
+			if !bytes.HasPrefix(response.Body.Bytes(), []byte("%PDF")) || response.Header().Get("Content-Type") != "application/pdf" {
+				t.Errorf("response is not PDF, content type %q", response.Header().Get("Content-Type"))
+			}
+			if tt.wantName != "" && !strings.Contains(response.Header().Get("Content-Disposition"), tt.wantName) {
+				t.Errorf("Content-Disposition = %q, want %q", response.Header().Get("Content-Disposition"), tt.wantName)
+			}
+		})
+	}
+}
+
+func TestServer_Busy(t *testing.T) {
+	server := newTestServer(t, Options{MaxConcurrent: 1})
+	server.limit <- struct{}{}
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/labels", strings.NewReader("Text,EAN\nA,5901234123457\n")))
+	if response.Code != http.StatusServiceUnavailable || response.Header().Get("Retry-After") == "" {
+		t.Errorf("status = %d, want %d with Retry-After", response.Code, http.StatusServiceUnavailable)
+	}
+
+	response = httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/health", nil))
+	var health map[string]any
+	if err := json.Unmarshal(response.Body.Bytes(), &health); err != nil {
+		t.Fatalf("health is not JSON: %v", err)
+	}
+	if response.Code != http.StatusOK || health["status"] != "ok" || health["active"] != 1.0 || health["max_concurrent"] != 1.0 {
+		t.Errorf("health = %d %v, want ok with one active request", response.Code, health)
+	}
+}
+
+func TestServer_MethodNotAllowed(t *testing.T) {
+	server := newTestServer(t, Options{})
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/labels", nil))
+	if response.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", response.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestServer_Serial(t *testing.T) {
+	generator := core.Generator{TextHeader: "Text", EanHeader: "EAN", TimesEachEAN: 1}
+	generator.Serial = core.SerialNumbers{Enabled: true, Start: 1, Step: 1}
+	server := newTestServer(t, Options{Generator: generator})
+	for _, want := range []int64{2, 4} {
+		response := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/labels", strings.NewReader(`[{"ean": "5901234123457", "times": 2}]`))
+		request.Header.Set("Content-Type", "application/json")
+		server.ServeHTTP(response, request)
+		if response.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", response.Code, response.Body)
+		}
+		if last := server.serialNumbers.Last; last == nil || *last != want {
+			t.Errorf("Serial.Last = %v, want %d", last, want)
+		}
+	}
+}
+
+func TestServer_SerialConcurrent(t *testing.T) {
+	generator := core.Generator{TextHeader: "Text", EanHeader: "EAN", TimesEachEAN: 1}
+	generator.Serial = core.SerialNumbers{Enabled: true, Start: 1, Step: 1}
+	server := newTestServer(t, Options{Generator: generator, MaxConcurrent: 8})
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/labels", strings.NewReader(`[{"ean": "5901234123457"}]`))
+			request.Header.Set("Content-Type", "application/json")
+			server.ServeHTTP(response, request)
+			if response.Code != http.StatusOK {
+				t.Errorf("status = %d, body %s", response.Code, response.Body)
+			}
+		}()
+	}
+	wg.Wait()
+	if last := server.serialNumbers.Last; last == nil || *last != 8 {
+		t.Errorf("Serial.Last = %v, want 8", last)
+	}
+}
+
+func TestServer_ListenAndServe(t *testing.T) {
+	server := newTestServer(t, Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.ListenAndServe(ctx, "127.0.0.1:0") }()
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ListenAndServe() = %v, want nil after shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ListenAndServe() did not stop after the context was done")
+	}
+	if err := server.ListenAndServe(context.Background(), "invalid address"); err == nil {
+		t.Error("ListenAndServe() should fail for invalid address")
+	}
+}